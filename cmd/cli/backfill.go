@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/schedulers"
+	"github.com/nzai/qr/stores"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+type backfill struct{}
+
+func (s backfill) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "backfill",
+		Usage: "crawl trading days missing from store, holidays of exchange calendar are skipped",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "store",
+				Aliases:  []string{"s"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify store, eg fs:///data",
+			},
+			exchangesFlag(),
+		}, dateRangeFlags()...),
+		Action: func(c *cli.Context) error {
+			store, err := stores.Parse(c.String("store"))
+			if err != nil {
+				zap.L().Error("parse store argument failed", zap.Error(err))
+				return err
+			}
+			defer store.Close()
+
+			_exchanges, err := exchanges.Parse(c.String("exchanges"))
+			if err != nil {
+				zap.L().Error("parse exchange argument failed", zap.Error(err), zap.String("exchanges", c.String("exchanges")))
+				return err
+			}
+
+			scheduler := schedulers.NewScheduler(store, _exchanges...)
+			for _, exchange := range _exchanges {
+				start, end, err := dateRange(c, exchange.Location())
+				if err != nil {
+					return err
+				}
+
+				err = scheduler.Backfill(c.Context, exchange, start, end)
+				if err != nil {
+					zap.L().Error("backfill exchange failed", zap.Error(err), zap.String("exchange", exchange.Code()))
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
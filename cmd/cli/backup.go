@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/stores"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+type backup struct{}
+
+func (s backup) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "backup",
+		Usage: "write stored days in date range into a self-describing archive",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "store",
+				Aliases:  []string{"s"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify store, eg fs:///data",
+			},
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify archive file path, eg /backup/quotes.tar",
+			},
+			&cli.StringFlag{
+				Name:  "compression",
+				Usage: "specify day compression, none, gzip or zstd",
+			},
+			&cli.StringFlag{
+				Name:  "codec",
+				Usage: "specify day serial codec, row or columnar",
+			},
+			exchangesFlag(),
+		}, dateRangeFlags()...),
+		Action: func(c *cli.Context) error {
+			format, err := stores.ParseFormat(c.String("compression"), c.String("codec"))
+			if err != nil {
+				zap.L().Error("parse format argument failed", zap.Error(err))
+				return err
+			}
+
+			_exchanges, err := exchanges.Parse(c.String("exchanges"))
+			if err != nil {
+				zap.L().Error("parse exchange argument failed", zap.Error(err), zap.String("exchanges", c.String("exchanges")))
+				return err
+			}
+
+			store, err := stores.Parse(c.String("store"))
+			if err != nil {
+				zap.L().Error("parse store argument failed", zap.Error(err))
+				return err
+			}
+			defer store.Close()
+
+			file, err := os.Create(c.String("output"))
+			if err != nil {
+				zap.L().Error("create archive file failed", zap.Error(err), zap.String("output", c.String("output")))
+				return err
+			}
+			defer file.Close()
+
+			bw := bufio.NewWriter(file)
+			aw, err := stores.NewArchiveWriter(bw, format)
+			if err != nil {
+				return err
+			}
+
+			for _, exchange := range _exchanges {
+				start, end, err := dateRange(c, exchange.Location())
+				if err != nil {
+					return err
+				}
+
+				days, err := stores.Backup(c.Context, store, aw, exchange, start, end)
+				if err != nil {
+					zap.L().Error("backup exchange failed", zap.Error(err), zap.String("exchange", exchange.Code()), zap.Int("days", days))
+					return err
+				}
+
+				zap.L().Info("backup exchange success", zap.String("exchange", exchange.Code()), zap.Int("days", days))
+			}
+
+			err = aw.Close()
+			if err != nil {
+				return err
+			}
+
+			err = bw.Flush()
+			if err != nil {
+				zap.L().Error("flush archive file failed", zap.Error(err))
+				return err
+			}
+
+			return file.Sync()
+		},
+	}
+}
+
+type restore struct{}
+
+func (s restore) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "verify archive and save every archived day into store",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "input",
+				Aliases:  []string{"i"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify archive file path, eg /backup/quotes.tar",
+			},
+			&cli.StringFlag{
+				Name:     "store",
+				Aliases:  []string{"s"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify store, eg leveldb:///data/leveldb",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			file, err := os.Open(c.String("input"))
+			if err != nil {
+				zap.L().Error("open archive file failed", zap.Error(err), zap.String("input", c.String("input")))
+				return err
+			}
+			defer file.Close()
+
+			store, err := stores.Parse(c.String("store"))
+			if err != nil {
+				zap.L().Error("parse store argument failed", zap.Error(err))
+				return err
+			}
+			defer store.Close()
+
+			// the archive is fully verified before any day is saved
+			header, days, err := stores.Restore(c.Context, file, store)
+			if err != nil {
+				zap.L().Error("restore archive failed", zap.Error(err), zap.Int("days", days))
+				return err
+			}
+
+			zap.L().Info("restore archive success",
+				zap.String("software", header.Software),
+				zap.Time("created", header.Created),
+				zap.Int("days", days))
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"github.com/nzai/qr/stores"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+// diffReport define store diff report
+type diffReport struct {
+	Days        int        `json:"days"`
+	Same        int        `json:"same"`
+	Different   int        `json:"different"`
+	Differences []*dayDiff `json:"differences"`
+}
+
+// dayDiff define difference of one day, missing is left or right if the day is only stored in the other store
+type dayDiff struct {
+	Exchange string `json:"exchange"`
+	Date     string `json:"date"`
+	Missing  string `json:"missing,omitempty"`
+	Error    string `json:"error,omitempty"`
+	*quotes.DailyDiff
+}
+
+type diff struct{}
+
+func (s diff) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "diff",
+		Usage: "compare stored days of two stores, print json report of missing days, missing companies and changed bars",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "left",
+				Aliases:  []string{"l"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify left store, eg fs:///data",
+			},
+			&cli.StringFlag{
+				Name:     "right",
+				Aliases:  []string{"r"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify right store, eg s3://bucket?region=us-east-1",
+			},
+			exchangesFlag(),
+		}, dateRangeFlags()...),
+		Action: func(c *cli.Context) error {
+			left, err := stores.Parse(c.String("left"))
+			if err != nil {
+				zap.L().Error("parse left store argument failed", zap.Error(err))
+				return err
+			}
+			defer left.Close()
+
+			right, err := stores.Parse(c.String("right"))
+			if err != nil {
+				zap.L().Error("parse right store argument failed", zap.Error(err))
+				return err
+			}
+			defer right.Close()
+
+			_exchanges, err := exchanges.Parse(c.String("exchanges"))
+			if err != nil {
+				zap.L().Error("parse exchange argument failed", zap.Error(err), zap.String("exchanges", c.String("exchanges")))
+				return err
+			}
+
+			report := &diffReport{Differences: []*dayDiff{}}
+			for _, exchange := range _exchanges {
+				start, end, err := dateRange(c, exchange.Location())
+				if err != nil {
+					return err
+				}
+
+				dates, err := s.unionDates(left, right, exchange, start, end)
+				if err != nil {
+					return err
+				}
+
+				for _, date := range dates {
+					err = c.Context.Err()
+					if err != nil {
+						return err
+					}
+
+					report.Days++
+					dd := s.diffDay(left, right, exchange, date)
+					if dd == nil {
+						report.Same++
+						continue
+					}
+
+					report.Different++
+					report.Differences = append(report.Differences, dd)
+				}
+
+				zap.L().Info("diff exchange finished",
+					zap.String("exchange", exchange.Code()),
+					zap.Int("days", len(dates)))
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(report)
+			if err != nil {
+				return err
+			}
+
+			if report.Different > 0 {
+				return fmt.Errorf("%d of %d days are different", report.Different, report.Days)
+			}
+
+			return nil
+		},
+	}
+}
+
+// unionDates list dates stored in either store, ordered by date
+func (s diff) unionDates(left, right stores.Store, exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	distinct := make(map[int64]time.Time)
+	for _, store := range []stores.Store{left, right} {
+		dates, err := stores.Dates(store, exchange, start, end)
+		if err != nil {
+			zap.L().Error("list stored dates failed", zap.Error(err), zap.String("exchange", exchange.Code()))
+			return nil, err
+		}
+
+		for _, date := range dates {
+			distinct[date.Unix()] = date
+		}
+	}
+
+	dates := make([]time.Time, 0, len(distinct))
+	for _, date := range distinct {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	return dates, nil
+}
+
+// diffDay compare exchange daily quote of both stores, return nil if same
+func (s diff) diffDay(left, right stores.Store, exchange exchanges.Exchange, date time.Time) *dayDiff {
+	dd := &dayDiff{Exchange: exchange.Code(), Date: date.Format(constants.DatePattern)}
+
+	var edqs [2]*quotes.ExchangeDailyQuote
+	for index, store := range []stores.Store{left, right} {
+		side := [2]string{"left", "right"}[index]
+
+		exists, err := store.Exists(exchange, date)
+		if err != nil {
+			dd.Error = fmt.Sprintf("%s: %v", side, err)
+			return dd
+		}
+
+		if !exists {
+			dd.Missing = side
+			return dd
+		}
+
+		edqs[index], err = store.Load(exchange, date)
+		if err != nil {
+			dd.Error = fmt.Sprintf("%s: %v", side, err)
+			return dd
+		}
+	}
+
+	// diff checks Equal first, the structured diff is only built for days not equal
+	dd.DailyDiff = quotes.Diff(*edqs[0], *edqs[1])
+	if dd.DailyDiff.IsEmpty() {
+		return nil
+	}
+
+	return dd
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nzai/qr/utils"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagDatePattern = "2006-01-02"
+)
+
+// exchangesFlag specify exchanges, all exchanges by default
+func exchangesFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:     "exchanges",
+		Aliases:  []string{"e"},
+		Required: false,
+		Usage:    "specify exchanges",
+		Value:    "Nasdaq,Amex,Nyse,Sse,Szse,Hkex",
+	}
+}
+
+// dateRangeFlags specify start and end date
+func dateRangeFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "start",
+			Required: false,
+			Usage:    "specify start date",
+			Value:    "2015-05-01",
+		},
+		&cli.StringFlag{
+			Name:     "end",
+			Required: false,
+			Usage:    "specify end date(default today)",
+		},
+	}
+}
+
+// dateRange parse start and end date flags in location
+func dateRange(c *cli.Context, location *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(flagDatePattern, c.String("start"), location)
+	if err != nil {
+		return start, start, fmt.Errorf("start date invalid: %s", c.String("start"))
+	}
+
+	end := utils.TodayZero(time.Now().In(location))
+	if c.String("end") != "" {
+		end, err = time.ParseInLocation(flagDatePattern, c.String("end"), location)
+		if err != nil {
+			return start, end, fmt.Errorf("end date invalid: %s", c.String("end"))
+		}
+	}
+
+	return start, end, nil
+}
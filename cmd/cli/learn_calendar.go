@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/stores"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+type learnCalendar struct{}
+
+func (s learnCalendar) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "learn-calendar",
+		Usage: "print stored days without any quote outside exchange calendar data as holidays, in calendar toml format",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "store",
+				Aliases:  []string{"s"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify store, eg fs:///data",
+			},
+			exchangesFlag(),
+		}, dateRangeFlags()...),
+		Action: func(c *cli.Context) error {
+			store, err := stores.Parse(c.String("store"))
+			if err != nil {
+				zap.L().Error("parse store argument failed", zap.Error(err))
+				return err
+			}
+			defer store.Close()
+
+			_exchanges, err := exchanges.Parse(c.String("exchanges"))
+			if err != nil {
+				zap.L().Error("parse exchange argument failed", zap.Error(err), zap.String("exchanges", c.String("exchanges")))
+				return err
+			}
+
+			for _, exchange := range _exchanges {
+				start, end, err := dateRange(c, exchange.Location())
+				if err != nil {
+					return err
+				}
+
+				learned, err := stores.LearnCalendar(store, exchange, exchanges.GetCalendar(exchange), start, end)
+				if err != nil {
+					zap.L().Error("learn exchange calendar from store failed",
+						zap.Error(err),
+						zap.String("exchange", exchange.Code()),
+						zap.Time("start", start),
+						zap.Time("end", end))
+					return err
+				}
+
+				fmt.Printf("# %s\n", exchange.Code())
+				for _, date := range learned {
+					fmt.Printf("\"%s\" = \"no quotes stored\"\n", date.Format(flagDatePattern))
+				}
+			}
+
+			return nil
+		},
+	}
+}
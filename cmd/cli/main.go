@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/urfave/cli/v2"
@@ -25,11 +27,25 @@ func main() {
 		Commands: []*cli.Command{
 			showVersion{}.Command(),
 			new(rollup).Command(),
+			repair{}.Command(),
+			verify{}.Command(),
+			diff{}.Command(),
+			tier{}.Command(),
+			retention{}.Command(),
+			backup{}.Command(),
+			restore{}.Command(),
+			backfill{}.Command(),
+			learnCalendar{}.Command(),
 		},
 	}
 
-	// timeout duration: 30s
-	ctx, _ := context.WithTimeout(context.Background(), time.Minute*30)
+	// timeout duration: 30m, stop gracefully on interrupt
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*30)
+	defer cancel()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	err := app.RunContext(ctx, os.Args)
 	if err != nil {
 		fmt.Println("\033[31m" + err.Error() + "\033[0m ")
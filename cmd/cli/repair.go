@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/stores"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+// manifestRepairer define store can rebuild month manifests
+type manifestRepairer interface {
+	RepairManifests(exchanges.Exchange, time.Time, time.Time) error
+}
+
+type repair struct{}
+
+func (s repair) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "repair",
+		Usage: "rebuild object store month manifests from stored objects",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "store",
+				Aliases:  []string{"s"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify object store, eg s3://bucket?region=us-east-1",
+			},
+			exchangesFlag(),
+		}, dateRangeFlags()...),
+		Action: func(c *cli.Context) error {
+			store, err := stores.Parse(c.String("store"))
+			if err != nil {
+				zap.L().Error("parse store argument failed", zap.Error(err))
+				return err
+			}
+			defer store.Close()
+
+			repairer, ok := store.(manifestRepairer)
+			if !ok {
+				return fmt.Errorf("store %T has no manifest", store)
+			}
+
+			_exchanges, err := exchanges.Parse(c.String("exchanges"))
+			if err != nil {
+				zap.L().Error("parse exchange argument failed", zap.Error(err), zap.String("exchanges", c.String("exchanges")))
+				return err
+			}
+
+			for _, exchange := range _exchanges {
+				start, end, err := dateRange(c, exchange.Location())
+				if err != nil {
+					return err
+				}
+
+				err = c.Context.Err()
+				if err != nil {
+					return err
+				}
+
+				err = repairer.RepairManifests(exchange, start, end)
+				if err != nil {
+					zap.L().Error("repair manifests failed", zap.Error(err), zap.String("exchange", exchange.Code()))
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/stores"
+	"github.com/nzai/qr/utils"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+type retention struct{}
+
+func (s retention) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "retention",
+		Usage: "downsample stored days older than age from minute bars to interval bars",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "store",
+				Aliases:  []string{"s"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify store, eg fs:///data",
+			},
+			&cli.StringFlag{
+				Name:  "start",
+				Usage: "specify start date of stored days to check",
+				Value: "2015-05-01",
+			},
+			&cli.IntFlag{
+				Name:  "age",
+				Usage: "specify days kept at minute resolution",
+				Value: 365 * 3,
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "specify downsampled bar interval, eg 5m, 15m",
+				Value: time.Minute * 5,
+			},
+			exchangesFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			store, err := stores.Parse(c.String("store"))
+			if err != nil {
+				zap.L().Error("parse store argument failed", zap.Error(err))
+				return err
+			}
+			defer store.Close()
+
+			_exchanges, err := exchanges.Parse(c.String("exchanges"))
+			if err != nil {
+				zap.L().Error("parse exchange argument failed", zap.Error(err), zap.String("exchanges", c.String("exchanges")))
+				return err
+			}
+
+			interval := c.Duration("interval")
+			for _, exchange := range _exchanges {
+				start, err := time.ParseInLocation(flagDatePattern, c.String("start"), exchange.Location())
+				if err != nil {
+					return fmt.Errorf("start date invalid: %s", c.String("start"))
+				}
+
+				cutoff := utils.TodayZero(time.Now().In(exchange.Location())).AddDate(0, 0, -c.Int("age"))
+				dates, err := stores.Dates(store, exchange, start, cutoff.AddDate(0, 0, -1))
+				if err != nil {
+					zap.L().Error("list stored dates failed", zap.Error(err), zap.String("exchange", exchange.Code()))
+					return err
+				}
+
+				var downsampled int
+				for _, date := range dates {
+					err = c.Context.Err()
+					if err != nil {
+						return err
+					}
+
+					ok, err := stores.Downsample(store, exchange, date, interval)
+					if err != nil {
+						zap.L().Error("downsample exchange daily quote failed",
+							zap.Error(err),
+							zap.String("exchange", exchange.Code()),
+							zap.Time("date", date))
+						return err
+					}
+
+					if ok {
+						downsampled++
+					}
+				}
+
+				zap.L().Info("downsample exchange success",
+					zap.String("exchange", exchange.Code()),
+					zap.Time("cutoff", cutoff),
+					zap.Duration("interval", interval),
+					zap.Int("days", len(dates)),
+					zap.Int("downsampled", downsampled))
+			}
+
+			return nil
+		},
+	}
+}
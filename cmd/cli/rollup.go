@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -27,10 +29,10 @@ type companyQuote struct {
 }
 
 type rollup struct {
-	sourceStore         stores.Store
+	sourceStore         stores.ContextStore
 	db                  *sql.DB
 	exchanges           []exchanges.Exchange
-	in                  chan *quotes.ExchangeDailyQuote
+	in                  chan *stores.QuoteStream
 	out                 chan *companyQuote
 	retryTimes          int
 	writePool           int
@@ -103,7 +105,7 @@ func (s *rollup) Command() *cli.Command {
 					zap.String("source", source))
 				return err
 			}
-			s.sourceStore = sourceStore
+			s.sourceStore = stores.WithContext(sourceStore)
 
 			err = s.initTDEngine(dest)
 			if err != nil {
@@ -124,10 +126,10 @@ func (s *rollup) Command() *cli.Command {
 				return err
 			}
 
-			s.in = make(chan *quotes.ExchangeDailyQuote, 8)
+			s.in = make(chan *stores.QuoteStream, 1)
 			s.out = make(chan *companyQuote, s.writePool*4)
 
-			go s.readLoop()
+			go s.readLoop(c.Context)
 			go s.writeLoop()
 
 			return s.process()
@@ -198,7 +200,9 @@ func (s rollup) ensureTables() error {
 	return nil
 }
 
-func (s rollup) readLoop() {
+func (s rollup) readLoop(ctx context.Context) {
+	defer close(s.in)
+
 	for _, exchange := range s.exchanges {
 		date := utils.TodayZero(time.Now().In(exchange.Location()))
 		startDate := time.Date(2015, 5, 1, 0, 0, 0, 0, exchange.Location())
@@ -212,10 +216,15 @@ func (s rollup) readLoop() {
 			zap.Int("totalDays", totalDays))
 
 		for startDate.Before(date) {
+			if ctx.Err() != nil {
+				zap.L().Info("read source cancelled", zap.Error(ctx.Err()), zap.String("exchange", exchange.Code()))
+				return
+			}
+
 			start := time.Now()
 			for index := 1; index <= s.retryTimes; index++ {
-				err := s.tryReadSourceOnce(exchange, date)
-				if err == nil {
+				err := s.tryReadSourceOnce(ctx, exchange, date)
+				if err == nil || ctx.Err() != nil {
 					break
 				}
 
@@ -226,7 +235,7 @@ func (s rollup) readLoop() {
 						zap.Time("date", date),
 						zap.Duration("duration", time.Since(start)))
 				} else {
-					time.Sleep(time.Second * 3)
+					utils.Sleep(ctx, time.Second*3)
 				}
 			}
 
@@ -236,11 +245,10 @@ func (s rollup) readLoop() {
 		zap.L().Info("read exchange finished", zap.String("exchange", exchange.Code()))
 	}
 
-	close(s.in)
 	zap.L().Info("read source finished")
 }
 
-func (s rollup) tryReadSourceOnce(exchange exchanges.Exchange, date time.Time) error {
+func (s rollup) tryReadSourceOnce(ctx context.Context, exchange exchanges.Exchange, date time.Time) error {
 	exists, err := s.destExists(exchange, date)
 	if err != nil {
 		return err
@@ -250,7 +258,7 @@ func (s rollup) tryReadSourceOnce(exchange exchanges.Exchange, date time.Time) e
 		return nil
 	}
 
-	exists, err = s.sourceStore.Exists(exchange, date)
+	exists, err = s.sourceStore.ExistsContext(ctx, exchange, date)
 	if err != nil {
 		return err
 	}
@@ -266,7 +274,8 @@ func (s rollup) tryReadSourceOnce(exchange exchanges.Exchange, date time.Time) e
 		zap.String("exchange", exchange.Code()),
 		zap.Time("date", date))
 
-	edq, err := s.sourceStore.Load(exchange, date)
+	// stream company quotes one by one, memory stays flat regardless of exchange size
+	stream, err := stores.StreamContext(ctx, s.sourceStore, exchange, date)
 	if err != nil {
 		return err
 	}
@@ -275,7 +284,12 @@ func (s rollup) tryReadSourceOnce(exchange exchanges.Exchange, date time.Time) e
 		zap.String("exchange", exchange.Code()),
 		zap.Time("date", date))
 
-	s.in <- edq
+	select {
+	case <-ctx.Done():
+		stream.Close()
+		return ctx.Err()
+	case s.in <- stream:
+	}
 
 	return nil
 }
@@ -310,34 +324,22 @@ func (s rollup) destExists(exchange exchanges.Exchange, date time.Time) (bool, e
 func (s rollup) process() error {
 	for {
 		edq, ok := <-s.in
-		for companyCode, cdq := range edq.Quotes {
-			s.out <- &companyQuote{
-				Exchange:    edq.Exchange,
-				CompanyCode: companyCode,
-				Date:        edq.Date,
-				SerialType:  quotes.SerialTypePre,
-				Quote:       *cdq.Pre.Rollup(),
-			}
-
-			s.out <- &companyQuote{
-				Exchange:    edq.Exchange,
-				CompanyCode: companyCode,
-				Date:        edq.Date,
-				SerialType:  quotes.SerialTypeRegular,
-				Quote:       *cdq.Regular.Rollup(),
-			}
+		if !ok {
+			break
+		}
 
-			s.out <- &companyQuote{
-				Exchange:    edq.Exchange,
-				CompanyCode: companyCode,
-				Date:        edq.Date,
-				SerialType:  quotes.SerialTypePost,
-				Quote:       *cdq.Post.Rollup(),
-			}
+		err := s.processStream(edq)
+		edq.Close()
+		if err != nil {
+			zap.L().Error("read source stream failed",
+				zap.Error(err),
+				zap.String("exchange", edq.Exchange),
+				zap.Time("date", edq.Date))
+			return err
 		}
 
 		command := fmt.Sprintf("insert into %s values(%d, %d)", s.exchangeDoneTableName(edq.Exchange), edq.Date.Unix()*1000, 1)
-		err := s.tryExecuteCommand(command)
+		err = s.tryExecuteCommand(command)
 		if err != nil {
 			return err
 		}
@@ -345,18 +347,53 @@ func (s rollup) process() error {
 		zap.L().Info("exchange proccess success",
 			zap.String("exchange", edq.Exchange),
 			zap.Time("date", edq.Date))
-
-		if ok {
-			continue
-		}
-
-		break
 	}
 
 	close(s.out)
 	return nil
 }
 
+// processStream rollup company quotes of stream one by one
+func (s rollup) processStream(edq *stores.QuoteStream) error {
+	exchange, found := exchanges.Get(edq.Exchange)
+	if !found {
+		return fmt.Errorf("exchange %s not found", edq.Exchange)
+	}
+
+	resampler := quotes.Resampler{
+		Interval: quotes.Interval{Count: 1, Unit: quotes.IntervalDay},
+		Location: exchange.Location(),
+	}
+
+	for {
+		cdq, err := edq.NextCompanyQuote()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		rq := resampler.Resample([]*quotes.CompanyDailyQuote{cdq})
+		for serialType, bars := range map[quotes.SerialType]quotes.Serial{
+			quotes.SerialTypePre:     rq.Pre,
+			quotes.SerialTypeRegular: rq.Regular,
+			quotes.SerialTypePost:    rq.Post,
+		} {
+			for _, bar := range bars {
+				s.out <- &companyQuote{
+					Exchange:    edq.Exchange,
+					CompanyCode: cdq.Company.Code,
+					Date:        edq.Date,
+					SerialType:  serialType,
+					Quote:       bar,
+				}
+			}
+		}
+	}
+}
+
 func (s rollup) writeLoop() {
 	for {
 		cq, ok := <-s.out
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 
+	"github.com/nzai/qr/constants"
 	"github.com/urfave/cli/v2"
 )
 
@@ -14,7 +15,7 @@ func (s showVersion) Command() *cli.Command {
 		Usage:   "show version",
 		Aliases: []string{"v"},
 		Action: func(c *cli.Context) error {
-			fmt.Println("v0.1.0")
+			fmt.Println(constants.Version)
 			return nil
 		},
 	}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/stores"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+type tier struct{}
+
+func (s tier) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "tier",
+		Usage: "migrate days older than age from hot store to cold store",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "hot",
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify hot store, eg leveldb:///data/leveldb",
+			},
+			&cli.StringFlag{
+				Name:     "cold",
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify cold store, eg s3://bucket?region=us-east-1",
+			},
+			&cli.IntFlag{
+				Name:  "age",
+				Usage: "specify days kept in hot store",
+				Value: 90,
+			},
+			exchangesFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			hot, err := stores.Parse(c.String("hot"))
+			if err != nil {
+				zap.L().Error("parse hot store argument failed", zap.Error(err))
+				return err
+			}
+
+			cold, err := stores.Parse(c.String("cold"))
+			if err != nil {
+				hot.Close()
+				zap.L().Error("parse cold store argument failed", zap.Error(err))
+				return err
+			}
+
+			tiered := stores.NewTiered(hot, cold, c.Int("age"))
+			defer tiered.Close()
+
+			_exchanges, err := exchanges.Parse(c.String("exchanges"))
+			if err != nil {
+				zap.L().Error("parse exchange argument failed", zap.Error(err), zap.String("exchanges", c.String("exchanges")))
+				return err
+			}
+
+			for _, exchange := range _exchanges {
+				migration, err := tiered.Migrate(c.Context, exchange)
+				if err != nil {
+					zap.L().Error("migrate exchange to cold store failed",
+						zap.Error(err),
+						zap.String("exchange", exchange.Code()),
+						zap.Int("moved", len(migration.Moved)))
+					return err
+				}
+
+				zap.L().Info("migrate exchange to cold store success",
+					zap.String("exchange", exchange.Code()),
+					zap.Time("cutoff", migration.Cutoff),
+					zap.Int("moved", len(migration.Moved)))
+			}
+
+			return nil
+		},
+	}
+}
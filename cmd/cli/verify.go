@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"github.com/nzai/qr/stores"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+// verifyReport define store verification report
+type verifyReport struct {
+	Days     int             `json:"days"`
+	Passed   int             `json:"passed"`
+	Failed   int             `json:"failed"`
+	Failures []*verifyResult `json:"failures"`
+}
+
+// verifyResult define verification result of one day
+type verifyResult struct {
+	Exchange   string             `json:"exchange"`
+	Date       string             `json:"date"`
+	Error      string             `json:"error,omitempty"`
+	Violations []quotes.Violation `json:"violations,omitempty"`
+}
+
+type verify struct{}
+
+func (s verify) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "verify",
+		Usage: "decode every stored day, check checksums and structural invariants, print json report",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "store",
+				Aliases:  []string{"s"},
+				Required: true,
+				Usage:    "\033[1;33mRequired!\033[0m specify store, eg fs:///data",
+			},
+			exchangesFlag(),
+		}, dateRangeFlags()...),
+		Action: func(c *cli.Context) error {
+			store, err := stores.Parse(c.String("store"))
+			if err != nil {
+				zap.L().Error("parse store argument failed", zap.Error(err))
+				return err
+			}
+			defer store.Close()
+
+			_exchanges, err := exchanges.Parse(c.String("exchanges"))
+			if err != nil {
+				zap.L().Error("parse exchange argument failed", zap.Error(err), zap.String("exchanges", c.String("exchanges")))
+				return err
+			}
+
+			report := &verifyReport{Failures: []*verifyResult{}}
+			for _, exchange := range _exchanges {
+				start, end, err := dateRange(c, exchange.Location())
+				if err != nil {
+					return err
+				}
+
+				dates, err := stores.Dates(store, exchange, start, end)
+				if err != nil {
+					zap.L().Error("list stored dates failed", zap.Error(err), zap.String("exchange", exchange.Code()))
+					return err
+				}
+
+				for _, date := range dates {
+					err = c.Context.Err()
+					if err != nil {
+						return err
+					}
+
+					report.Days++
+					result := s.verifyDay(store, exchange, date)
+					if result == nil {
+						report.Passed++
+						continue
+					}
+
+					report.Failed++
+					report.Failures = append(report.Failures, result)
+				}
+
+				zap.L().Info("verify exchange finished",
+					zap.String("exchange", exchange.Code()),
+					zap.Int("days", len(dates)))
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(report)
+			if err != nil {
+				return err
+			}
+
+			if report.Failed > 0 {
+				return fmt.Errorf("%d of %d days failed verification", report.Failed, report.Days)
+			}
+
+			return nil
+		},
+	}
+}
+
+// verifyDay decode exchange daily quote and check it, return nil if passed
+// checksums are verified while decoding if the format has them
+func (s verify) verifyDay(store stores.Store, exchange exchanges.Exchange, date time.Time) *verifyResult {
+	result := &verifyResult{Exchange: exchange.Code(), Date: date.Format(constants.DatePattern)}
+
+	stream, err := stores.Stream(store, exchange, date)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer stream.Close()
+
+	edq, err := stream.ReadAll()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if edq.Exchange != exchange.Code() || !edq.Date.Equal(date) {
+		result.Error = fmt.Sprintf("stored quote is %s %s", edq.Exchange, edq.Date.Format(constants.DatePattern))
+		return result
+	}
+
+	result.Violations = edq.Check(exchanges.GetCalendar(exchange).SessionWindow(date))
+	if len(result.Violations) > 0 {
+		return result
+	}
+
+	return nil
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"flag"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/nzai/qr/exchanges"
@@ -13,9 +16,10 @@ import (
 )
 
 var (
-	sourceStoreArgument = flag.String("src", "fs|/data", "source store type: eg fs")
-	destStoreArgument   = flag.String("dest", "fs|/data", "dest store type: eg fs")
+	sourceStoreArgument = flag.String("src", "fs:///data", "source store data source name: eg fs:///data")
+	destStoreArgument   = flag.String("dest", "fs:///data", "dest store data source name: eg fs:///data")
 	exchangeArgument    = flag.String("e", "Nyse", "exchange: eg Nyse,Nasdaq")
+	startArgument       = flag.String("start", "2015-05-01", "sync days from start date: eg 2015-05-01")
 )
 
 func main() {
@@ -50,61 +54,47 @@ func main() {
 			zap.String("arg", *exchangeArgument))
 	}
 
+	start, err := time.Parse("2006-01-02", *startArgument)
+	if err != nil {
+		zap.L().Fatal("parse start argument failed",
+			zap.Error(err),
+			zap.String("arg", *startArgument))
+	}
+
 	zap.L().Info("sync start")
 	defer zap.L().Info("sync end")
 
 	ch := make(chan *EDQ, 8)
-	ctx, cancelFunc := context.WithCancel(context.Background())
+	// stop sync gracefully on interrupt
+	ctx, cancelFunc := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancelFunc()
 
 	go func() {
 		defer close(ch)
-		loader(ctx, sourceStore, destStore, _exchanges, ch)
+		loader(ctx, stores.WithContext(sourceStore), destStore, _exchanges, start, ch)
 	}()
 
-	saver(destStore, ch)
+	saver(ctx, stores.WithContext(destStore), ch)
+	// stop loader if saver failed
+	cancelFunc()
 }
 
-func loader(ctx context.Context, source, dest stores.Store, _exchanges []exchanges.Exchange, ch chan *EDQ) {
+func loader(ctx context.Context, source stores.ContextStore, dest stores.Store, _exchanges []exchanges.Exchange, start time.Time, ch chan *EDQ) {
 	for _, exchange := range _exchanges {
-		startTime := time.Now()
+		startDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, exchange.Location())
+		endDate := utils.YesterdayZero(time.Now().In(exchange.Location()))
 
-		date := time.Date(2015, 5, 1, 0, 0, 0, 0, exchange.Location())
-		endDate := utils.TodayZero(startTime.In(exchange.Location()))
+		dates, err := stores.MissingDatesContext(ctx, source, dest, exchange, startDate, endDate)
+		if err != nil {
+			zap.L().Error("list missing dates failed",
+				zap.Error(err),
+				zap.Any("exchange", exchange.Code()))
+			return
+		}
 
-		var exists bool
 		var edq *quotes.ExchangeDailyQuote
-		var err error
-		for date.Before(endDate) {
-			exists, err = dest.Exists(exchange, date)
-			if err != nil {
-				zap.L().Error("check dest exists failed",
-					zap.Error(err),
-					zap.Any("exchange", exchange.Code()),
-					zap.Any("date", date.Format("2006-01-02")))
-				return
-			}
-
-			if exists {
-				date = date.AddDate(0, 0, 1)
-				continue
-			}
-
-			exists, err = source.Exists(exchange, date)
-			if err != nil {
-				zap.L().Error("check source exists failed",
-					zap.Error(err),
-					zap.Any("exchange", exchange.Code()),
-					zap.Any("date", date.Format("2006-01-02")))
-				return
-			}
-
-			if !exists {
-				date = date.AddDate(0, 0, 1)
-				continue
-			}
-
-			edq, err = source.Load(exchange, date)
+		for _, date := range dates {
+			edq, err = source.LoadContext(ctx, exchange, date)
 			if err != nil {
 				zap.L().Error("load edq failed",
 					zap.Error(err),
@@ -117,29 +107,25 @@ func loader(ctx context.Context, source, dest stores.Store, _exchanges []exchang
 				zap.Any("exchange", exchange.Code()),
 				zap.Any("date", date.Format("2006-01-02")))
 
-			ch <- &EDQ{
-				Exchange: exchange,
-				Date:     date,
-				Quote:    edq,
-			}
-
 			select {
 			case <-ctx.Done():
 				return
-			default:
+			case ch <- &EDQ{
+				Exchange: exchange,
+				Date:     date,
+				Quote:    edq,
+			}:
 			}
-
-			date = date.AddDate(0, 0, 1)
 		}
 	}
 
 	zap.L().Info("load complete")
 }
 
-func saver(dest stores.Store, ch chan *EDQ) {
+func saver(ctx context.Context, dest stores.ContextStore, ch chan *EDQ) {
 	var err error
 	for edq := range ch {
-		err = dest.Save(edq.Exchange, edq.Date, edq.Quote)
+		err = dest.SaveContext(ctx, edq.Exchange, edq.Date, edq.Quote)
 		if err != nil {
 			zap.L().Error("save edq failed",
 				zap.Error(err),
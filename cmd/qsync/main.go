@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/nzai/qr/exchanges"
 	"github.com/nzai/qr/stores"
@@ -9,9 +14,10 @@ import (
 )
 
 var (
-	sourceStoreArgument = flag.String("src", "fs:/data", "source store type: eg fs")
-	destStoreArgument   = flag.String("dest", "fs:/data", "dest store type: eg fs")
+	sourceStoreArgument = flag.String("src", "fs:///data", "source store data source name: eg fs:///data")
+	destStoreArgument   = flag.String("dest", "fs:///data", "dest store data source name: eg fs:///data")
 	exchangeArgument    = flag.String("e", "Nyse", "exchange: eg Nyse,Nasdaq")
+	startArgument       = flag.String("start", "2015-05-01", "sync days from start date: eg 2015-05-01")
 )
 
 func main() {
@@ -46,7 +52,18 @@ func main() {
 			zap.String("arg", *exchangeArgument))
 	}
 
-	sync := NewSync(sourceStore, destStore, _exchanges)
-	wg := sync.Run()
+	start, err := time.Parse("2006-01-02", *startArgument)
+	if err != nil {
+		zap.L().Fatal("parse start argument failed",
+			zap.Error(err),
+			zap.String("arg", *startArgument))
+	}
+
+	// stop sync gracefully on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sync := NewSync(sourceStore, destStore, _exchanges, start)
+	wg := sync.Run(ctx)
 	wg.Wait()
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -14,18 +15,19 @@ import (
 
 // Sync sync exchange daily quote
 type Sync struct {
-	source    stores.Store
-	dest      stores.Store
+	source    stores.ContextStore
+	dest      stores.ContextStore
 	exchanges []exchanges.Exchange
+	start     time.Time
 }
 
-// NewSync create new exchange daily quote sync
-func NewSync(source stores.Store, dest stores.Store, exchanges []exchanges.Exchange) *Sync {
-	return &Sync{source: source, dest: dest, exchanges: exchanges}
+// NewSync create new exchange daily quote sync, days from start date are synced
+func NewSync(source stores.Store, dest stores.Store, exchanges []exchanges.Exchange, start time.Time) *Sync {
+	return &Sync{source: stores.WithContext(source), dest: stores.WithContext(dest), exchanges: exchanges, start: start}
 }
 
-// Run sync jobs
-func (s Sync) Run() *sync.WaitGroup {
+// Run sync jobs, jobs stop when context done
+func (s Sync) Run(ctx context.Context) *sync.WaitGroup {
 	wg := new(sync.WaitGroup)
 	wg.Add(len(s.exchanges))
 
@@ -34,7 +36,7 @@ func (s Sync) Run() *sync.WaitGroup {
 			zap.L().Info("sync exchange daily quote start",
 				zap.String("exchange", exchange.Code()))
 
-			err := s.syncExchange(exchange)
+			err := s.syncExchange(ctx, exchange)
 			if err != nil {
 				zap.L().Error("sync exchange daily quote failed",
 					zap.Error(err),
@@ -52,58 +54,58 @@ func (s Sync) Run() *sync.WaitGroup {
 }
 
 // syncExchange sync exchange quote
-func (s Sync) syncExchange(exchange exchanges.Exchange) error {
+func (s Sync) syncExchange(ctx context.Context, exchange exchanges.Exchange) error {
 	startTime := time.Now()
 
-	date := time.Date(2015, 5, 1, 0, 0, 0, 0, exchange.Location())
-	endDate := utils.TodayZero(startTime.In(exchange.Location()))
+	startDate := time.Date(s.start.Year(), s.start.Month(), s.start.Day(), 0, 0, 0, 0, exchange.Location())
+	endDate := utils.YesterdayZero(startTime.In(exchange.Location()))
 
-	total := int(endDate.Sub(date).Hours() / 24)
-	var processed int
+	dates, err := stores.MissingDatesContext(ctx, s.source, s.dest, exchange, startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	total := len(dates)
+	for index, date := range dates {
+		err = ctx.Err()
+		if err != nil {
+			return err
+		}
 
-	for date.Before(endDate) {
-		zap.L().Info(fmt.Sprintf("(%.2f%%) start", float64(processed)*100/float64(total)),
+		zap.L().Info(fmt.Sprintf("(%.2f%%) start", float64(index)*100/float64(total)),
 			zap.String("exchange", exchange.Code()),
 			zap.Time("date", date),
 			zap.String("d", date.Weekday().String()),
-			zap.String("process", fmt.Sprintf("%d/%d", processed, total)))
+			zap.String("process", fmt.Sprintf("%d/%d", index, total)))
 
 		start := time.Now()
 
-		exists, err := s.syncExchangeDate(exchange, date)
+		err = s.syncExchangeDate(ctx, exchange, date)
 		if err != nil {
 			return err
 		}
 
-		if exists {
-			total--
-		} else {
-			processed++
-
-			passed := time.Now().Sub(startTime)
-			speed := float64(processed) / passed.Seconds()
-			remain := time.Duration(float64(total-processed)/speed) * time.Second
-
-			zap.L().Info(fmt.Sprintf("(%.2f%%) synced", float64(processed)*100/float64(total)),
-				zap.String("exchange", exchange.Code()),
-				zap.Time("date", date),
-				zap.Duration("d", time.Now().Sub(start)),
-				zap.String("process", fmt.Sprintf("%d/%d", processed, total)),
-				zap.Duration("remain", remain))
-		}
+		processed := index + 1
+		passed := time.Now().Sub(startTime)
+		speed := float64(processed) / passed.Seconds()
+		remain := time.Duration(float64(total-processed)/speed) * time.Second
 
-		date = date.AddDate(0, 0, 1)
+		zap.L().Info(fmt.Sprintf("(%.2f%%) synced", float64(processed)*100/float64(total)),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date),
+			zap.Duration("d", time.Now().Sub(start)),
+			zap.String("process", fmt.Sprintf("%d/%d", processed, total)),
+			zap.Duration("remain", remain))
 	}
 
 	return nil
 }
 
 // syncExchangeDate sync exchange daily quote
-func (s Sync) syncExchangeDate(exchange exchanges.Exchange, date time.Time) (bool, error) {
-	exists := false
+func (s Sync) syncExchangeDate(ctx context.Context, exchange exchanges.Exchange, date time.Time) error {
 	var err error
 	for index := 0; index < constants.RetryCount; index++ {
-		exists, err = s.syncExchangeDateOnce(exchange, date)
+		err = s.syncExchangeDateOnce(ctx, exchange, date)
 		if err == nil {
 			if index > 0 {
 				zap.L().Info("sync exchange daily quote success",
@@ -111,7 +113,7 @@ func (s Sync) syncExchangeDate(exchange exchanges.Exchange, date time.Time) (boo
 					zap.Time("date", date),
 					zap.String("retries", fmt.Sprintf("%d/%d", index+1, constants.RetryCount)))
 			}
-			return exists, nil
+			return nil
 		}
 
 		if index < constants.RetryCount-1 {
@@ -121,49 +123,26 @@ func (s Sync) syncExchangeDate(exchange exchanges.Exchange, date time.Time) (boo
 				zap.Time("date", date),
 				zap.Duration("retry in", constants.RetryInterval),
 				zap.String("retries", fmt.Sprintf("%d/%d", index+1, constants.RetryCount)))
-			time.Sleep(constants.RetryInterval)
+			err1 := utils.Sleep(ctx, constants.RetryInterval)
+			if err1 != nil {
+				return err1
+			}
 		}
 	}
 
-	return false, err
+	return err
 }
 
 // syncExchangeDateOnce sync exchange daily quote once
-func (s Sync) syncExchangeDateOnce(exchange exchanges.Exchange, date time.Time) (bool, error) {
-	exists, err := s.dest.Exists(exchange, date)
-	if err != nil {
-		zap.L().Error("check dest exchange daily quote exists failed",
-			zap.Error(err),
-			zap.String("exchange", exchange.Code()),
-			zap.Time("date", date))
-		return true, err
-	}
-
-	if exists {
-		return true, nil
-	}
-
-	exists, err = s.source.Exists(exchange, date)
-	if err != nil {
-		zap.L().Error("check source exchange daily quote exists failed",
-			zap.Error(err),
-			zap.String("exchange", exchange.Code()),
-			zap.Time("date", date))
-		return true, err
-	}
-
-	if !exists {
-		return false, nil
-	}
-
+func (s Sync) syncExchangeDateOnce(ctx context.Context, exchange exchanges.Exchange, date time.Time) error {
 	start := time.Now()
-	edq, err := s.source.Load(exchange, date)
+	edq, err := s.source.LoadContext(ctx, exchange, date)
 	if err != nil {
 		zap.L().Error("load exchange daily quote failed",
 			zap.Error(err),
 			zap.String("exchange", exchange.Code()),
 			zap.Time("date", date))
-		return false, err
+		return err
 	}
 	zap.L().Info("load source success",
 		zap.Duration("d", time.Now().Sub(start)),
@@ -171,13 +150,13 @@ func (s Sync) syncExchangeDateOnce(exchange exchanges.Exchange, date time.Time)
 		zap.Time("date", date))
 
 	start = time.Now()
-	err = s.dest.Save(exchange, date, edq)
+	err = s.dest.SaveContext(ctx, exchange, date, edq)
 	if err != nil {
 		zap.L().Error("save exchange daily quote failed",
 			zap.Error(err),
 			zap.String("exchange", exchange.Code()),
 			zap.Time("date", date))
-		return false, err
+		return err
 	}
 	zap.L().Info("save dest success",
 		zap.Duration("d", time.Now().Sub(start)),
@@ -186,13 +165,13 @@ func (s Sync) syncExchangeDateOnce(exchange exchanges.Exchange, date time.Time)
 
 	start = time.Now()
 	// validate
-	saved, err := s.dest.Load(exchange, date)
+	saved, err := s.dest.LoadContext(ctx, exchange, date)
 	if err != nil {
 		zap.L().Error("load saved exchange daily quote failed",
 			zap.Error(err),
 			zap.String("exchange", exchange.Code()),
 			zap.Time("date", date))
-		return false, err
+		return err
 	}
 	zap.L().Info("load dest success",
 		zap.Duration("d", time.Now().Sub(start)),
@@ -206,17 +185,17 @@ func (s Sync) syncExchangeDateOnce(exchange exchanges.Exchange, date time.Time)
 			zap.String("exchange", exchange.Code()),
 			zap.Time("date", date))
 
-		err1 := s.dest.Delete(exchange, date)
+		err1 := s.dest.DeleteContext(ctx, exchange, date)
 		if err1 != nil {
 			zap.L().Error("delete dest exchange daily quote failed",
 				zap.Error(err1),
 				zap.String("exchange", exchange.Code()),
 				zap.Time("date", date))
-			return false, err1
+			return err1
 		}
 
-		return false, err
+		return err
 	}
 
-	return false, nil
+	return nil
 }
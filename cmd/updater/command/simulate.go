@@ -11,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nzai/qr/cmd/updater/trade_system"
+	"github.com/nzai/qr/exchanges"
 	"github.com/nzai/qr/quotes"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
@@ -38,6 +39,18 @@ func (s Simulate) Command() *cli.Command {
 				Value:    "aapl_5m",
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:     "exchange",
+				Aliases:  []string{"e"},
+				Value:    "Nasdaq",
+				Required: false,
+				Usage:    "specify exchange whose calendar drops quotes out of trading sessions",
+			},
+			&cli.BoolFlag{
+				Name:     "extended",
+				Required: false,
+				Usage:    "keep quotes of pre and post market sessions",
+			},
 			&cli.Float64Flag{
 				Name:     "amount",
 				Aliases:  []string{"a"},
@@ -50,6 +63,11 @@ func (s Simulate) Command() *cli.Command {
 			tableName := c.String("tablename")
 			amount := c.Float64("amount")
 
+			exchange, found := exchanges.Get(c.String("exchange"))
+			if !found {
+				return fmt.Errorf("invalid exchange: %s", c.String("exchange"))
+			}
+
 			db, err := s.openDB(tdeAddress)
 			if err != nil {
 				return err
@@ -60,6 +78,7 @@ func (s Simulate) Command() *cli.Command {
 			if err != nil {
 				return err
 			}
+			qs = s.tradingQuotes(exchanges.GetCalendar(exchange), qs, c.Bool("extended"))
 			s.qs = qs
 
 			if len(qs) == 0 {
@@ -178,6 +197,39 @@ func (s Simulate) loadData(ctx context.Context, db *sql.DB, tableName string) ([
 	return qs, nil
 }
 
+// tradingQuotes drop quotes out of trading sessions of exchange calendar
+// daily bars are stamped at the start of the day, so they are only dropped if not on trading day
+func (s Simulate) tradingQuotes(calendar *exchanges.Calendar, qs []*quotes.Quote, extended bool) []*quotes.Quote {
+	daily := s.isDaily(qs)
+	result := make([]*quotes.Quote, 0, len(qs))
+	for _, quote := range qs {
+		t := time.Unix(int64(quote.Timestamp), 0)
+		if daily && calendar.IsTradingDay(t) || !daily && calendar.IsOpen(t, extended) {
+			result = append(result, quote)
+		}
+	}
+
+	if len(result) < len(qs) {
+		zap.L().Info("drop quotes out of trading sessions",
+			zap.String("exchange", calendar.Code()),
+			zap.Int("quotes", len(qs)),
+			zap.Int("dropped", len(qs)-len(result)))
+	}
+
+	return result
+}
+
+// isDaily check quotes ordered by timestamp are daily bars, intraday bars of the same day are less than 12 hours apart
+func (s Simulate) isDaily(qs []*quotes.Quote) bool {
+	for index := 1; index < len(qs); index++ {
+		if qs[index].Timestamp-qs[index-1].Timestamp < 12*3600 {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (s Simulate) simulate(ctx context.Context, amount float64, qs []*quotes.Quote, system trade_system.TradeSystem) (*simulateResult, error) {
 	err := system.Init(ctx)
 	if err != nil {
@@ -19,6 +19,13 @@ type Config struct {
 		AppID     int    `toml:"app_id"`
 		AppSecret string `toml:"app_secret"`
 	} `toml:"wechat"`
+	// Quality define crawled quote validation, policies map issue to policy, eg price_jump = "drop_company"
+	// thresholds are nil if undefined, zero disables the check
+	Quality struct {
+		Policies         map[string]string `toml:"policies"`
+		MaxJump          *float64          `toml:"max_jump"`
+		MaxZeroVolumeRun *int              `toml:"max_zero_volume_run"`
+	} `toml:"quality"`
 	// Nsq struct {
 	// 	Broker  string `toml:"broker"`
 	// 	TLSCert string `toml:"tls_cert"`
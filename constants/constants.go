@@ -15,4 +15,9 @@ const (
 	AwsSqsMaxBatchSize = 10
 	// DefaultLastDays crawl last 20 days
 	DefaultLastDays = 20
+	// CrawlTimeout define timeout of crawling one company daily quote
+	CrawlTimeout = time.Minute * 5
 )
+
+// Version define software version, override by -ldflags "-X github.com/nzai/qr/constants.Version=v0.1.1"
+var Version = "v0.1.0"
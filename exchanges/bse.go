@@ -1,6 +1,7 @@
 package exchanges
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -49,10 +50,15 @@ func (s Bse) Location() *time.Location {
 
 // Companies get exchange companies
 func (s Bse) Companies() (map[string]*quotes.Company, error) {
+	return s.CompaniesContext(context.Background())
+}
+
+// CompaniesContext get exchange companies until context done
+func (s Bse) CompaniesContext(ctx context.Context) (map[string]*quotes.Company, error) {
 	companies := make(map[string]*quotes.Company)
 	pageIndex := 0
 	for {
-		totalPage, _companies, err := s.getCompanyByPageIndex(pageIndex)
+		totalPage, _companies, err := s.getCompanyByPageIndex(ctx, pageIndex)
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +77,7 @@ func (s Bse) Companies() (map[string]*quotes.Company, error) {
 	return companies, nil
 }
 
-func (s Bse) getCompanyByPageIndex(pageIndex int) (int, []*quotes.Company, error) {
+func (s Bse) getCompanyByPageIndex(ctx context.Context, pageIndex int) (int, []*quotes.Company, error) {
 	uv := url.Values{
 		"page":      []string{strconv.Itoa(pageIndex)},
 		"typejb":    []string{"T"},
@@ -80,7 +86,7 @@ func (s Bse) getCompanyByPageIndex(pageIndex int) (int, []*quotes.Company, error
 		"sorttype":  []string{"asc"},
 	}
 
-	request, _ := http.NewRequest(http.MethodPost, "https://www.bse.cn/nqxxController/nqxxCnzq.do?callback=jQuery331_1730808249531", strings.NewReader(uv.Encode()))
+	request, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://www.bse.cn/nqxxController/nqxxCnzq.do?callback=jQuery331_1730808249531", strings.NewReader(uv.Encode()))
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
 	request.Header.Set("Referer", "https://www.bse.cn/nq/listedcompany.html")
 	request.Header.Set("Origin", "https://www.bse.cn")
@@ -132,13 +138,17 @@ func (s Bse) getCompanyByPageIndex(pageIndex int) (int, []*quotes.Company, error
 
 // Crawl company daily quote
 func (s Bse) Crawl(company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
+	return s.CrawlContext(context.Background(), company, date)
+}
+
+// CrawlContext crawl company daily quote until context done
+func (s Bse) CrawlContext(ctx context.Context, company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
 	cdq := &quotes.CompanyDailyQuote{
-		Company:  company,
-		Dividend: &quotes.Dividend{Enable: false, Timestamp: 0, Amount: 0},
-		Split:    &quotes.Split{Enable: false, Timestamp: 0, Numerator: 0, Denominator: 0},
-		Pre:      new(quotes.Serial),
-		Regular:  new(quotes.Serial),
-		Post:     new(quotes.Serial),
+		Company: company,
+		Pre:     new(quotes.Serial),
+		Regular: new(quotes.Serial),
+		Post:    new(quotes.Serial),
+		Meta:    &quotes.CompanyMeta{Source: quotes.SourceBse},
 	}
 
 	if date.Before(utils.YesterdayZero(time.Now())) {
@@ -153,7 +163,7 @@ func (s Bse) Crawl(company *quotes.Company, date time.Time) (*quotes.CompanyDail
 		"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36",
 	}
 
-	code, buffer, err := utils.TryDownloadBytesWithHeader(u, header, constants.RetryCount, constants.RetryInterval)
+	code, buffer, err := utils.TryDownloadBytesWithHeaderContext(ctx, u, header, constants.RetryCount, constants.RetryInterval)
 	if err != nil {
 		zap.L().Warn("download yahoo finance quote failed", zap.Error(err), zap.String("url", u))
 		return nil, err
@@ -0,0 +1,450 @@
+package exchanges
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+const (
+	// calendarDatePattern define date pattern of calendar data files
+	calendarDatePattern = "2006-01-02"
+)
+
+// Clock define wall clock of day in minutes, eg 09:30 is 570
+type Clock int
+
+// ParseClock parse clock text like 09:30
+func ParseClock(text string) (Clock, error) {
+	t, err := time.Parse("15:04", text)
+	if err != nil {
+		return 0, fmt.Errorf("clock invalid: %s", text)
+	}
+
+	return Clock(t.Hour()*60 + t.Minute()), nil
+}
+
+// clockOf get wall clock of time
+func clockOf(t time.Time) Clock {
+	return Clock(t.Hour()*60 + t.Minute())
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", int(c)/60, int(c)%60)
+}
+
+// UnmarshalText parse clock from data file
+func (c *Clock) UnmarshalText(text []byte) error {
+	clock, err := ParseClock(string(text))
+	if err != nil {
+		return err
+	}
+
+	*c = clock
+	return nil
+}
+
+// On get time of clock on the date
+func (c Clock) On(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), int(c)/60, int(c)%60, 0, 0, date.Location())
+}
+
+// Session define trading session in exchange local clock, open is inclusive and close is exclusive
+type Session struct {
+	Open  Clock `toml:"open"`
+	Close Clock `toml:"close"`
+}
+
+// IsZero check session is undefined
+func (s Session) IsZero() bool {
+	return s.Open >= s.Close
+}
+
+// Contains check time clock is in session
+func (s Session) Contains(t time.Time) bool {
+	clock := clockOf(t)
+	return !s.IsZero() && clock >= s.Open && clock < s.Close
+}
+
+func (s Session) String() string {
+	return s.Open.String() + "-" + s.Close.String()
+}
+
+// Sessions define trading sessions of day, break is the midday break inside regular session
+type Sessions struct {
+	Pre     Session `toml:"pre"`
+	Regular Session `toml:"regular"`
+	Break   Session `toml:"break"`
+	Post    Session `toml:"post"`
+}
+
+// Calendar define exchange trading calendar, trading days are the days neither weekend nor holiday
+type Calendar struct {
+	code            string
+	location        *time.Location
+	weekends        map[time.Weekday]bool
+	sessions        Sessions
+	halfDaySessions Sessions
+	start           time.Time
+	end             time.Time
+	mutex           *sync.RWMutex
+	holidays        map[string]string
+	halfDays        map[string]string
+}
+
+// NewCalendar create calendar closed on weekends only
+func NewCalendar(code string, location *time.Location, sessions Sessions, weekends ...time.Weekday) *Calendar {
+	calendar := &Calendar{
+		code:            code,
+		location:        location,
+		weekends:        make(map[time.Weekday]bool, len(weekends)),
+		sessions:        sessions,
+		halfDaySessions: sessions,
+		mutex:           new(sync.RWMutex),
+		holidays:        make(map[string]string),
+		halfDays:        make(map[string]string),
+	}
+
+	for _, weekday := range weekends {
+		calendar.weekends[weekday] = true
+	}
+
+	return calendar
+}
+
+// calendarFile define calendar data file
+type calendarFile struct {
+	Start           string            `toml:"start"`
+	End             string            `toml:"end"`
+	Weekends        []string          `toml:"weekends"`
+	Sessions        Sessions          `toml:"sessions"`
+	HalfDaySessions *Sessions         `toml:"half_day_sessions"`
+	Holidays        map[string]string `toml:"holidays"`
+	HalfDays        map[string]string `toml:"half_days"`
+}
+
+// LoadCalendar load calendar from toml data file, dates and clocks are in location
+func LoadCalendar(code string, location *time.Location, r io.Reader) (*Calendar, error) {
+	file := new(calendarFile)
+	_, err := toml.NewDecoder(r).Decode(file)
+	if err != nil {
+		zap.L().Error("decode calendar file failed", zap.Error(err), zap.String("exchange", code))
+		return nil, err
+	}
+
+	weekends := make([]time.Weekday, 0, len(file.Weekends))
+	for _, name := range file.Weekends {
+		weekday, err := parseWeekday(name)
+		if err != nil {
+			zap.L().Error("parse calendar weekend failed", zap.Error(err), zap.String("exchange", code))
+			return nil, err
+		}
+
+		weekends = append(weekends, weekday)
+	}
+
+	calendar := NewCalendar(code, location, file.Sessions, weekends...)
+	if file.HalfDaySessions != nil {
+		calendar.halfDaySessions = *file.HalfDaySessions
+	}
+
+	calendar.start, err = time.ParseInLocation(calendarDatePattern, file.Start, location)
+	if err != nil {
+		return nil, fmt.Errorf("calendar start date invalid: %s", file.Start)
+	}
+
+	calendar.end, err = time.ParseInLocation(calendarDatePattern, file.End, location)
+	if err != nil {
+		return nil, fmt.Errorf("calendar end date invalid: %s", file.End)
+	}
+
+	for text, name := range file.Holidays {
+		date, err := time.ParseInLocation(calendarDatePattern, text, location)
+		if err != nil {
+			return nil, fmt.Errorf("calendar holiday invalid: %s", text)
+		}
+
+		calendar.AddHoliday(date, name)
+	}
+
+	for text, name := range file.HalfDays {
+		date, err := time.ParseInLocation(calendarDatePattern, text, location)
+		if err != nil {
+			return nil, fmt.Errorf("calendar half day invalid: %s", text)
+		}
+
+		calendar.halfDays[calendar.key(date)] = name
+	}
+
+	return calendar, nil
+}
+
+// parseWeekday parse weekday name like Saturday
+func parseWeekday(name string) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(weekday.String(), name) {
+			return weekday, nil
+		}
+	}
+
+	return 0, fmt.Errorf("weekday invalid: %s", name)
+}
+
+// Code get exchange code of calendar
+func (c Calendar) Code() string {
+	return c.code
+}
+
+// Location get calendar location
+func (c Calendar) Location() *time.Location {
+	return c.location
+}
+
+// key get calendar date key of time
+func (c Calendar) key(date time.Time) string {
+	return date.In(c.location).Format(calendarDatePattern)
+}
+
+// day truncate time to zero clock of its date in calendar location
+func (c Calendar) day(t time.Time) time.Time {
+	t = t.In(c.location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.location)
+}
+
+// Covers check date is covered by calendar data, holidays out of data are unknown until learned
+func (c Calendar) Covers(date time.Time) bool {
+	date = date.In(c.location)
+	return !c.start.IsZero() && !date.Before(c.start) && date.Before(c.end.AddDate(0, 0, 1))
+}
+
+// IsWeekend check date is weekend
+func (c Calendar) IsWeekend(date time.Time) bool {
+	return c.weekends[date.In(c.location).Weekday()]
+}
+
+// Holiday get holiday name, return false if date is not holiday
+func (c Calendar) Holiday(date time.Time) (string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	name, found := c.holidays[c.key(date)]
+	return name, found
+}
+
+// AddHoliday add holiday to calendar, return false if date is not trading day already
+func (c Calendar) AddHoliday(date time.Time, name string) bool {
+	if c.IsWeekend(date) {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := c.key(date)
+	if _, found := c.holidays[key]; found {
+		return false
+	}
+
+	c.holidays[key] = name
+	return true
+}
+
+// Holidays list holidays between start and end date (inclusive), ordered by date
+func (c Calendar) Holidays(start, end time.Time) []time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	startKey, endKey := c.key(start), c.key(end)
+	var dates []time.Time
+	for key := range c.holidays {
+		if key < startKey || key > endKey {
+			continue
+		}
+
+		date, _ := time.ParseInLocation(calendarDatePattern, key, c.location)
+		dates = append(dates, date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	return dates
+}
+
+// IsTradingDay check exchange is open on date
+func (c Calendar) IsTradingDay(date time.Time) bool {
+	if c.IsWeekend(date) {
+		return false
+	}
+
+	_, found := c.Holiday(date)
+	return !found
+}
+
+// IsHalfDay check exchange closes early on date
+func (c Calendar) IsHalfDay(date time.Time) bool {
+	_, found := c.halfDays[c.key(date)]
+	return found && c.IsTradingDay(date)
+}
+
+// Sessions get trading sessions of date, return false if date is not trading day
+func (c Calendar) Sessions(date time.Time) (Sessions, bool) {
+	if !c.IsTradingDay(date) {
+		return Sessions{}, false
+	}
+
+	if c.IsHalfDay(date) {
+		return c.halfDaySessions, true
+	}
+
+	return c.sessions, true
+}
+
+// IsOpen check exchange is trading at time, pre and post sessions count only if extended
+func (c Calendar) IsOpen(t time.Time, extended bool) bool {
+	t = t.In(c.location)
+	sessions, ok := c.Sessions(t)
+	if !ok {
+		return false
+	}
+
+	if sessions.Regular.Contains(t) {
+		return !sessions.Break.Contains(t)
+	}
+
+	return extended && (sessions.Pre.Contains(t) || sessions.Post.Contains(t))
+}
+
+// SessionWindow get session window of date to validate quotes, return nil if date is not trading day or sessions are unknown
+// pre and post serials must be inside pre and post sessions, regular serial inside regular session but out of break
+func (c Calendar) SessionWindow(date time.Time) quotes.SessionWindow {
+	sessions, ok := c.Sessions(date)
+	if !ok || sessions.Regular.IsZero() {
+		return nil
+	}
+
+	return func(serialType quotes.SerialType, t time.Time) bool {
+		t = t.In(c.location)
+		if !c.day(t).Equal(c.day(date)) {
+			return false
+		}
+
+		switch serialType {
+		case quotes.SerialTypePre:
+			return sessions.Pre.Contains(t)
+		case quotes.SerialTypeRegular:
+			return sessions.Regular.Contains(t) && !sessions.Break.Contains(t)
+		case quotes.SerialTypePost:
+			return sessions.Post.Contains(t)
+		default:
+			return false
+		}
+	}
+}
+
+// TradingDays list trading days between start and end date (inclusive)
+func (c Calendar) TradingDays(start, end time.Time) []time.Time {
+	var dates []time.Time
+	for date := c.day(start); !date.After(end); date = date.AddDate(0, 0, 1) {
+		if c.IsTradingDay(date) {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+}
+
+// Next get the first trading day after date
+func (c Calendar) Next(date time.Time) time.Time {
+	date = c.day(date).AddDate(0, 0, 1)
+	for !c.IsTradingDay(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date
+}
+
+// Previous get the last trading day before date
+func (c Calendar) Previous(date time.Time) time.Time {
+	date = c.day(date).AddDate(0, 0, -1)
+	for !c.IsTradingDay(date) {
+		date = date.AddDate(0, 0, -1)
+	}
+
+	return date
+}
+
+//go:embed calendars/*.toml
+var calendarData embed.FS
+
+// calendarFiles define bundled calendar data file of exchanges
+var calendarFiles = map[string]string{
+	"Nyse":   "calendars/us.toml",
+	"Nasdaq": "calendars/us.toml",
+	"Amex":   "calendars/us.toml",
+	"Sse":    "calendars/cn.toml",
+	"Szse":   "calendars/cn.toml",
+	"Bse":    "calendars/cn.toml",
+	"Hkex":   "calendars/hk.toml",
+}
+
+var (
+	_calendars     = map[string]*Calendar{}
+	calendarsMutex = new(sync.Mutex)
+)
+
+// RegisterCalendar replace exchange calendar, eg calendar loaded from other data file
+func RegisterCalendar(calendar *Calendar) {
+	calendarsMutex.Lock()
+	defer calendarsMutex.Unlock()
+
+	_calendars[calendar.Code()] = calendar
+}
+
+// GetCalendar get exchange calendar, bundled data is loaded at the first time
+// exchange without bundled data or whose data is broken gets a calendar only closed on weekends
+func GetCalendar(exchange Exchange) *Calendar {
+	calendarsMutex.Lock()
+	defer calendarsMutex.Unlock()
+
+	calendar, found := _calendars[exchange.Code()]
+	if found {
+		return calendar
+	}
+
+	calendar, err := loadBundledCalendar(exchange)
+	if err != nil {
+		zap.L().Warn("load bundled calendar failed, use weekends only calendar",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()))
+		calendar = NewCalendar(exchange.Code(), exchange.Location(), Sessions{}, time.Saturday, time.Sunday)
+	}
+
+	_calendars[exchange.Code()] = calendar
+	return calendar
+}
+
+// loadBundledCalendar load exchange calendar from bundled data file
+func loadBundledCalendar(exchange Exchange) (*Calendar, error) {
+	path, found := calendarFiles[exchange.Code()]
+	if !found {
+		return nil, fmt.Errorf("exchange %s has no bundled calendar", exchange.Code())
+	}
+
+	file, err := calendarData.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadCalendar(exchange.Code(), exchange.Location(), file)
+}
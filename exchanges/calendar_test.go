@@ -0,0 +1,130 @@
+package exchanges
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/quotes"
+)
+
+func TestGetCalendar(t *testing.T) {
+	for _, exchange := range All() {
+		calendar := GetCalendar(exchange)
+		for _, year := range []int{2015, 2024} {
+			if !calendar.Covers(time.Date(year, 6, 3, 0, 0, 0, 0, exchange.Location())) {
+				t.Errorf("%s calendar does not cover %d", exchange.Code(), year)
+			}
+		}
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	nyse, _ := Get("Nyse")
+	calendar := GetCalendar(nyse)
+	location := nyse.Location()
+
+	tests := []struct {
+		date    time.Time
+		trading bool
+		half    bool
+	}{
+		{time.Date(2024, 7, 3, 0, 0, 0, 0, location), true, true},
+		{time.Date(2024, 7, 4, 0, 0, 0, 0, location), false, false},
+		{time.Date(2024, 7, 5, 0, 0, 0, 0, location), true, false},
+		{time.Date(2024, 7, 6, 0, 0, 0, 0, location), false, false},
+		{time.Date(2025, 1, 9, 0, 0, 0, 0, location), false, false},
+		{time.Date(2018, 12, 5, 0, 0, 0, 0, location), false, false},
+		{time.Date(2019, 11, 29, 0, 0, 0, 0, location), true, true},
+	}
+
+	for _, tt := range tests {
+		if calendar.IsTradingDay(tt.date) != tt.trading {
+			t.Errorf("IsTradingDay(%s) = %v, want %v", tt.date.Format("2006-01-02"), !tt.trading, tt.trading)
+		}
+
+		if calendar.IsHalfDay(tt.date) != tt.half {
+			t.Errorf("IsHalfDay(%s) = %v, want %v", tt.date.Format("2006-01-02"), !tt.half, tt.half)
+		}
+	}
+
+	days := calendar.TradingDays(time.Date(2024, 7, 1, 0, 0, 0, 0, location), time.Date(2024, 7, 7, 0, 0, 0, 0, location))
+	if len(days) != 4 {
+		t.Errorf("TradingDays() = %d days, want 4", len(days))
+	}
+
+	next := calendar.Next(time.Date(2024, 7, 3, 0, 0, 0, 0, location))
+	if next.Day() != 5 {
+		t.Errorf("Next() = %s, want 2024-07-05", next)
+	}
+
+	previous := calendar.Previous(time.Date(2024, 7, 8, 0, 0, 0, 0, location))
+	if previous.Day() != 5 {
+		t.Errorf("Previous() = %s, want 2024-07-05", previous)
+	}
+
+	// half day closes at 13:00
+	if !calendar.IsOpen(time.Date(2024, 7, 3, 12, 59, 0, 0, location), false) {
+		t.Errorf("IsOpen() half day 12:59 = false")
+	}
+
+	if calendar.IsOpen(time.Date(2024, 7, 3, 14, 0, 0, 0, location), false) {
+		t.Errorf("IsOpen() half day 14:00 = true")
+	}
+
+	if !calendar.IsOpen(time.Date(2024, 7, 3, 14, 0, 0, 0, location), true) {
+		t.Errorf("IsOpen() half day post market 14:00 = false")
+	}
+}
+
+func TestCalendar_Break(t *testing.T) {
+	hkex, _ := Get("Hkex")
+	calendar := GetCalendar(hkex)
+	location := hkex.Location()
+
+	tests := []struct {
+		time time.Time
+		open bool
+	}{
+		{time.Date(2024, 6, 3, 9, 30, 0, 0, location), true},
+		{time.Date(2024, 6, 3, 12, 30, 0, 0, location), false},
+		{time.Date(2024, 6, 3, 15, 59, 0, 0, location), true},
+		{time.Date(2024, 6, 3, 16, 0, 0, 0, location), false},
+		{time.Date(2024, 12, 24, 13, 30, 0, 0, location), false},
+		{time.Date(2024, 12, 25, 10, 0, 0, 0, location), false},
+	}
+
+	for _, tt := range tests {
+		if calendar.IsOpen(tt.time, false) != tt.open {
+			t.Errorf("IsOpen(%s) = %v, want %v", tt.time, !tt.open, tt.open)
+		}
+	}
+}
+
+func TestCalendar_SessionWindow(t *testing.T) {
+	nasdaq, _ := Get("Nasdaq")
+	calendar := GetCalendar(nasdaq)
+	location := nasdaq.Location()
+
+	if calendar.SessionWindow(time.Date(2024, 7, 4, 0, 0, 0, 0, location)) != nil {
+		t.Errorf("SessionWindow() of holiday is not nil")
+	}
+
+	window := calendar.SessionWindow(time.Date(2024, 7, 5, 0, 0, 0, 0, location))
+	tests := []struct {
+		serialType quotes.SerialType
+		time       time.Time
+		in         bool
+	}{
+		{quotes.SerialTypePre, time.Date(2024, 7, 5, 8, 0, 0, 0, location), true},
+		{quotes.SerialTypeRegular, time.Date(2024, 7, 5, 8, 0, 0, 0, location), false},
+		{quotes.SerialTypeRegular, time.Date(2024, 7, 5, 15, 59, 0, 0, location), true},
+		{quotes.SerialTypePost, time.Date(2024, 7, 5, 19, 59, 0, 0, location), true},
+		{quotes.SerialTypeRegular, time.Date(2024, 7, 8, 10, 0, 0, 0, location), false},
+	}
+
+	for _, tt := range tests {
+		if window(tt.serialType, tt.time) != tt.in {
+			t.Errorf("SessionWindow() %s %s = %v, want %v", tt.serialType, tt.time, !tt.in, tt.in)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package exchanges
+
+import (
+	"context"
+	"time"
+
+	"github.com/nzai/qr/quotes"
+)
+
+// ContextExchange define exchange can be cancelled by context
+type ContextExchange interface {
+	Exchange
+	// CompaniesContext get exchange companies until context done
+	CompaniesContext(context.Context) (map[string]*quotes.Company, error)
+	// CrawlContext crawl company daily quote until context done
+	CrawlContext(context.Context, *quotes.Company, time.Time) (*quotes.CompanyDailyQuote, error)
+}
+
+// WithContext adapt exchange to ContextExchange, use native implement if exchange is a ContextExchange
+func WithContext(exchange Exchange) ContextExchange {
+	if ce, ok := exchange.(ContextExchange); ok {
+		return ce
+	}
+
+	return contextExchange{exchange}
+}
+
+// contextExchange adapt Exchange to ContextExchange, give up waiting the call when context done
+type contextExchange struct {
+	Exchange
+}
+
+// CompaniesContext get exchange companies until context done
+func (e contextExchange) CompaniesContext(ctx context.Context) (map[string]*quotes.Company, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		companies map[string]*quotes.Company
+		err       error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		companies, err := e.Companies()
+		done <- result{companies, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.companies, r.err
+	}
+}
+
+// CrawlContext crawl company daily quote until context done
+func (e contextExchange) CrawlContext(ctx context.Context, company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		cdq *quotes.CompanyDailyQuote
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		cdq, err := e.Crawl(company, date)
+		done <- result{cdq, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.cdq, r.err
+	}
+}
@@ -2,6 +2,7 @@ package exchanges
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,6 +29,20 @@ func Get(code string) (Exchange, bool) {
 	return exchange, found
 }
 
+// All get all registered exchanges order by code
+func All() []Exchange {
+	exchanges := make([]Exchange, 0, len(_exchanges))
+	for _, exchange := range _exchanges {
+		exchanges = append(exchanges, exchange)
+	}
+
+	sort.Slice(exchanges, func(i, j int) bool {
+		return exchanges[i].Code() < exchanges[j].Code()
+	})
+
+	return exchanges
+}
+
 // Parse parse command argument
 func Parse(arg string) ([]Exchange, error) {
 	parts := strings.Split(arg, ",")
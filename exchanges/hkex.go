@@ -1,6 +1,7 @@
 package exchanges
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
@@ -41,6 +42,11 @@ func (s Hkex) Location() *time.Location {
 
 // Companies get exchange companies
 func (s Hkex) Companies() (map[string]*quotes.Company, error) {
+	return s.CompaniesContext(context.Background())
+}
+
+// CompaniesContext get exchange companies until context done
+func (s Hkex) CompaniesContext(ctx context.Context) (map[string]*quotes.Company, error) {
 
 	source := map[string]string{
 		"http://www.hkex.com.hk/Market-Data/Securities-Prices/Equities?sc_lang=zh-HK":                      "https://www1.hkex.com.hk/hkexwidget/data/getequityfilter?lang=chi&token=%s&sort=5&order=0&all=1&qid=%d&callback=3322", // 股本證券
@@ -53,7 +59,7 @@ func (s Hkex) Companies() (map[string]*quotes.Company, error) {
 
 	companies := make(map[string]*quotes.Company)
 	for page, api := range source {
-		_companies, err := s.queryCompanies(page, api)
+		_companies, err := s.queryCompanies(ctx, page, api)
 		if err != nil {
 			zap.L().Error("query hkex companies failed", zap.Error(err), zap.String("page", page), zap.String("api", api))
 			return nil, err
@@ -73,8 +79,8 @@ func (s Hkex) Companies() (map[string]*quotes.Company, error) {
 }
 
 // queryCompanies query companies of special category
-func (s Hkex) queryCompanies(page, api string) ([]*quotes.Company, error) {
-	body, err := utils.TryDownloadString(page, constants.RetryCount, constants.RetryInterval)
+func (s Hkex) queryCompanies(ctx context.Context, page, api string) ([]*quotes.Company, error) {
+	body, err := utils.TryDownloadStringContext(ctx, page, constants.RetryCount, constants.RetryInterval)
 	if err != nil {
 		zap.L().Error("download hkex page failed", zap.Error(err), zap.String("url", page))
 		return nil, err
@@ -88,7 +94,7 @@ func (s Hkex) queryCompanies(page, api string) ([]*quotes.Company, error) {
 	}
 
 	url := fmt.Sprintf(api, matches[1], time.Now().UnixNano())
-	body, err = utils.TryDownloadString(url, constants.RetryCount, constants.RetryInterval)
+	body, err = utils.TryDownloadStringContext(ctx, url, constants.RetryCount, constants.RetryInterval)
 	if err != nil {
 		zap.L().Error("download hkex companies failed", zap.Error(err), zap.String("url", url), zap.String("token", matches[1]))
 		return nil, err
@@ -107,5 +113,10 @@ func (s Hkex) queryCompanies(page, api string) ([]*quotes.Company, error) {
 
 // Crawl company daily quote
 func (s Hkex) Crawl(company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
-	return s.source.Crawl(company, date, ".HK")
+	return s.CrawlContext(context.Background(), company, date)
+}
+
+// CrawlContext crawl company daily quote until context done
+func (s Hkex) CrawlContext(ctx context.Context, company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
+	return sources.WithContext(s.source).CrawlContext(ctx, company, date, ".HK")
 }
@@ -1,6 +1,7 @@
 package exchanges
 
 import (
+	"context"
 	"time"
 
 	"github.com/nzai/qr/quotes"
@@ -40,10 +41,20 @@ func (s Nasdaq) Location() *time.Location {
 
 // Companies get exchange companies
 func (s Nasdaq) Companies() (map[string]*quotes.Company, error) {
-	return s.companySource.Companies(s.Code())
+	return s.CompaniesContext(context.Background())
+}
+
+// CompaniesContext get exchange companies until context done
+func (s Nasdaq) CompaniesContext(ctx context.Context) (map[string]*quotes.Company, error) {
+	return s.companySource.CompaniesContext(ctx, s.Code())
 }
 
 // Crawl company daily quote
 func (s Nasdaq) Crawl(company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
-	return s.source.Crawl(company, date, "")
+	return s.CrawlContext(context.Background(), company, date)
+}
+
+// CrawlContext crawl company daily quote until context done
+func (s Nasdaq) CrawlContext(ctx context.Context, company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
+	return sources.WithContext(s.source).CrawlContext(ctx, company, date, "")
 }
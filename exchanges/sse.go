@@ -1,6 +1,7 @@
 package exchanges
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"regexp"
@@ -22,7 +23,7 @@ func init() {
 type Sse struct {
 	source         sources.Source
 	location       *time.Location
-	sd             sources.SplitDividendSource
+	sd             sources.ContextActionSource
 	validCodeRegex *regexp.Regexp
 }
 
@@ -49,10 +50,15 @@ func (s Sse) Location() *time.Location {
 
 // Companies get exchange companies
 func (s Sse) Companies() (map[string]*quotes.Company, error) {
+	return s.CompaniesContext(context.Background())
+}
+
+// CompaniesContext get exchange companies until context done
+func (s Sse) CompaniesContext(ctx context.Context) (map[string]*quotes.Company, error) {
 	companies := make(map[string]*quotes.Company)
 	pageIndex := 1
 	for {
-		totalPage, _companies, err := s.getCompanyByPageIndex(pageIndex)
+		totalPage, _companies, err := s.getCompanyByPageIndex(ctx, pageIndex)
 		if err != nil {
 			return nil, err
 		}
@@ -70,7 +76,7 @@ func (s Sse) Companies() (map[string]*quotes.Company, error) {
 	return companies, nil
 }
 
-func (s Sse) getCompanyByPageIndex(pageIndex int) (int, []*quotes.Company, error) {
+func (s Sse) getCompanyByPageIndex(ctx context.Context, pageIndex int) (int, []*quotes.Company, error) {
 	pi := strconv.Itoa(pageIndex)
 	now := strconv.FormatInt(time.Now().Unix()*1000, 10)
 	url := os.Expand("http://query.sse.com.cn/sseQuery/commonQuery.do?jsonCallBack=jsonpCallback67704651&STOCK_TYPE=1&REG_PROVINCE=&CSRC_CODE=&STOCK_CODE=&sqlId=COMMON_SSE_CP_GPJCTPZ_GPLB_GP_L&COMPANY_STATUS=2%2C4%2C5%2C7%2C8&type=inParams&isPagination=true&pageHelp.cacheSize=1&pageHelp.beginPage=${index}&pageHelp.pageSize=100&pageHelp.pageNo=${index}&pageHelp.endPage=${index}&_=${now}",
@@ -89,7 +95,7 @@ func (s Sse) getCompanyByPageIndex(pageIndex int) (int, []*quotes.Company, error
 		"Referer": "http://www.sse.com.cn/",
 	}
 	// download excel from sse
-	_, html, err := utils.TryDownloadBytesWithHeader(url, header, constants.RetryCount, constants.RetryInterval)
+	_, html, err := utils.TryDownloadBytesWithHeaderContext(ctx, url, header, constants.RetryCount, constants.RetryInterval)
 	if err != nil {
 		zap.L().Error("download sse companies failed", zap.Error(err), zap.String("url", url))
 		return 0, nil, err
@@ -125,24 +131,31 @@ func (s Sse) getCompanyByPageIndex(pageIndex int) (int, []*quotes.Company, error
 
 // Crawl company daily quote
 func (s Sse) Crawl(company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
+	return s.CrawlContext(context.Background(), company, date)
+}
+
+// CrawlContext crawl company daily quote until context done
+func (s Sse) CrawlContext(ctx context.Context, company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
 	// 分时数据从雅虎抓取
-	cdq, err := s.source.Crawl(company, date, ".SS")
+	cdq, err := sources.WithContext(s.source).CrawlContext(ctx, company, date, ".SS")
 	if err != nil {
 		return nil, err
 	}
 
 	// 因为雅虎财经api中关于上海和深证交易所的股票拆分/送股信息是错误的，所以分红配股单独查询
-	dividend, split, err := s.sd.QuerySplitAndDividend(company, date)
+	actions, err := s.sd.QueryActionsContext(ctx, company, date)
 	if err != nil {
-		// zap.L().Error("query split and dividend failed",
+		// zap.L().Error("query corporate actions failed",
 		// 	zap.Error(err),
 		// 	zap.Any("company", company),
 		// 	zap.Time("date", date))
 		return nil, err
 	}
 
-	cdq.Dividend = dividend
-	cdq.Split = split
+	cdq.Actions = actions
+	if cdq.Meta != nil {
+		cdq.Meta.EventSource = quotes.SourceIFeng
+	}
 
 	return cdq, nil
 }
@@ -2,6 +2,7 @@ package exchanges
 
 import (
 	"bytes"
+	"context"
 	"time"
 
 	"github.com/360EntSecGroup-Skylar/excelize"
@@ -21,7 +22,7 @@ func init() {
 type Szse struct {
 	source   sources.Source
 	location *time.Location
-	sd       sources.SplitDividendSource
+	sd       sources.ContextActionSource
 }
 
 // NewSzse create shenzhen stock exchange
@@ -46,6 +47,11 @@ func (s Szse) Location() *time.Location {
 
 // Companies get exchange companies
 func (s Szse) Companies() (map[string]*quotes.Company, error) {
+	return s.CompaniesContext(context.Background())
+}
+
+// CompaniesContext get exchange companies until context done
+func (s Szse) CompaniesContext(ctx context.Context) (map[string]*quotes.Company, error) {
 	urls := map[string][]int{
 		"http://www.szse.cn/api/report/ShowReport?SHOWTYPE=xlsx&CATALOGID=1110&TABKEY=tab1&random=0.49987789273726513": {4},
 		"http://www.szse.cn/api/report/ShowReport?SHOWTYPE=xlsx&CATALOGID=1110&TABKEY=tab2&random=0.42963499040546527": {9},
@@ -54,7 +60,7 @@ func (s Szse) Companies() (map[string]*quotes.Company, error) {
 
 	companies := make(map[string]*quotes.Company)
 	for url, columns := range urls {
-		_companies, err := s.getByURL(url, columns)
+		_companies, err := s.getByURL(ctx, url, columns)
 		if err != nil {
 			return nil, err
 		}
@@ -73,9 +79,9 @@ func (s Szse) Companies() (map[string]*quotes.Company, error) {
 	return companies, nil
 }
 
-func (s Szse) getByURL(url string, columns []int) (map[string]*quotes.Company, error) {
+func (s Szse) getByURL(ctx context.Context, url string, columns []int) (map[string]*quotes.Company, error) {
 	// download html from sse
-	_, html, err := utils.TryDownloadBytes(url, constants.RetryCount, constants.RetryInterval)
+	_, html, err := utils.TryDownloadBytesContext(ctx, url, constants.RetryCount, constants.RetryInterval)
 	if err != nil {
 		zap.L().Error("download szse companies failed", zap.Error(err), zap.String("url", url))
 		return nil, err
@@ -114,24 +120,31 @@ func (s Szse) getByURL(url string, columns []int) (map[string]*quotes.Company, e
 
 // Crawl company daily quote
 func (s Szse) Crawl(company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
+	return s.CrawlContext(context.Background(), company, date)
+}
+
+// CrawlContext crawl company daily quote until context done
+func (s Szse) CrawlContext(ctx context.Context, company *quotes.Company, date time.Time) (*quotes.CompanyDailyQuote, error) {
 	// 分时数据从雅虎抓取
-	cdq, err := s.source.Crawl(company, date, ".SZ")
+	cdq, err := sources.WithContext(s.source).CrawlContext(ctx, company, date, ".SZ")
 	if err != nil {
 		return nil, err
 	}
 
 	// 因为雅虎财经api中关于上海和深证交易所的股票拆分/送股信息是错误的，所以分红配股单独查询
-	dividend, split, err := s.sd.QuerySplitAndDividend(company, date)
+	actions, err := s.sd.QueryActionsContext(ctx, company, date)
 	if err != nil {
-		zap.L().Error("query split and dividend failed",
+		zap.L().Error("query corporate actions failed",
 			zap.Error(err),
 			zap.Any("company", company),
 			zap.Time("date", date))
 		return nil, err
 	}
 
-	cdq.Dividend = dividend
-	cdq.Split = split
+	cdq.Actions = actions
+	if cdq.Meta != nil {
+		cdq.Meta.EventSource = quotes.SourceIFeng
+	}
 
 	return cdq, nil
 }
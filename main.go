@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/nzai/qr/config"
 	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
 	"github.com/nzai/qr/schedulers"
 	"github.com/nzai/qr/stores"
 	"github.com/nzai/qr/utils"
@@ -62,9 +67,58 @@ func main() {
 			zap.String("arg", conf.Exchanges))
 	}
 
+	// stop jobs gracefully on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	validator, err := newValidator(conf)
+	if err != nil {
+		zap.L().Fatal("parse quality config failed", zap.Error(err))
+	}
+
 	scheduler := schedulers.NewScheduler(store, _exchanges...)
-	wg := scheduler.Run(startDate)
+	scheduler.SetValidator(validator)
+	wg := scheduler.Run(ctx, startDate)
 	wg.Wait()
+
+	zap.L().Info("qr stopped")
+}
+
+// newValidator create crawled quote validator, config overrides default policies and thresholds
+func newValidator(conf *config.Config) (*quotes.Validator, error) {
+	validator := quotes.NewValidator()
+	for name, policyName := range conf.Quality.Policies {
+		issue, err := quotes.ParseIssue(name)
+		if err != nil {
+			return nil, err
+		}
+
+		policy, err := quotes.ParsePolicy(policyName)
+		if err != nil {
+			return nil, err
+		}
+
+		err = validator.SetPolicy(issue, policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.Quality.MaxJump != nil {
+		if *conf.Quality.MaxJump < 0 {
+			return nil, fmt.Errorf("quality.max_jump invalid: %v", *conf.Quality.MaxJump)
+		}
+		validator.MaxJump = *conf.Quality.MaxJump
+	}
+
+	if conf.Quality.MaxZeroVolumeRun != nil {
+		if *conf.Quality.MaxZeroVolumeRun < 0 {
+			return nil, fmt.Errorf("quality.max_zero_volume_run invalid: %d", *conf.Quality.MaxZeroVolumeRun)
+		}
+		validator.MaxZeroVolumeRun = *conf.Quality.MaxZeroVolumeRun
+	}
+
+	return validator, nil
 }
 
 func initLogger(logPath string) (*zap.Logger, error) {
@@ -0,0 +1,379 @@
+package quotes
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/nzai/bio"
+	"go.uber.org/zap"
+)
+
+// ActionType define corporate action type
+type ActionType byte
+
+const (
+	// ActionCashDividend cash dividend, amount per share
+	ActionCashDividend ActionType = iota + 1
+	// ActionStockDividend stock dividend, numerator bonus shares for every denominator shares held
+	ActionStockDividend
+	// ActionSplit stock split, numerator shares after split for every denominator shares before
+	ActionSplit
+	// ActionRightsIssue rights issue, numerator new shares for every denominator shares held, subscribed at amount per share
+	ActionRightsIssue
+)
+
+var actionTypeNames = map[ActionType]string{
+	ActionCashDividend:  "cash_dividend",
+	ActionStockDividend: "stock_dividend",
+	ActionSplit:         "split",
+	ActionRightsIssue:   "rights_issue",
+}
+
+func (t ActionType) String() string {
+	name, found := actionTypeNames[t]
+	if !found {
+		return fmt.Sprintf("action type(%d)", byte(t))
+	}
+
+	return name
+}
+
+// ParseActionType parse action type name
+func ParseActionType(name string) (ActionType, error) {
+	for actionType, actionName := range actionTypeNames {
+		if actionName == name {
+			return actionType, nil
+		}
+	}
+
+	return 0, fmt.Errorf("action type invalid: %s", name)
+}
+
+// Action define corporate action
+type Action struct {
+	Type        ActionType
+	Timestamp   uint64
+	Amount      float32
+	Numerator   float32
+	Denominator float32
+}
+
+// Ratio get shares after action for every share before, return 1 if action does not change shares or is invalid
+// split convention is only applied to split actions
+func (a Action) Ratio(convention SplitConvention) float64 {
+	if a.Denominator <= 0 || a.Numerator <= 0 {
+		return 1
+	}
+
+	switch a.Type {
+	case ActionSplit:
+		if convention == SplitBonus {
+			return float64(a.Denominator+a.Numerator) / float64(a.Denominator)
+		}
+
+		return float64(a.Numerator) / float64(a.Denominator)
+	case ActionStockDividend, ActionRightsIssue:
+		return float64(a.Denominator+a.Numerator) / float64(a.Denominator)
+	default:
+		return 1
+	}
+}
+
+// Encode encode action to io.Writer
+func (a Action) Encode(w io.Writer) error {
+	bw := bio.NewBinaryWriter(w)
+
+	_, err := bw.UInt8(uint8(a.Type))
+	if err != nil {
+		zap.L().Error("encode action type failed", zap.Error(err), zap.Stringer("type", a.Type))
+		return err
+	}
+
+	_, err = bw.UInt64(a.Timestamp)
+	if err != nil {
+		zap.L().Error("encode action timestamp failed", zap.Error(err), zap.Uint64("timestamp", a.Timestamp))
+		return err
+	}
+
+	_, err = bw.Float32(a.Amount)
+	if err != nil {
+		zap.L().Error("encode action amount failed", zap.Error(err), zap.Float32("amount", a.Amount))
+		return err
+	}
+
+	_, err = bw.Float32(a.Numerator)
+	if err != nil {
+		zap.L().Error("encode action numerator failed", zap.Error(err), zap.Float32("numerator", a.Numerator))
+		return err
+	}
+
+	_, err = bw.Float32(a.Denominator)
+	if err != nil {
+		zap.L().Error("encode action denominator failed", zap.Error(err), zap.Float32("denominator", a.Denominator))
+		return err
+	}
+
+	return nil
+}
+
+// Decode decode action from io.Reader
+func (a *Action) Decode(r io.Reader) error {
+	br := bio.NewBinaryReader(r)
+
+	actionType, err := br.UInt8()
+	if err != nil {
+		zap.L().Error("decode action type failed", zap.Error(err))
+		return err
+	}
+
+	timestamp, err := br.UInt64()
+	if err != nil {
+		zap.L().Error("decode action timestamp failed", zap.Error(err))
+		return err
+	}
+
+	amount, err := br.Float32()
+	if err != nil {
+		zap.L().Error("decode action amount failed", zap.Error(err))
+		return err
+	}
+
+	numerator, err := br.Float32()
+	if err != nil {
+		zap.L().Error("decode action numerator failed", zap.Error(err))
+		return err
+	}
+
+	denominator, err := br.Float32()
+	if err != nil {
+		zap.L().Error("decode action denominator failed", zap.Error(err))
+		return err
+	}
+
+	a.Type = ActionType(actionType)
+	a.Timestamp = timestamp
+	a.Amount = amount
+	a.Numerator = numerator
+	a.Denominator = denominator
+
+	return nil
+}
+
+// Equal check action is equal
+func (a Action) Equal(b Action) error {
+	if a.Type != b.Type {
+		return fmt.Errorf("action type %s is different from %s", a.Type, b.Type)
+	}
+
+	if a.Timestamp != b.Timestamp {
+		return fmt.Errorf("%s timestamp %d is different from %d", a.Type, a.Timestamp, b.Timestamp)
+	}
+
+	if a.Amount != b.Amount {
+		return fmt.Errorf("%s amount %f is different from %f", a.Type, a.Amount, b.Amount)
+	}
+
+	if a.Numerator != b.Numerator {
+		return fmt.Errorf("%s numerator %f is different from %f", a.Type, a.Numerator, b.Numerator)
+	}
+
+	if a.Denominator != b.Denominator {
+		return fmt.Errorf("%s denominator %f is different from %f", a.Type, a.Denominator, b.Denominator)
+	}
+
+	return nil
+}
+
+// Action convert enabled dividend to cash dividend action
+func (d Dividend) Action() Action {
+	return Action{Type: ActionCashDividend, Timestamp: d.Timestamp, Amount: d.Amount}
+}
+
+// Action convert enabled split to split action
+func (s Split) Action() Action {
+	return Action{Type: ActionSplit, Timestamp: s.Timestamp, Numerator: s.Numerator, Denominator: s.Denominator}
+}
+
+// Actions define corporate actions of company daily quote, ordered by timestamp and type
+type Actions []Action
+
+// NewActions create actions from legacy dividend and split, disabled or nil ones are ignored
+func NewActions(dividend *Dividend, split *Split) Actions {
+	var actions Actions
+	if dividend != nil && dividend.Enable {
+		actions = append(actions, dividend.Action())
+	}
+
+	if split != nil && split.Enable {
+		actions = append(actions, split.Action())
+	}
+
+	sort.Stable(actions)
+
+	return actions
+}
+
+func (s Actions) Len() int {
+	return len(s)
+}
+
+func (s Actions) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s Actions) Less(i, j int) bool {
+	if s[i].Timestamp != s[j].Timestamp {
+		return s[i].Timestamp < s[j].Timestamp
+	}
+
+	return s[i].Type < s[j].Type
+}
+
+// Legacy convert actions to legacy dividend and split, return false if they can not hold all actions
+// legacy holds at most one cash dividend and one split in order
+func (s Actions) Legacy() (*Dividend, *Split, bool) {
+	if !sort.IsSorted(s) {
+		return nil, nil, false
+	}
+
+	dividend, split := &Dividend{}, &Split{}
+	for _, action := range s {
+		switch {
+		case action.Type == ActionCashDividend && !dividend.Enable:
+			dividend = &Dividend{Enable: true, Timestamp: action.Timestamp, Amount: action.Amount}
+		case action.Type == ActionSplit && !split.Enable:
+			split = &Split{Enable: true, Timestamp: action.Timestamp, Numerator: action.Numerator, Denominator: action.Denominator}
+		default:
+			return nil, nil, false
+		}
+	}
+
+	return dividend, split, true
+}
+
+const (
+	// actionsMarker first byte of actions list encoding, legacy encoding starts with dividend enable flag 0 or 1
+	actionsMarker = 2
+
+	// maxActions upper bound of actions count of one company in one day, anything larger is corrupted
+	maxActions = 1 << 10
+)
+
+// Encode encode actions to io.Writer
+// actions legacy can hold are encoded as legacy dividend and split, so the same quote encodes to the same bytes as before,
+// others are encoded as actions marker followed by the actions list
+func (s Actions) Encode(w io.Writer) error {
+	dividend, split, ok := s.Legacy()
+	if ok {
+		err := dividend.Encode(w)
+		if err != nil {
+			zap.L().Error("encode dividend failed", zap.Error(err), zap.Any("dividend", dividend))
+			return err
+		}
+
+		err = split.Encode(w)
+		if err != nil {
+			zap.L().Error("encode split failed", zap.Error(err), zap.Any("split", split))
+			return err
+		}
+
+		return nil
+	}
+
+	bw := bio.NewBinaryWriter(w)
+
+	_, err := bw.UInt8(actionsMarker)
+	if err != nil {
+		zap.L().Error("encode actions marker failed", zap.Error(err))
+		return err
+	}
+
+	_, err = bw.Int(len(s))
+	if err != nil {
+		zap.L().Error("encode actions count failed", zap.Error(err), zap.Int("count", len(s)))
+		return err
+	}
+
+	for _, action := range s {
+		err = action.Encode(bw)
+		if err != nil {
+			zap.L().Error("encode action failed", zap.Error(err), zap.Any("action", action))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Decode decode actions from io.Reader, both legacy dividend and split and actions list are accepted
+func (s *Actions) Decode(r io.Reader) error {
+	br := bio.NewBinaryReader(r)
+
+	marker, err := br.UInt8()
+	if err != nil {
+		zap.L().Error("decode actions marker failed", zap.Error(err))
+		return err
+	}
+
+	if marker != actionsMarker {
+		// legacy encoding, marker is dividend enable flag
+		dividend := new(Dividend)
+		if marker > 0 {
+			err = dividend.decodeEnabled(br)
+			if err != nil {
+				zap.L().Error("decode dividend failed", zap.Error(err))
+				return err
+			}
+		}
+
+		split := new(Split)
+		err = split.Decode(br)
+		if err != nil {
+			zap.L().Error("decode split failed", zap.Error(err))
+			return err
+		}
+
+		*s = NewActions(dividend, split)
+		return nil
+	}
+
+	count, err := br.Int()
+	if err != nil {
+		zap.L().Error("decode actions count failed", zap.Error(err))
+		return err
+	}
+
+	if count < 0 || count > maxActions {
+		return fmt.Errorf("actions count invalid: %d", count)
+	}
+
+	actions := make(Actions, count)
+	for index := range actions {
+		err = actions[index].Decode(br)
+		if err != nil {
+			zap.L().Error("decode action failed", zap.Error(err), zap.Int("index", index))
+			return err
+		}
+	}
+
+	*s = actions
+
+	return nil
+}
+
+// Equal check actions are equal
+func (s Actions) Equal(t Actions) error {
+	if len(s) != len(t) {
+		return fmt.Errorf("actions count %d is different from %d", len(s), len(t))
+	}
+
+	for index, action := range s {
+		err := action.Equal(t[index])
+		if err != nil {
+			return fmt.Errorf("action %d is different due to %v", index, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package quotes
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/nzai/bio"
+)
+
+func TestActions_Decode(t *testing.T) {
+	// legacy encoding of dividend and split
+	buffer := new(bytes.Buffer)
+	dividend := Dividend{Enable: true, Timestamp: 1678113000, Amount: 0.23}
+	split := Split{Enable: true, Timestamp: 1678113000, Numerator: 4, Denominator: 1}
+
+	err := dividend.Encode(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = split.Encode(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := buffer.Bytes()
+
+	var actions Actions
+	err = actions.Decode(bytes.NewReader(legacy))
+	if err != nil {
+		t.Fatalf("Decode() legacy error = %v", err)
+	}
+
+	err = actions.Equal(Actions{dividend.Action(), split.Action()})
+	if err != nil {
+		t.Errorf("Decode() legacy not equal: %v", err)
+	}
+
+	// actions legacy can hold encode to the same bytes
+	buffer = new(bytes.Buffer)
+	err = actions.Encode(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buffer.Bytes(), legacy) {
+		t.Errorf("Encode() = %v, want legacy %v", buffer.Bytes(), legacy)
+	}
+
+	// several actions of the same day
+	actions = Actions{
+		{Type: ActionCashDividend, Timestamp: 1678113000, Amount: 0.1},
+		{Type: ActionCashDividend, Timestamp: 1678113000, Amount: 0.2},
+		{Type: ActionStockDividend, Timestamp: 1678113000, Numerator: 3, Denominator: 10},
+		{Type: ActionRightsIssue, Timestamp: 1678113000, Amount: 5, Numerator: 2, Denominator: 10},
+	}
+
+	buffer = new(bytes.Buffer)
+	err = actions.Encode(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Actions
+	err = decoded.Decode(buffer)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	err = decoded.Equal(actions)
+	if err != nil {
+		t.Errorf("Decode() not equal: %v", err)
+	}
+
+	// no action encodes as two disabled flags
+	buffer = new(bytes.Buffer)
+	err = Actions(nil).Encode(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buffer.Bytes(), []byte{0, 0}) {
+		t.Errorf("Encode() empty = %v, want [0 0]", buffer.Bytes())
+	}
+
+	// corrupted count is rejected before allocation
+	for _, count := range []int{-1, maxActions + 1, math.MaxInt32} {
+		buffer = new(bytes.Buffer)
+		bw := bio.NewBinaryWriter(buffer)
+		bw.UInt8(actionsMarker)
+		bw.Int(count)
+
+		err = decoded.Decode(buffer)
+		if err == nil {
+			t.Errorf("Decode() count %d error = nil, want error", count)
+		}
+	}
+}
+
+func TestNewAdjustEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions Actions
+		price   float64
+		volume  float64
+	}{
+		{
+			"cash and stock dividend",
+			Actions{
+				{Type: ActionCashDividend, Amount: 0.5},
+				{Type: ActionStockDividend, Numerator: 3, Denominator: 10},
+			},
+			(10 - 0.5) / 1.3 / 10,
+			1.3,
+		},
+		{
+			"rights issue",
+			Actions{{Type: ActionRightsIssue, Amount: 5, Numerator: 3, Denominator: 10}},
+			(10 + 5*0.3) / 1.3 / 10,
+			1.3,
+		},
+		{
+			"two dividends and split",
+			Actions{
+				{Type: ActionCashDividend, Amount: 0.5},
+				{Type: ActionCashDividend, Amount: 0.5},
+				{Type: ActionSplit, Numerator: 2, Denominator: 1},
+			},
+			(10 - 1) / 2.0 / 10,
+			2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := newAdjustEvent(1, tt.actions, 10, SplitRatio)
+			if math.Abs(event.Price-tt.price) > 1e-6 {
+				t.Errorf("newAdjustEvent() price = %f, want %f", event.Price, tt.price)
+			}
+
+			if math.Abs(event.Volume-tt.volume) > 1e-6 {
+				t.Errorf("newAdjustEvent() volume = %f, want %f", event.Volume, tt.volume)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package quotes
+
+import (
+	"fmt"
+	"math"
+)
+
+// AdjustMode define price adjustment direction
+type AdjustMode byte
+
+const (
+	// AdjustForward keep the latest prices, adjust earlier prices down to them
+	AdjustForward AdjustMode = iota + 1
+	// AdjustBackward keep the earliest prices, adjust later prices up to them
+	AdjustBackward
+)
+
+func (m AdjustMode) String() string {
+	switch m {
+	case AdjustForward:
+		return "forward"
+	case AdjustBackward:
+		return "backward"
+	default:
+		return fmt.Sprintf("adjust mode(%d)", byte(m))
+	}
+}
+
+// ParseAdjustMode parse adjust mode name
+func ParseAdjustMode(name string) (AdjustMode, error) {
+	for _, mode := range []AdjustMode{AdjustForward, AdjustBackward} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+
+	return 0, fmt.Errorf("adjust mode invalid: %s", name)
+}
+
+// SplitConvention define meaning of split numerator and denominator
+type SplitConvention byte
+
+const (
+	// SplitRatio numerator shares after split for every denominator shares before, like yahoo 4:1
+	SplitRatio SplitConvention = iota
+	// SplitBonus numerator bonus shares for every denominator shares held, like a-share 10 送 3 crawled as split before stock dividend action
+	SplitBonus
+)
+
+// Ratio get shares after split for every share before, return 1 if split is disabled or invalid
+func (s Split) Ratio(convention SplitConvention) float64 {
+	if !s.Enable {
+		return 1
+	}
+
+	return s.Action().Ratio(convention)
+}
+
+// AdjustEvent define corporate actions of a day and the price and volume multiplier they bring to the days before it
+//
+//	shares ratio = split ratio * (1 + stock dividend ratio + rights ratio)
+//	ex-rights price = (previous close - cash dividend + rights price * rights ratio) / shares ratio
+//	price multiplier = ex-rights price / previous close
+//	volume multiplier = shares ratio
+//
+// cash dividend and rights price are ignored if there is no previous close or ex-rights price is not positive
+type AdjustEvent struct {
+	Day           int     `json:"day"`
+	Timestamp     uint64  `json:"timestamp"`
+	Actions       Actions `json:"actions"`
+	PreviousClose float32 `json:"previous_close,omitempty"`
+	Price         float64 `json:"price"`
+	Volume        float64 `json:"volume"`
+}
+
+// AdjustFactor define cumulative price and volume factor of one day
+type AdjustFactor struct {
+	Price  float64 `json:"price"`
+	Volume float64 `json:"volume"`
+}
+
+// Adjustment define adjustment of company daily quotes
+// factors are one per day in the order of company daily quotes it was created from
+type Adjustment struct {
+	Mode    AdjustMode     `json:"mode"`
+	Events  []AdjustEvent  `json:"events"`
+	Factors []AdjustFactor `json:"factors"`
+}
+
+// NewAdjustment create adjustment from company daily quotes ordered by date
+// corporate actions of a day take effect before the day opens, so they only change the days before it
+func NewAdjustment(cdqs []*CompanyDailyQuote, mode AdjustMode, convention SplitConvention) *Adjustment {
+	adjustment := &Adjustment{
+		Mode:    mode,
+		Factors: make([]AdjustFactor, len(cdqs)),
+	}
+
+	var previousClose float32
+	for day, cdq := range cdqs {
+		if len(cdq.Actions) > 0 {
+			event := newAdjustEvent(day, cdq.Actions, previousClose, convention)
+			if event.Price != 1 || event.Volume != 1 {
+				adjustment.Events = append(adjustment.Events, event)
+			}
+		}
+
+		if cdq.Regular != nil && len(*cdq.Regular) > 0 {
+			previousClose = (*cdq.Regular)[len(*cdq.Regular)-1].Close
+		}
+	}
+
+	// forward factor of a day is the product of multipliers of the events after it
+	price, volume := 1.0, 1.0
+	next := len(adjustment.Events) - 1
+	for day := len(cdqs) - 1; day >= 0; day-- {
+		adjustment.Factors[day] = AdjustFactor{Price: price, Volume: volume}
+
+		for next >= 0 && adjustment.Events[next].Day == day {
+			price *= adjustment.Events[next].Price
+			volume *= adjustment.Events[next].Volume
+			next--
+		}
+	}
+
+	// backward factor is forward factor relative to the first day
+	if mode == AdjustBackward && len(cdqs) > 0 {
+		first := adjustment.Factors[0]
+		for day := range adjustment.Factors {
+			adjustment.Factors[day].Price /= first.Price
+			adjustment.Factors[day].Volume /= first.Volume
+		}
+	}
+
+	return adjustment
+}
+
+// newAdjustEvent create adjust event from corporate actions of a day
+func newAdjustEvent(day int, actions Actions, previousClose float32, convention SplitConvention) AdjustEvent {
+	split, bonus, cash, rights := 1.0, 0.0, 0.0, 0.0
+	for _, action := range actions {
+		switch action.Type {
+		case ActionCashDividend:
+			cash += float64(action.Amount)
+		case ActionStockDividend:
+			bonus += action.Ratio(convention) - 1
+		case ActionSplit:
+			split *= action.Ratio(convention)
+		case ActionRightsIssue:
+			ratio := action.Ratio(convention) - 1
+			bonus += ratio
+			rights += float64(action.Amount) * ratio
+		}
+	}
+
+	shares := split * (1 + bonus)
+	event := AdjustEvent{
+		Day:           day,
+		Timestamp:     actions[0].Timestamp,
+		Actions:       actions,
+		PreviousClose: previousClose,
+		Price:         1 / shares,
+		Volume:        shares,
+	}
+
+	if previousClose > 0 {
+		exRights := (float64(previousClose) - cash + rights) / shares
+		if exRights > 0 {
+			event.Price = exRights / float64(previousClose)
+		}
+	}
+
+	return event
+}
+
+// Apply return adjusted copies of company daily quotes, cdqs must be the quotes adjustment was created from
+// corporate actions are kept as they are
+func (a Adjustment) Apply(cdqs []*CompanyDailyQuote) []*CompanyDailyQuote {
+	adjusted := make([]*CompanyDailyQuote, len(cdqs))
+	for day, cdq := range cdqs {
+		factor := AdjustFactor{Price: 1, Volume: 1}
+		if day < len(a.Factors) {
+			factor = a.Factors[day]
+		}
+
+		adjusted[day] = &CompanyDailyQuote{
+			Company: cdq.Company,
+			Actions: cdq.Actions,
+			Pre:     factor.adjustSerial(cdq.Pre),
+			Regular: factor.adjustSerial(cdq.Regular),
+			Post:    factor.adjustSerial(cdq.Post),
+			Meta:    cdq.Meta,
+		}
+	}
+
+	return adjusted
+}
+
+// Adjust return adjusted copy of serial
+func (f AdjustFactor) Adjust(serial Serial) Serial {
+	adjusted := make(Serial, len(serial))
+	for index, quote := range serial {
+		adjusted[index] = Quote{
+			Timestamp: quote.Timestamp,
+			Open:      float32(float64(quote.Open) * f.Price),
+			Close:     float32(float64(quote.Close) * f.Price),
+			High:      float32(float64(quote.High) * f.Price),
+			Low:       float32(float64(quote.Low) * f.Price),
+			Volume:    uint64(math.Round(float64(quote.Volume) * f.Volume)),
+		}
+	}
+
+	return adjusted
+}
+
+// adjustSerial adjust serial, nil serial stays nil
+func (f AdjustFactor) adjustSerial(serial *Serial) *Serial {
+	if serial == nil {
+		return nil
+	}
+
+	adjusted := f.Adjust(*serial)
+	return &adjusted
+}
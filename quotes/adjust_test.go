@@ -0,0 +1,96 @@
+package quotes
+
+import (
+	"math"
+	"testing"
+)
+
+// testAdjustQuotes five days with two dividends and two splits, the closes are continuous after adjustment
+//
+//	day 0 close 100
+//	day 1 dividend 2, close 98
+//	day 2 split 2:1, close 49
+//	day 3 dividend 1, close 48
+//	day 4 split 3:1, close 16
+func testAdjustQuotes() []*CompanyDailyQuote {
+	closes := []float32{100, 98, 49, 48, 16}
+	cdqs := make([]*CompanyDailyQuote, len(closes))
+	for day, price := range closes {
+		timestamp := uint64(1678113000 + day*86400)
+		cdqs[day] = &CompanyDailyQuote{
+			Company: &Company{Code: "AAPL", Name: "Apple Inc."},
+			Pre:     &Serial{},
+			Regular: &Serial{{Timestamp: timestamp, Open: price, Close: price, High: price, Low: price, Volume: 600}},
+			Post:    &Serial{},
+		}
+	}
+
+	cdqs[1].Actions = Actions{{Type: ActionCashDividend, Timestamp: 1678113000 + 86400, Amount: 2}}
+	cdqs[2].Actions = Actions{{Type: ActionSplit, Timestamp: 1678113000 + 86400*2, Numerator: 2, Denominator: 1}}
+	cdqs[3].Actions = Actions{{Type: ActionCashDividend, Timestamp: 1678113000 + 86400*3, Amount: 1}}
+	cdqs[4].Actions = Actions{{Type: ActionSplit, Timestamp: 1678113000 + 86400*4, Numerator: 3, Denominator: 1}}
+
+	return cdqs
+}
+
+func TestNewAdjustment(t *testing.T) {
+	cdqs := testAdjustQuotes()
+
+	tests := []struct {
+		mode    AdjustMode
+		close   float64
+		volumes []uint64
+	}{
+		{AdjustForward, 16, []uint64{3600, 3600, 1800, 1800, 600}},
+		{AdjustBackward, 100, []uint64{600, 600, 300, 300, 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			adjustment := NewAdjustment(cdqs, tt.mode, SplitRatio)
+			if len(adjustment.Events) != 4 {
+				t.Fatalf("NewAdjustment() events = %d, want 4", len(adjustment.Events))
+			}
+
+			for day, cdq := range adjustment.Apply(cdqs) {
+				quote := (*cdq.Regular)[0]
+				if math.Abs(float64(quote.Close)-tt.close) > 1e-3 {
+					t.Errorf("day %d adjusted close = %f, want %f", day, quote.Close, tt.close)
+				}
+
+				if quote.Volume != tt.volumes[day] {
+					t.Errorf("day %d adjusted volume = %d, want %d", day, quote.Volume, tt.volumes[day])
+				}
+
+				if quote.Timestamp != (*cdqs[day].Regular)[0].Timestamp {
+					t.Errorf("day %d timestamp changed", day)
+				}
+			}
+
+			// raw quotes are not changed
+			if (*cdqs[0].Regular)[0].Close != 100 {
+				t.Errorf("Apply() changed raw quotes")
+			}
+		})
+	}
+}
+
+func TestSplit_Ratio(t *testing.T) {
+	tests := []struct {
+		split      Split
+		convention SplitConvention
+		want       float64
+	}{
+		{Split{Enable: true, Numerator: 4, Denominator: 1}, SplitRatio, 4},
+		{Split{Enable: true, Numerator: 1, Denominator: 10}, SplitRatio, 0.1},
+		{Split{Enable: true, Numerator: 3, Denominator: 10}, SplitBonus, 1.3},
+		{Split{Enable: false, Numerator: 4, Denominator: 1}, SplitRatio, 1},
+	}
+
+	for _, tt := range tests {
+		got := tt.split.Ratio(tt.convention)
+		if math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("Split.Ratio(%+v) = %f, want %f", tt.split, got, tt.want)
+		}
+	}
+}
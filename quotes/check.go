@@ -0,0 +1,129 @@
+package quotes
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Violation define broken structural invariant of exchange daily quote
+type Violation struct {
+	Company string `json:"company,omitempty"`
+	Serial  string `json:"serial,omitempty"`
+	Index   int    `json:"index"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s %s[%d] %s: %s", v.Company, v.Serial, v.Index, v.Rule, v.Message)
+}
+
+// Check check structural invariants, return every violation found
+//
+//	every quote belongs to a company in companies map, and keyed by its company code
+//	timestamps are strictly increasing and within the day
+//	pre serial ends before regular serial, regular serial ends before post serial
+//	every quote is inside the session of its serial type, not checked if window is nil
+//	prices are finite and non-negative, high is not lower than low, volumes are not wrapped negative numbers
+func (q ExchangeDailyQuote) Check(window SessionWindow) []Violation {
+	var violations []Violation
+
+	start := uint64(q.Date.Unix())
+	end := uint64(q.Date.AddDate(0, 0, 1).Unix())
+	for code, cdq := range q.Quotes {
+		if cdq.Company == nil {
+			violations = append(violations, Violation{Company: code, Index: -1, Rule: "company_missing", Message: "quote has no company"})
+			continue
+		}
+
+		if cdq.Company.Code != code {
+			violations = append(violations, Violation{Company: code, Index: -1, Rule: "company_mismatch",
+				Message: fmt.Sprintf("quote keyed by %s belongs to %s", code, cdq.Company.Code)})
+		}
+
+		if _, found := q.Companies[code]; !found {
+			violations = append(violations, Violation{Company: code, Index: -1, Rule: "company_unknown", Message: "company not in companies"})
+		}
+
+		var previous *Serial
+		var previousType SerialType
+		for _, serialType := range []SerialType{SerialTypePre, SerialTypeRegular, SerialTypePost} {
+			serial := cdq.serial(serialType)
+			if serial == nil {
+				continue
+			}
+
+			for index, quote := range *serial {
+				violations = append(violations, quote.check(code, serialType, index, start, end)...)
+
+				if window != nil && !window(serialType, time.Unix(int64(quote.Timestamp), 0)) {
+					violations = append(violations, Violation{Company: code, Serial: serialType.String(), Index: index, Rule: "out_of_session",
+						Message: fmt.Sprintf("timestamp %d is out of %s session", quote.Timestamp, serialType)})
+				}
+
+				if index > 0 && quote.Timestamp <= (*serial)[index-1].Timestamp {
+					violations = append(violations, Violation{Company: code, Serial: serialType.String(), Index: index, Rule: "timestamp_unsorted",
+						Message: fmt.Sprintf("timestamp %d is not after %d", quote.Timestamp, (*serial)[index-1].Timestamp)})
+				}
+			}
+
+			if len(*serial) == 0 {
+				continue
+			}
+
+			if previous != nil && (*serial)[0].Timestamp <= (*previous)[len(*previous)-1].Timestamp {
+				violations = append(violations, Violation{Company: code, Serial: serialType.String(), Index: 0, Rule: "session_overlap",
+					Message: fmt.Sprintf("starts at %d, not after %s ends at %d", (*serial)[0].Timestamp, previousType, (*previous)[len(*previous)-1].Timestamp)})
+			}
+
+			previous, previousType = serial, serialType
+		}
+	}
+
+	return violations
+}
+
+// serial get serial by type
+func (q CompanyDailyQuote) serial(serialType SerialType) *Serial {
+	switch serialType {
+	case SerialTypePre:
+		return q.Pre
+	case SerialTypeRegular:
+		return q.Regular
+	case SerialTypePost:
+		return q.Post
+	default:
+		return nil
+	}
+}
+
+// check check invariants of single quote
+func (q Quote) check(code string, serialType SerialType, index int, start, end uint64) []Violation {
+	var violations []Violation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{Company: code, Serial: serialType.String(), Index: index, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if q.Timestamp < start || q.Timestamp >= end {
+		add("timestamp_out_of_day", "timestamp %d is out of [%d, %d)", q.Timestamp, start, end)
+	}
+
+	for _, price := range []float32{q.Open, q.Close, q.High, q.Low} {
+		value := float64(price)
+		if math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+			add("price_invalid", "open %v close %v high %v low %v", q.Open, q.Close, q.High, q.Low)
+			break
+		}
+	}
+
+	if q.High < q.Low {
+		add("high_below_low", "high %v is lower than low %v", q.High, q.Low)
+	}
+
+	if q.Volume > math.MaxInt64 {
+		add("volume_negative", "volume %d is a wrapped negative number", q.Volume)
+	}
+
+	return violations
+}
@@ -0,0 +1,72 @@
+package quotes
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExchangeDailyQuote_Check(t *testing.T) {
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, time.UTC)
+	open := uint64(date.Add(time.Hour * 14).Unix())
+
+	company := &Company{Code: "AAPL", Name: "Apple Inc."}
+	edq := ExchangeDailyQuote{
+		Exchange:  "Nasdaq",
+		Date:      date,
+		Companies: map[string]*Company{"AAPL": company},
+		Quotes: map[string]*CompanyDailyQuote{
+			"AAPL": {
+				Company: company,
+				Pre:     &Serial{{Timestamp: open - 60, Open: 1, Close: 1, High: 1, Low: 1, Volume: 1}},
+				Regular: &Serial{{Timestamp: open, Open: 1, Close: 2, High: 2, Low: 1, Volume: 10}, {Timestamp: open + 60, Open: 2, Close: 2, High: 2, Low: 2}},
+				Post:    &Serial{},
+			},
+		},
+	}
+
+	violations := edq.Check(nil)
+	if len(violations) != 0 {
+		t.Fatalf("Check() = %v, want no violation", violations)
+	}
+
+	broken := &CompanyDailyQuote{
+		Company: &Company{Code: "MSFT"},
+		Pre:     &Serial{{Timestamp: open + 90, Open: 1, Close: 1, High: 1, Low: 1}},
+		Regular: &Serial{
+			{Timestamp: open + 60, Open: float32(math.NaN()), Close: 1, High: 1, Low: 1},
+			{Timestamp: open, Open: 1, Close: 1, High: 1, Low: 2, Volume: math.MaxUint64},
+		},
+		Post: &Serial{{Timestamp: uint64(date.AddDate(0, 0, 1).Unix()), Open: 1, Close: 1, High: 1, Low: 1}},
+	}
+	edq.Quotes["MSFT"] = broken
+
+	rules := make(map[string]int)
+	for _, violation := range edq.Check(nil) {
+		rules[violation.Rule]++
+	}
+
+	for _, rule := range []string{"company_unknown", "price_invalid", "timestamp_unsorted", "high_below_low", "volume_negative", "session_overlap", "timestamp_out_of_day"} {
+		if rules[rule] == 0 {
+			t.Errorf("Check() missing %s violation, got %v", rule, rules)
+		}
+	}
+
+	// regular session is [open, open + 1 hour), pre and post sessions are out of it
+	window := func(serialType SerialType, t time.Time) bool {
+		inRegular := !t.Before(time.Unix(int64(open), 0)) && t.Before(time.Unix(int64(open), 0).Add(time.Hour))
+		return inRegular == (serialType == SerialTypeRegular)
+	}
+
+	delete(edq.Quotes, "MSFT")
+	violations = edq.Check(window)
+	if len(violations) != 0 {
+		t.Fatalf("Check() with session window = %v, want no violation", violations)
+	}
+
+	edq.Quotes["AAPL"].Post = &Serial{{Timestamp: open + 120, Open: 2, Close: 2, High: 2, Low: 2}}
+	violations = edq.Check(window)
+	if len(violations) != 1 || violations[0].Rule != "out_of_session" || violations[0].Serial != SerialTypePost.String() {
+		t.Errorf("Check() with session window = %v, want post out_of_session violation", violations)
+	}
+}
@@ -0,0 +1,331 @@
+package quotes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+
+	"github.com/nzai/bio"
+	"go.uber.org/zap"
+)
+
+// SerialCodec define how quote serial is encoded
+type SerialCodec byte
+
+const (
+	// SerialCodecRow encode quotes row by row, 8+4*4+8 bytes per quote
+	SerialCodecRow SerialCodec = iota
+	// SerialCodecColumnar encode quotes column by column,
+	// delta-of-delta timestamps, xor compressed prices and varint volumes
+	SerialCodecColumnar
+)
+
+func (c SerialCodec) String() string {
+	switch c {
+	case SerialCodecRow:
+		return "row"
+	case SerialCodecColumnar:
+		return "columnar"
+	default:
+		return fmt.Sprintf("serial codec(%d)", byte(c))
+	}
+}
+
+// ParseSerialCodec parse serial codec name
+func ParseSerialCodec(name string) (SerialCodec, error) {
+	for _, codec := range []SerialCodec{SerialCodecRow, SerialCodecColumnar} {
+		if codec.String() == name {
+			return codec, nil
+		}
+	}
+
+	return SerialCodecRow, fmt.Errorf("serial codec invalid: %s", name)
+}
+
+// EncodeCodec encode quotes to io.Writer by codec
+func (s Serial) EncodeCodec(w io.Writer, codec SerialCodec) error {
+	switch codec {
+	case SerialCodecRow:
+		return s.Encode(w)
+	case SerialCodecColumnar:
+		return s.encodeColumnar(w)
+	default:
+		return fmt.Errorf("serial codec unsupported: %s", codec)
+	}
+}
+
+// DecodeCodec decode quotes from io.Reader by codec
+func (s *Serial) DecodeCodec(r io.Reader, codec SerialCodec) error {
+	switch codec {
+	case SerialCodecRow:
+		return s.Decode(r)
+	case SerialCodecColumnar:
+		return s.decodeColumnar(r)
+	default:
+		return fmt.Errorf("serial codec unsupported: %s", codec)
+	}
+}
+
+// encodeColumnar encode quotes as a length prefixed columnar block
+// block: count | timestamps | volumes | open, close, high, low bit stream
+func (s Serial) encodeColumnar(w io.Writer) error {
+	buffer := binary.AppendUvarint(nil, uint64(len(s)))
+
+	if len(s) > 0 {
+		// delta-of-delta timestamps
+		buffer = binary.AppendUvarint(buffer, s[0].Timestamp)
+		var delta int64
+		for index := 1; index < len(s); index++ {
+			current := int64(s[index].Timestamp - s[index-1].Timestamp)
+			buffer = binary.AppendVarint(buffer, current-delta)
+			delta = current
+		}
+
+		for _, quote := range s {
+			buffer = binary.AppendUvarint(buffer, quote.Volume)
+		}
+
+		bw := new(bitWriter)
+		for _, column := range []func(Quote) float32{
+			func(q Quote) float32 { return q.Open },
+			func(q Quote) float32 { return q.Close },
+			func(q Quote) float32 { return q.High },
+			func(q Quote) float32 { return q.Low },
+		} {
+			bw.writeFloats(s, column)
+		}
+
+		buffer = append(buffer, bw.buffer...)
+	}
+
+	bw := bio.NewBinaryWriter(w)
+	_, err := bw.Int(len(buffer))
+	if err != nil {
+		zap.L().Error("encode columnar block length failed", zap.Error(err), zap.Int("length", len(buffer)))
+		return err
+	}
+
+	_, err = w.Write(buffer)
+	if err != nil {
+		zap.L().Error("encode columnar block failed", zap.Error(err), zap.Int("count", len(s)))
+		return err
+	}
+
+	return nil
+}
+
+// decodeColumnar decode quotes from a length prefixed columnar block
+func (s *Serial) decodeColumnar(r io.Reader) error {
+	br := bio.NewBinaryReader(r)
+
+	length, err := br.Int()
+	if err != nil {
+		zap.L().Error("decode columnar block length failed", zap.Error(err))
+		return err
+	}
+
+	buffer, err := br.Bytes(length)
+	if err != nil {
+		zap.L().Error("decode columnar block failed", zap.Error(err), zap.Int("length", length))
+		return err
+	}
+
+	count, n := binary.Uvarint(buffer)
+	if n <= 0 || count > uint64(len(buffer)) {
+		return fmt.Errorf("columnar serial count invalid")
+	}
+	buffer = buffer[n:]
+
+	serial := make([]Quote, count)
+	if count > 0 {
+		var delta int64
+		for index := range serial {
+			if index == 0 {
+				serial[0].Timestamp, n = binary.Uvarint(buffer)
+			} else {
+				var dod int64
+				dod, n = binary.Varint(buffer)
+				delta += dod
+				serial[index].Timestamp = serial[index-1].Timestamp + uint64(delta)
+			}
+
+			if n <= 0 {
+				return fmt.Errorf("columnar serial timestamp %d invalid", index)
+			}
+			buffer = buffer[n:]
+		}
+
+		for index := range serial {
+			serial[index].Volume, n = binary.Uvarint(buffer)
+			if n <= 0 {
+				return fmt.Errorf("columnar serial volume %d invalid", index)
+			}
+			buffer = buffer[n:]
+		}
+
+		bitReader := &bitReader{buffer: buffer}
+		for _, column := range []func(*Quote, float32){
+			func(q *Quote, value float32) { q.Open = value },
+			func(q *Quote, value float32) { q.Close = value },
+			func(q *Quote, value float32) { q.High = value },
+			func(q *Quote, value float32) { q.Low = value },
+		} {
+			err = bitReader.readFloats(serial, column)
+			if err != nil {
+				zap.L().Error("decode columnar prices failed", zap.Error(err))
+				return err
+			}
+		}
+	}
+
+	*s = serial
+
+	return nil
+}
+
+// bitWriter append bits to byte buffer, most significant bit first
+type bitWriter struct {
+	buffer []byte
+	free   uint8 // free bits in last byte
+}
+
+func (w *bitWriter) writeBit(bit bool) {
+	if w.free == 0 {
+		w.buffer = append(w.buffer, 0)
+		w.free = 8
+	}
+
+	w.free--
+	if bit {
+		w.buffer[len(w.buffer)-1] |= 1 << w.free
+	}
+}
+
+func (w *bitWriter) writeBits(value uint64, count int) {
+	for index := count - 1; index >= 0; index-- {
+		w.writeBit(value&(1<<index) != 0)
+	}
+}
+
+// writeFloats write float column with gorilla xor compression
+func (w *bitWriter) writeFloats(serial Serial, column func(Quote) float32) {
+	previous := math.Float32bits(column(serial[0]))
+	w.writeBits(uint64(previous), 32)
+
+	// leading and trailing zeros of current window, no window yet
+	leading, trailing := -1, 0
+	for _, quote := range serial[1:] {
+		current := math.Float32bits(column(quote))
+		xor := current ^ previous
+		previous = current
+
+		if xor == 0 {
+			w.writeBit(false)
+			continue
+		}
+		w.writeBit(true)
+
+		currentLeading, currentTrailing := bits.LeadingZeros32(xor), bits.TrailingZeros32(xor)
+		if leading >= 0 && currentLeading >= leading && currentTrailing >= trailing {
+			// meaningful bits fit in previous window
+			w.writeBit(false)
+			w.writeBits(uint64(xor>>trailing), 32-leading-trailing)
+			continue
+		}
+
+		leading, trailing = currentLeading, currentTrailing
+		significant := 32 - leading - trailing
+
+		w.writeBit(true)
+		w.writeBits(uint64(leading), 5)
+		w.writeBits(uint64(significant-1), 5)
+		w.writeBits(uint64(xor>>trailing), significant)
+	}
+}
+
+// bitReader read bits from byte buffer, most significant bit first
+type bitReader struct {
+	buffer []byte
+	offset int // offset in bits
+}
+
+func (r *bitReader) readBit() (bool, error) {
+	if r.offset >= len(r.buffer)*8 {
+		return false, io.ErrUnexpectedEOF
+	}
+
+	bit := r.buffer[r.offset/8]&(1<<(7-r.offset%8)) != 0
+	r.offset++
+
+	return bit, nil
+}
+
+func (r *bitReader) readBits(count int) (uint64, error) {
+	var value uint64
+	for index := 0; index < count; index++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+
+		value <<= 1
+		if bit {
+			value |= 1
+		}
+	}
+
+	return value, nil
+}
+
+// readFloats read float column with gorilla xor compression
+func (r *bitReader) readFloats(serial Serial, column func(*Quote, float32)) error {
+	value, err := r.readBits(32)
+	if err != nil {
+		return err
+	}
+
+	previous := uint32(value)
+	column(&serial[0], math.Float32frombits(previous))
+
+	leading, trailing := 0, 0
+	for index := 1; index < len(serial); index++ {
+		changed, err := r.readBit()
+		if err != nil {
+			return err
+		}
+
+		if changed {
+			newWindow, err := r.readBit()
+			if err != nil {
+				return err
+			}
+
+			if newWindow {
+				value, err = r.readBits(5)
+				if err != nil {
+					return err
+				}
+				leading = int(value)
+
+				value, err = r.readBits(5)
+				if err != nil {
+					return err
+				}
+				trailing = 32 - leading - int(value) - 1
+			}
+
+			value, err = r.readBits(32 - leading - trailing)
+			if err != nil {
+				return err
+			}
+
+			previous ^= uint32(value) << trailing
+		}
+
+		column(&serial[index], math.Float32frombits(previous))
+	}
+
+	return nil
+}
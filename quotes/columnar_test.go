@@ -0,0 +1,48 @@
+package quotes
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestSerial_Columnar(t *testing.T) {
+	tests := []struct {
+		name   string
+		serial Serial
+	}{
+		{"empty", Serial{}},
+		{"single", Serial{{Timestamp: 1678109400, Open: 10.5, Close: 10.6, High: 10.7, Low: 10.4, Volume: 100}}},
+		{"irregular", Serial{
+			{Timestamp: 1678109400, Open: 10.5, Close: 10.6, High: 10.7, Low: 10.4, Volume: 100},
+			{Timestamp: 1678109460, Open: 10.6, Close: 10.6, High: 10.6, Low: 10.6, Volume: 0},
+			{Timestamp: 1678109700, Open: 0, Close: -1.25, High: math.MaxFloat32, Low: float32(math.Inf(-1)), Volume: math.MaxUint64},
+			{Timestamp: 1678109640, Open: 3.14159, Close: 2.71828, High: 1e-7, Low: 1e7, Volume: 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			err := tt.serial.EncodeCodec(buffer, SerialCodecColumnar)
+			if err != nil {
+				t.Fatalf("EncodeCodec() error = %v", err)
+			}
+
+			decoded := new(Serial)
+			err = decoded.DecodeCodec(buffer, SerialCodecColumnar)
+			if err != nil {
+				t.Fatalf("DecodeCodec() error = %v", err)
+			}
+
+			err = tt.serial.Equal(*decoded)
+			if err != nil {
+				t.Errorf("DecodeCodec() not equal: %v", err)
+			}
+
+			if buffer.Len() != 0 {
+				t.Errorf("DecodeCodec() left %d bytes unread", buffer.Len())
+			}
+		})
+	}
+}
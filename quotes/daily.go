@@ -3,6 +3,7 @@ package quotes
 import (
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/nzai/bio"
@@ -10,116 +11,62 @@ import (
 )
 
 // ExchangeDailyQuote define exchange daily quote
+// meta is crawl provenance, nil if unknown, it is not part of the encoding and not compared by Equal
 type ExchangeDailyQuote struct {
 	Exchange  string
 	Date      time.Time
 	Companies map[string]*Company
 	Quotes    map[string]*CompanyDailyQuote
+	Meta      *Meta
 }
 
 // Encode encode exchange daily quote to io.Writer
 func (q ExchangeDailyQuote) Encode(w io.Writer) error {
-	bw := bio.NewBinaryWriter(w)
-
-	_, err := bw.String(q.Exchange)
-	if err != nil {
-		zap.L().Error("encode exchange code failed", zap.Error(err), zap.String("exchange", q.Exchange))
-		return err
-	}
-
-	_, err = bw.Time(q.Date)
-	if err != nil {
-		zap.L().Error("encode date failed", zap.Error(err), zap.Time("date", q.Date))
-		return err
-	}
+	return q.EncodeCodec(w, SerialCodecRow)
+}
 
-	_, err = bw.Int(len(q.Companies))
+// EncodeCodec encode exchange daily quote to io.Writer, quote serials encoded by codec
+// companies and quotes are encoded order by code, so the same quote always encodes to the same bytes
+func (q ExchangeDailyQuote) EncodeCodec(w io.Writer, codec SerialCodec) error {
+	ew, err := NewExchangeDailyQuoteWriter(w, codec, q.Exchange, q.Date, q.Companies, len(q.Quotes))
 	if err != nil {
-		zap.L().Error("encode company count failed", zap.Error(err), zap.Int("count", len(q.Companies)))
 		return err
 	}
 
-	for _, company := range q.Companies {
-		err = company.Encode(bw)
-		if err != nil {
-			zap.L().Error("encode companye failed", zap.Error(err), zap.Any("company", company))
-			return err
-		}
-	}
-
-	_, err = bw.Int(len(q.Quotes))
-	if err != nil {
-		zap.L().Error("encode quotes count failed", zap.Error(err), zap.Int("count", len(q.Quotes)))
-		return err
+	codes := make([]string, 0, len(q.Quotes))
+	for code := range q.Quotes {
+		codes = append(codes, code)
 	}
+	sort.Strings(codes)
 
-	for companyCode, dailyQuote := range q.Quotes {
-		err = dailyQuote.Encode(bw)
+	for _, code := range codes {
+		err = ew.WriteCompanyQuote(q.Quotes[code])
 		if err != nil {
-			zap.L().Error("encode daily quote failed", zap.Error(err), zap.Any("company", companyCode))
 			return err
 		}
 	}
 
-	return nil
+	return ew.Close()
 }
 
 // Decode decode exchange daily quote from io.Reader
 func (q *ExchangeDailyQuote) Decode(r io.Reader) error {
-	br := bio.NewBinaryReader(r)
-
-	exchange, err := br.String()
-	if err != nil {
-		zap.L().Error("decode exchange failed", zap.Error(err))
-		return err
-	}
-
-	date, err := br.Time()
-	if err != nil {
-		zap.L().Error("decode date failed", zap.Error(err))
-		return err
-	}
+	return q.DecodeCodec(r, SerialCodecRow)
+}
 
-	count, err := br.Int()
+// DecodeCodec decode exchange daily quote from io.Reader, quote serials decoded by codec
+func (q *ExchangeDailyQuote) DecodeCodec(r io.Reader, codec SerialCodec) error {
+	er, err := NewExchangeDailyQuoteReader(r, codec)
 	if err != nil {
-		zap.L().Error("decode company count failed", zap.Error(err))
 		return err
 	}
 
-	companies := make(map[string]*Company, count)
-	for index := 0; index < count; index++ {
-		company := new(Company)
-		err = company.Decode(br)
-		if err != nil {
-			zap.L().Error("decode company failed", zap.Error(err))
-			return err
-		}
-
-		companies[company.Code] = company
-	}
-
-	count, err = br.Int()
+	edq, err := er.ReadAll()
 	if err != nil {
-		zap.L().Error("decode quotes count failed", zap.Error(err))
 		return err
 	}
 
-	cdqs := make(map[string]*CompanyDailyQuote, count)
-	for index := 0; index < count; index++ {
-		cdq := new(CompanyDailyQuote)
-		err = cdq.Decode(br)
-		if err != nil {
-			zap.L().Error("decode daily quote failed", zap.Error(err))
-			return err
-		}
-
-		cdqs[cdq.Company.Code] = cdq
-	}
-
-	q.Exchange = exchange
-	q.Date = date
-	q.Companies = companies
-	q.Quotes = cdqs
+	*q = *edq
 
 	return nil
 }
@@ -176,17 +123,24 @@ func (q ExchangeDailyQuote) IsEmpty() bool {
 }
 
 // CompanyDailyQuote define company daily quote
+// actions are corporate actions of the day ordered by timestamp and type
+// meta is set by crawler and collected into exchange daily quote meta, it is not encoded
 type CompanyDailyQuote struct {
-	Company  *Company
-	Dividend *Dividend
-	Split    *Split
-	Pre      *Serial
-	Regular  *Serial
-	Post     *Serial
+	Company *Company
+	Actions Actions
+	Pre     *Serial
+	Regular *Serial
+	Post    *Serial
+	Meta    *CompanyMeta
 }
 
 // Encode encode company daily quote to io.Writer
 func (q CompanyDailyQuote) Encode(w io.Writer) error {
+	return q.EncodeCodec(w, SerialCodecRow)
+}
+
+// EncodeCodec encode company daily quote to io.Writer, quote serials encoded by codec
+func (q CompanyDailyQuote) EncodeCodec(w io.Writer, codec SerialCodec) error {
 	bw := bio.NewBinaryWriter(w)
 
 	err := q.Company.Encode(bw)
@@ -195,38 +149,28 @@ func (q CompanyDailyQuote) Encode(w io.Writer) error {
 		return err
 	}
 
-	if q.Dividend != nil {
-		err = q.Dividend.Encode(bw)
-		if err != nil {
-			zap.L().Error("encode dividend failed", zap.Error(err), zap.Any("dividend", q.Dividend))
-			return err
-		}
-	}
-
-	if q.Split != nil {
-		err = q.Split.Encode(bw)
-		if err != nil {
-			zap.L().Error("encode split failed", zap.Error(err), zap.Any("split", q.Split))
-			return err
-		}
+	err = q.Actions.Encode(bw)
+	if err != nil {
+		zap.L().Error("encode actions failed", zap.Error(err), zap.Any("actions", q.Actions))
+		return err
 	}
 
 	if q.Pre != nil {
-		err = q.Pre.Encode(bw)
+		err = q.Pre.EncodeCodec(bw, codec)
 		if err != nil {
 			zap.L().Error("encode pre serial failed", zap.Error(err), zap.Int("count", len(*q.Pre)))
 			return err
 		}
 	}
 
-	err = q.Regular.Encode(bw)
+	err = q.Regular.EncodeCodec(bw, codec)
 	if err != nil {
 		zap.L().Error("encode regular serial failed", zap.Error(err), zap.Int("count", len(*q.Regular)))
 		return err
 	}
 
 	if q.Post != nil {
-		err = q.Post.Encode(bw)
+		err = q.Post.EncodeCodec(bw, codec)
 		if err != nil {
 			zap.L().Error("encode post serial failed", zap.Error(err), zap.Int("count", len(*q.Post)))
 			return err
@@ -238,6 +182,11 @@ func (q CompanyDailyQuote) Encode(w io.Writer) error {
 
 // Decode decode company daily quote from io.Reader
 func (q *CompanyDailyQuote) Decode(r io.Reader) error {
+	return q.DecodeCodec(r, SerialCodecRow)
+}
+
+// DecodeCodec decode company daily quote from io.Reader, quote serials decoded by codec
+func (q *CompanyDailyQuote) DecodeCodec(r io.Reader, codec SerialCodec) error {
 	br := bio.NewBinaryReader(r)
 
 	company := new(Company)
@@ -247,44 +196,36 @@ func (q *CompanyDailyQuote) Decode(r io.Reader) error {
 		return err
 	}
 
-	dividend := new(Dividend)
-	err = dividend.Decode(br)
+	var actions Actions
+	err = actions.Decode(br)
 	if err != nil {
-		zap.L().Error("decode dividend failed", zap.Error(err))
-		return err
-	}
-
-	split := new(Split)
-	err = split.Decode(br)
-	if err != nil {
-		zap.L().Error("decode split failed", zap.Error(err))
+		zap.L().Error("decode actions failed", zap.Error(err))
 		return err
 	}
 
 	pre := new(Serial)
-	err = pre.Decode(br)
+	err = pre.DecodeCodec(br, codec)
 	if err != nil {
 		zap.L().Error("decode pre serial failed", zap.Error(err))
 		return err
 	}
 
 	regular := new(Serial)
-	err = regular.Decode(br)
+	err = regular.DecodeCodec(br, codec)
 	if err != nil {
 		zap.L().Error("decode regular serial failed", zap.Error(err))
 		return err
 	}
 
 	post := new(Serial)
-	err = post.Decode(br)
+	err = post.DecodeCodec(br, codec)
 	if err != nil {
 		zap.L().Error("decode post serial failed", zap.Error(err))
 		return err
 	}
 
 	q.Company = company
-	q.Dividend = dividend
-	q.Split = split
+	q.Actions = actions
 	q.Pre = pre
 	q.Regular = regular
 	q.Post = post
@@ -299,14 +240,9 @@ func (q CompanyDailyQuote) Equal(s CompanyDailyQuote) error {
 		return fmt.Errorf("company is not equal due to %v", err)
 	}
 
-	err = q.Dividend.Equal(*s.Dividend)
+	err = q.Actions.Equal(s.Actions)
 	if err != nil {
-		return fmt.Errorf("dividend is not equal due to %v", err)
-	}
-
-	err = q.Split.Equal(*s.Split)
-	if err != nil {
-		return fmt.Errorf("split is not equal due to %v", err)
+		return fmt.Errorf("actions are not equal due to %v", err)
 	}
 
 	err = q.Pre.Equal(*s.Pre)
@@ -341,13 +277,5 @@ func (q CompanyDailyQuote) IsEmpty() bool {
 		return false
 	}
 
-	if q.Dividend != nil && q.Dividend.Enable {
-		return false
-	}
-
-	if q.Split != nil && q.Split.Enable {
-		return false
-	}
-
-	return true
+	return len(q.Actions) == 0
 }
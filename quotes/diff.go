@@ -0,0 +1,166 @@
+package quotes
+
+import (
+	"sort"
+)
+
+// DailyDiff define structured difference between two exchange daily quotes
+// left only and right only list companies missing on the other side,
+// mismatch is the Equal error if quotes differ in a way not reported by companies, like bar order or company list
+type DailyDiff struct {
+	LeftOnly  []string       `json:"left_only,omitempty"`
+	RightOnly []string       `json:"right_only,omitempty"`
+	Companies []*CompanyDiff `json:"companies,omitempty"`
+	Mismatch  string         `json:"mismatch,omitempty"`
+}
+
+// CompanyDiff define difference of one company daily quote
+type CompanyDiff struct {
+	Company string    `json:"company"`
+	Name    []string  `json:"name,omitempty"`
+	Actions []string  `json:"actions,omitempty"`
+	Bars    []BarDiff `json:"bars,omitempty"`
+}
+
+// BarDiff define difference of one bar, nil side means the bar is missing there
+type BarDiff struct {
+	Serial    string `json:"serial"`
+	Timestamp uint64 `json:"timestamp"`
+	Left      *Quote `json:"left"`
+	Right     *Quote `json:"right"`
+}
+
+// IsEmpty check no difference found
+func (d DailyDiff) IsEmpty() bool {
+	return len(d.LeftOnly) == 0 && len(d.RightOnly) == 0 && len(d.Companies) == 0 && d.Mismatch == ""
+}
+
+// Diff compare exchange daily quotes, report every missing company and changed bar instead of the first mismatch
+// the diff is empty if and only if quotes are Equal, it is only built for quotes not equal
+func Diff(left, right ExchangeDailyQuote) *DailyDiff {
+	diff := new(DailyDiff)
+
+	err := left.Equal(right)
+	if err == nil {
+		return diff
+	}
+
+	codes := make(map[string]bool, len(left.Quotes)+len(left.Companies))
+	for code := range left.Companies {
+		codes[code] = true
+	}
+	for code := range left.Quotes {
+		codes[code] = true
+	}
+	for code := range right.Companies {
+		codes[code] = true
+	}
+	for code := range right.Quotes {
+		codes[code] = true
+	}
+
+	sorted := make([]string, 0, len(codes))
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Strings(sorted)
+
+	for _, code := range sorted {
+		lq, lok := left.Quotes[code]
+		rq, rok := right.Quotes[code]
+		switch {
+		case lok && !rok:
+			diff.LeftOnly = append(diff.LeftOnly, code)
+		case !lok && rok:
+			diff.RightOnly = append(diff.RightOnly, code)
+		case lok && rok:
+			cd := diffCompany(code, lq, rq)
+			if cd != nil {
+				diff.Companies = append(diff.Companies, cd)
+			}
+		default:
+			// company without quote, compare companies map only
+			lc, lok := left.Companies[code]
+			rc, rok := right.Companies[code]
+			switch {
+			case lok && !rok:
+				diff.LeftOnly = append(diff.LeftOnly, code)
+			case !lok && rok:
+				diff.RightOnly = append(diff.RightOnly, code)
+			case lc.Name != rc.Name:
+				diff.Companies = append(diff.Companies, &CompanyDiff{Company: code, Name: []string{lc.Name, rc.Name}})
+			}
+		}
+	}
+
+	if diff.IsEmpty() {
+		diff.Mismatch = err.Error()
+	}
+
+	return diff
+}
+
+// diffCompany compare company daily quotes, return nil if equal
+func diffCompany(code string, left, right *CompanyDailyQuote) *CompanyDiff {
+	diff := &CompanyDiff{Company: code}
+	changed := false
+
+	if left.Company != nil && right.Company != nil && left.Company.Name != right.Company.Name {
+		diff.Name = []string{left.Company.Name, right.Company.Name}
+		changed = true
+	}
+
+	err := left.Actions.Equal(right.Actions)
+	if err != nil {
+		diff.Actions = []string{err.Error()}
+		changed = true
+	}
+
+	for _, serialType := range []SerialType{SerialTypePre, SerialTypeRegular, SerialTypePost} {
+		bars := diffSerial(serialType, left.serial(serialType), right.serial(serialType))
+		if len(bars) > 0 {
+			diff.Bars = append(diff.Bars, bars...)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return diff
+}
+
+// diffSerial merge serials by timestamp, report missing and changed bars
+func diffSerial(serialType SerialType, left, right *Serial) []BarDiff {
+	var ls, rs Serial
+	if left != nil {
+		ls = append(ls, *left...)
+	}
+	if right != nil {
+		rs = append(rs, *right...)
+	}
+	sort.Stable(ls)
+	sort.Stable(rs)
+
+	var bars []BarDiff
+	i, j := 0, 0
+	for i < len(ls) || j < len(rs) {
+		switch {
+		case j >= len(rs) || (i < len(ls) && ls[i].Timestamp < rs[j].Timestamp):
+			bars = append(bars, BarDiff{Serial: serialType.String(), Timestamp: ls[i].Timestamp, Left: &ls[i]})
+			i++
+		case i >= len(ls) || rs[j].Timestamp < ls[i].Timestamp:
+			bars = append(bars, BarDiff{Serial: serialType.String(), Timestamp: rs[j].Timestamp, Right: &rs[j]})
+			j++
+		default:
+			if ls[i].Equal(rs[j]) != nil {
+				bars = append(bars, BarDiff{Serial: serialType.String(), Timestamp: ls[i].Timestamp, Left: &ls[i], Right: &rs[j]})
+			}
+			i++
+			j++
+		}
+	}
+
+	return bars
+}
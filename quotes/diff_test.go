@@ -0,0 +1,103 @@
+package quotes
+
+import (
+	"testing"
+	"time"
+)
+
+// newDiffEDQ create exchange daily quote of companies with two regular bars each
+func newDiffEDQ(date time.Time, open uint64, codes ...string) ExchangeDailyQuote {
+	edq := ExchangeDailyQuote{
+		Exchange:  "Nasdaq",
+		Date:      date,
+		Companies: make(map[string]*Company),
+		Quotes:    make(map[string]*CompanyDailyQuote),
+	}
+
+	for _, code := range codes {
+		company := &Company{Code: code, Name: code}
+		edq.Companies[code] = company
+		edq.Quotes[code] = &CompanyDailyQuote{
+			Company: company,
+			Pre:     &Serial{},
+			Regular: &Serial{{Timestamp: open, Open: 1, Close: 1, High: 1, Low: 1, Volume: 1}, {Timestamp: open + 60, Open: 2, Close: 2, High: 2, Low: 2, Volume: 2}},
+			Post:    &Serial{},
+		}
+	}
+
+	return edq
+}
+
+func TestDiff(t *testing.T) {
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, time.UTC)
+	open := uint64(date.Add(time.Hour * 14).Unix())
+	newEDQ := func(codes ...string) ExchangeDailyQuote { return newDiffEDQ(date, open, codes...) }
+
+	left, right := newEDQ("AAPL", "MSFT"), newEDQ("AAPL", "MSFT")
+	if diff := Diff(left, right); !diff.IsEmpty() {
+		t.Fatalf("Diff() equal quotes = %+v, want empty", diff)
+	}
+
+	left, right = newEDQ("AAPL", "MSFT", "NVDA"), newEDQ("AAPL", "MSFT", "TSLA")
+	(*right.Quotes["MSFT"].Regular)[1].Close = 3
+	*right.Quotes["AAPL"].Regular = (*right.Quotes["AAPL"].Regular)[:1]
+
+	diff := Diff(left, right)
+	if len(diff.LeftOnly) != 1 || diff.LeftOnly[0] != "NVDA" || len(diff.RightOnly) != 1 || diff.RightOnly[0] != "TSLA" {
+		t.Errorf("Diff() left only %v, right only %v, want [NVDA] and [TSLA]", diff.LeftOnly, diff.RightOnly)
+	}
+
+	if len(diff.Companies) != 2 {
+		t.Fatalf("Diff() got %d changed companies, want 2", len(diff.Companies))
+	}
+
+	aapl, msft := diff.Companies[0], diff.Companies[1]
+	if len(aapl.Bars) != 1 || aapl.Bars[0].Left == nil || aapl.Bars[0].Right != nil || aapl.Bars[0].Timestamp != open+60 {
+		t.Errorf("Diff() AAPL bars = %+v, want missing right bar at %d", aapl.Bars, open+60)
+	}
+
+	if len(msft.Bars) != 1 || msft.Bars[0].Left == nil || msft.Bars[0].Right == nil || msft.Bars[0].Right.Close != 3 {
+		t.Errorf("Diff() MSFT bars = %+v, want changed bar", msft.Bars)
+	}
+}
+
+func TestDiff_AgreesWithEqual(t *testing.T) {
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, time.UTC)
+	open := uint64(date.Add(time.Hour * 14).Unix())
+
+	tests := []struct {
+		name   string
+		modify func(*ExchangeDailyQuote)
+	}{
+		{"same", func(edq *ExchangeDailyQuote) {}},
+		{"bar changed", func(edq *ExchangeDailyQuote) { (*edq.Quotes["AAPL"].Regular)[0].Volume++ }},
+		{"bar missing", func(edq *ExchangeDailyQuote) { *edq.Quotes["AAPL"].Regular = (*edq.Quotes["AAPL"].Regular)[:1] }},
+		{"bars unsorted", func(edq *ExchangeDailyQuote) {
+			regular := *edq.Quotes["AAPL"].Regular
+			regular[0], regular[1] = regular[1], regular[0]
+		}},
+		{"company missing", func(edq *ExchangeDailyQuote) {
+			delete(edq.Companies, "MSFT")
+			delete(edq.Quotes, "MSFT")
+		}},
+		{"company list only", func(edq *ExchangeDailyQuote) { delete(edq.Companies, "MSFT") }},
+		{"company renamed", func(edq *ExchangeDailyQuote) { edq.Quotes["AAPL"].Company = &Company{Code: "AAPL", Name: "Apple"} }},
+		{"actions changed", func(edq *ExchangeDailyQuote) {
+			edq.Quotes["AAPL"].Actions = Actions{{Type: ActionCashDividend, Timestamp: open, Amount: 0.23}}
+		}},
+		{"exchange changed", func(edq *ExchangeDailyQuote) { edq.Exchange = "Nyse" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, right := newDiffEDQ(date, open, "AAPL", "MSFT"), newDiffEDQ(date, open, "AAPL", "MSFT")
+			tt.modify(&right)
+
+			equal := left.Equal(right) == nil
+			diff := Diff(left, right)
+			if diff.IsEmpty() != equal {
+				t.Errorf("Diff().IsEmpty() = %v, Equal() = %v, diff %+v", diff.IsEmpty(), equal, diff)
+			}
+		})
+	}
+}
@@ -58,6 +58,11 @@ func (d *Dividend) Decode(r io.Reader) error {
 		return nil
 	}
 
+	return d.decodeEnabled(br)
+}
+
+// decodeEnabled decode enabled dividend after enable flag
+func (d *Dividend) decodeEnabled(br *bio.BinaryReader) error {
 	timestamp, err := br.UInt64()
 	if err != nil {
 		zap.L().Error("decode dividend timestamp failed", zap.Error(err))
@@ -70,7 +75,7 @@ func (d *Dividend) Decode(r io.Reader) error {
 		return err
 	}
 
-	d.Enable = enable
+	d.Enable = true
 	d.Timestamp = timestamp
 	d.Amount = amount
 
@@ -0,0 +1,86 @@
+package quotes
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MetaVersion current version of exchange daily quote meta
+// fields are only added, never removed or redefined, older readers ignore unknown fields
+// version 2 added resolution, version 3 added quality and company issues
+const MetaVersion = 3
+
+const (
+	// SourceYahoo yahoo finance chart api
+	SourceYahoo = "yahoo"
+	// SourceBse bse time sharing chart api
+	SourceBse = "bse"
+	// SourceIFeng ifeng finance split and dividend api
+	SourceIFeng = "ifeng"
+)
+
+// Meta define crawl provenance of exchange daily quote
+// resolution is the bar interval of quote serials after downsampling, zero means raw minute bars
+// quality is the validation summary of crawled quotes, nil if the day was not validated
+type Meta struct {
+	Version    int                     `json:"version"`
+	Software   string                  `json:"software,omitempty"`
+	CrawlStart time.Time               `json:"crawl_start"`
+	CrawlEnd   time.Time               `json:"crawl_end"`
+	Attempted  int                     `json:"attempted"`
+	Failed     int                     `json:"failed"`
+	Companies  map[string]*CompanyMeta `json:"companies,omitempty"`
+	Resolution time.Duration           `json:"resolution,omitempty"`
+	Quality    *Quality                `json:"quality,omitempty"`
+}
+
+// CompanyMeta define crawl provenance of company daily quote
+// event source is the source of dividend and split if it is different from quote source
+// issues are the quality issues found validating company quote, the company is dropped if it is not in quotes
+type CompanyMeta struct {
+	Source         string  `json:"source"`
+	EventSource    string  `json:"event_source,omitempty"`
+	Currency       string  `json:"currency,omitempty"`
+	PreviousClose  float32 `json:"previous_close,omitempty"`
+	ExchangeName   string  `json:"exchange_name,omitempty"`
+	InstrumentType string  `json:"instrument_type,omitempty"`
+	Timezone       string  `json:"timezone,omitempty"`
+	Issues         Issues  `json:"issues,omitempty"`
+}
+
+// NewMeta create meta of current version
+func NewMeta(software string, crawlStart, crawlEnd time.Time, attempted, failed int) *Meta {
+	return &Meta{
+		Version:    MetaVersion,
+		Software:   software,
+		CrawlStart: crawlStart,
+		CrawlEnd:   crawlEnd,
+		Attempted:  attempted,
+		Failed:     failed,
+		Companies:  make(map[string]*CompanyMeta),
+	}
+}
+
+// MarshalMeta encode meta to json, return nil if meta is nil
+func MarshalMeta(meta *Meta) ([]byte, error) {
+	if meta == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(meta)
+}
+
+// UnmarshalMeta decode meta from json, return nil if buffer is empty
+func UnmarshalMeta(buffer []byte) (*Meta, error) {
+	if len(buffer) == 0 {
+		return nil, nil
+	}
+
+	meta := new(Meta)
+	err := json.Unmarshal(buffer, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
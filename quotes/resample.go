@@ -0,0 +1,246 @@
+package quotes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// IntervalUnit define unit of resample interval
+type IntervalUnit byte
+
+const (
+	// IntervalMinute minutes, bars aligned to local midnight
+	IntervalMinute IntervalUnit = iota + 1
+	// IntervalHour hours, bars aligned to local midnight
+	IntervalHour
+	// IntervalDay calendar days, bars start at local midnight
+	IntervalDay
+	// IntervalWeek calendar weeks, bars start at local monday midnight
+	IntervalWeek
+	// IntervalMonth calendar months, bars start at local first day of month
+	IntervalMonth
+)
+
+var intervalUnitSymbols = map[IntervalUnit]string{
+	IntervalMinute: "m",
+	IntervalHour:   "h",
+	IntervalDay:    "d",
+	IntervalWeek:   "w",
+	IntervalMonth:  "M",
+}
+
+// Interval define resample bar interval like 5m, 1h, 1d, 1w, 1M
+type Interval struct {
+	Count int
+	Unit  IntervalUnit
+}
+
+// ParseInterval parse interval like 5m, 15m, 30m, 1h, 1d, 1w, 1M
+func ParseInterval(text string) (Interval, error) {
+	if len(text) < 2 {
+		return Interval{}, fmt.Errorf("interval invalid: %s", text)
+	}
+
+	count, err := strconv.Atoi(text[:len(text)-1])
+	if err != nil || count <= 0 {
+		return Interval{}, fmt.Errorf("interval invalid: %s", text)
+	}
+
+	for unit, symbol := range intervalUnitSymbols {
+		if symbol == text[len(text)-1:] {
+			return Interval{Count: count, Unit: unit}, nil
+		}
+	}
+
+	return Interval{}, fmt.Errorf("interval unit invalid: %s", text)
+}
+
+// IntervalOf convert whole minute duration to interval, return false if duration is not whole minutes
+func IntervalOf(duration time.Duration) (Interval, bool) {
+	if duration < time.Minute || duration%time.Minute != 0 {
+		return Interval{}, false
+	}
+
+	if duration%time.Hour == 0 {
+		return Interval{Count: int(duration / time.Hour), Unit: IntervalHour}, true
+	}
+
+	return Interval{Count: int(duration / time.Minute), Unit: IntervalMinute}, true
+}
+
+func (i Interval) String() string {
+	return strconv.Itoa(i.Count) + intervalUnitSymbols[i.Unit]
+}
+
+// Duration get fixed duration of intraday interval, return 0 for calendar intervals
+func (i Interval) Duration() time.Duration {
+	switch i.Unit {
+	case IntervalMinute:
+		return time.Minute * time.Duration(i.Count)
+	case IntervalHour:
+		return time.Hour * time.Duration(i.Count)
+	default:
+		return 0
+	}
+}
+
+// Start get start of bar contains t, in location of t
+func (i Interval) Start(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch i.Unit {
+	case IntervalMinute, IntervalHour:
+		offset := t.Sub(midnight)
+		return midnight.Add(offset - offset%i.Duration())
+	case IntervalDay:
+		days := (midnight.YearDay() - 1) % i.Count
+		return midnight.AddDate(0, 0, -days)
+	case IntervalWeek:
+		// monday is the first day of week
+		weekday := (int(midnight.Weekday()) + 6) % 7
+		monday := midnight.AddDate(0, 0, -weekday)
+		if i.Count == 1 {
+			return monday
+		}
+
+		_, week := monday.ISOWeek()
+		return monday.AddDate(0, 0, -7*((week-1)%i.Count))
+	case IntervalMonth:
+		month := (int(t.Month()) - 1) / i.Count * i.Count
+		return time.Date(t.Year(), time.Month(month+1), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return t
+	}
+}
+
+// Next get start of the bar after the bar starts at start
+func (i Interval) Next(start time.Time) time.Time {
+	switch i.Unit {
+	case IntervalMinute, IntervalHour:
+		next := start.Add(i.Duration())
+		// bars restart at local midnight, the last bar of day may be shorter
+		midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+		if next.After(midnight) {
+			return midnight
+		}
+
+		return next
+	case IntervalDay:
+		next := start.AddDate(0, 0, i.Count)
+		// bars restart at the first day of year
+		newYear := time.Date(start.Year()+1, 1, 1, 0, 0, 0, 0, start.Location())
+		if next.After(newYear) {
+			return newYear
+		}
+
+		return next
+	case IntervalWeek:
+		return i.Start(start.AddDate(0, 0, 7*i.Count))
+	case IntervalMonth:
+		return start.AddDate(0, i.Count, 0)
+	default:
+		return start
+	}
+}
+
+// Resample aggregate quotes into interval bars aligned in location, serial must be ordered by timestamp
+// bar timestamp is the bar start, open is the first open, close is the last close,
+// high and low are the extremes, volume is the sum
+func (s Serial) Resample(interval Interval, location *time.Location) Serial {
+	bars := make(Serial, 0, len(s))
+
+	var end uint64
+	for _, quote := range s {
+		if len(bars) == 0 || quote.Timestamp >= end || quote.Timestamp < bars[len(bars)-1].Timestamp {
+			start := interval.Start(time.Unix(int64(quote.Timestamp), 0).In(location))
+			end = uint64(interval.Next(start).Unix())
+
+			quote.Timestamp = uint64(start.Unix())
+			bars = append(bars, quote)
+			continue
+		}
+
+		bar := &bars[len(bars)-1]
+		bar.Close = quote.Close
+		if quote.High > bar.High {
+			bar.High = quote.High
+		}
+
+		if quote.Low < bar.Low {
+			bar.Low = quote.Low
+		}
+
+		bar.Volume += quote.Volume
+	}
+
+	return bars
+}
+
+// Resampler resample company daily quotes of one or more days into interval bars aligned in location
+// pre, regular and post sessions are resampled separately unless merge is set
+type Resampler struct {
+	Interval Interval
+	Location *time.Location
+	Merge    bool
+}
+
+// ResampledQuote define resampled bars of company
+// if sessions are merged, all bars are in regular, pre and post are empty
+type ResampledQuote struct {
+	Company *Company
+	Pre     Serial
+	Regular Serial
+	Post    Serial
+}
+
+// Resample resample company daily quotes, quotes should be ordered by date
+func (r Resampler) Resample(cdqs []*CompanyDailyQuote) *ResampledQuote {
+	rq := new(ResampledQuote)
+
+	var pre, regular, post Serial
+	for _, cdq := range cdqs {
+		if rq.Company == nil {
+			rq.Company = cdq.Company
+		}
+
+		if r.Merge {
+			regular = append(regular, cdq.quotes()...)
+			continue
+		}
+
+		pre = appendSerial(pre, cdq.Pre)
+		regular = appendSerial(regular, cdq.Regular)
+		post = appendSerial(post, cdq.Post)
+	}
+
+	if r.Merge {
+		sort.Sort(regular)
+	}
+
+	rq.Pre = pre.Resample(r.Interval, r.Location)
+	rq.Regular = regular.Resample(r.Interval, r.Location)
+	rq.Post = post.Resample(r.Interval, r.Location)
+
+	return rq
+}
+
+// quotes get pre, regular and post quotes in one serial
+func (q CompanyDailyQuote) quotes() Serial {
+	var serial Serial
+	serial = appendSerial(serial, q.Pre)
+	serial = appendSerial(serial, q.Regular)
+	serial = appendSerial(serial, q.Post)
+
+	return serial
+}
+
+// appendSerial append quotes of serial, nil serial is ignored
+func appendSerial(s Serial, serial *Serial) Serial {
+	if serial == nil {
+		return s
+	}
+
+	return append(s, *serial...)
+}
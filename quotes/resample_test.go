@@ -0,0 +1,118 @@
+package quotes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSerial_Resample(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	open := uint64(time.Date(2023, 3, 6, 9, 30, 0, 0, newYork).Unix())
+	serial := Serial{
+		{Timestamp: open, Open: 10, Close: 11, High: 12, Low: 9, Volume: 100},
+		{Timestamp: open + 60, Open: 11, Close: 13, High: 14, Low: 10, Volume: 200},
+		{Timestamp: open + 240, Open: 13, Close: 12, High: 13, Low: 8, Volume: 300},
+		{Timestamp: open + 360, Open: 12, Close: 15, High: 16, Low: 12, Volume: 400},
+	}
+
+	want := Serial{
+		{Timestamp: open, Open: 10, Close: 12, High: 14, Low: 8, Volume: 600},
+		{Timestamp: open + 300, Open: 12, Close: 15, High: 16, Low: 12, Volume: 400},
+	}
+
+	err := serial.Resample(Interval{Count: 5, Unit: IntervalMinute}, newYork).Equal(want)
+	if err != nil {
+		t.Errorf("Resample() not equal: %v", err)
+	}
+
+	// 1h bars align to local clock, 09:30 belongs to 09:00 bar
+	hourly := serial.Resample(Interval{Count: 1, Unit: IntervalHour}, newYork)
+	if len(hourly) != 1 || hourly[0].Timestamp != open-1800 {
+		t.Errorf("Resample(1h) = %+v, want one bar at 09:00", hourly)
+	}
+
+	err = hourly[0].Equal(Quote{Timestamp: open - 1800, Open: 10, Close: 15, High: 16, Low: 8, Volume: 1000})
+	if err != nil {
+		t.Errorf("Resample(1h) not equal: %v", err)
+	}
+}
+
+func TestInterval_Start(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	at := time.Date(2023, 3, 8, 14, 47, 0, 0, shanghai) // wednesday
+
+	tests := []struct {
+		interval string
+		want     time.Time
+	}{
+		{"15m", time.Date(2023, 3, 8, 14, 45, 0, 0, shanghai)},
+		{"30m", time.Date(2023, 3, 8, 14, 30, 0, 0, shanghai)},
+		{"1h", time.Date(2023, 3, 8, 14, 0, 0, 0, shanghai)},
+		{"1d", time.Date(2023, 3, 8, 0, 0, 0, 0, shanghai)},
+		{"1w", time.Date(2023, 3, 6, 0, 0, 0, 0, shanghai)},
+		{"1M", time.Date(2023, 3, 1, 0, 0, 0, 0, shanghai)},
+		{"3M", time.Date(2023, 1, 1, 0, 0, 0, 0, shanghai)},
+	}
+
+	for _, tt := range tests {
+		interval, err := ParseInterval(tt.interval)
+		if err != nil {
+			t.Fatalf("ParseInterval(%s) error = %v", tt.interval, err)
+		}
+
+		if interval.String() != tt.interval {
+			t.Errorf("Interval.String() = %s, want %s", interval, tt.interval)
+		}
+
+		got := interval.Start(at)
+		if !got.Equal(tt.want) {
+			t.Errorf("Interval(%s).Start() = %v, want %v", tt.interval, got, tt.want)
+		}
+
+		if !interval.Next(got).After(at) {
+			t.Errorf("Interval(%s).Next() = %v, not after %v", tt.interval, interval.Next(got), at)
+		}
+	}
+}
+
+func TestResampler_Resample(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	var cdqs []*CompanyDailyQuote
+	for day := 6; day <= 8; day++ {
+		open := uint64(time.Date(2023, 3, day, 9, 30, 0, 0, newYork).Unix())
+		price := float32(day)
+		cdqs = append(cdqs, &CompanyDailyQuote{
+			Company: &Company{Code: "AAPL", Name: "Apple Inc."},
+			Pre:     &Serial{{Timestamp: open - 3600, Open: price, Close: price, High: price, Low: price, Volume: 1}},
+			Regular: &Serial{
+				{Timestamp: open, Open: price, Close: price + 1, High: price + 2, Low: price - 1, Volume: 10},
+				{Timestamp: open + 60, Open: price + 1, Close: price + 0.5, High: price + 1, Low: price, Volume: 20},
+			},
+			Post: &Serial{{Timestamp: open + 23400, Open: price, Close: price, High: price + 3, Low: price, Volume: 2}},
+		})
+	}
+
+	week := Interval{Count: 1, Unit: IntervalWeek}
+	monday := uint64(time.Date(2023, 3, 6, 0, 0, 0, 0, newYork).Unix())
+
+	separate := Resampler{Interval: week, Location: newYork}.Resample(cdqs)
+	err := separate.Regular.Equal(Serial{{Timestamp: monday, Open: 6, Close: 8.5, High: 10, Low: 5, Volume: 90}})
+	if err != nil {
+		t.Errorf("Resample() regular not equal: %v", err)
+	}
+
+	if len(separate.Pre) != 1 || separate.Pre[0].Volume != 3 || len(separate.Post) != 1 || separate.Post[0].High != 11 {
+		t.Errorf("Resample() pre = %+v, post = %+v", separate.Pre, separate.Post)
+	}
+
+	merged := Resampler{Interval: week, Location: newYork, Merge: true}.Resample(cdqs)
+	err = merged.Regular.Equal(Serial{{Timestamp: monday, Open: 6, Close: 8, High: 11, Low: 5, Volume: 99}})
+	if err != nil {
+		t.Errorf("Resample() merged not equal: %v", err)
+	}
+
+	if len(merged.Pre) != 0 || len(merged.Post) != 0 {
+		t.Errorf("Resample() merged pre = %+v, post = %+v, want empty", merged.Pre, merged.Post)
+	}
+}
@@ -0,0 +1,234 @@
+package quotes
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/nzai/bio"
+	"go.uber.org/zap"
+)
+
+// CompanyQuoteIterator iterate company daily quotes one by one
+type CompanyQuoteIterator interface {
+	// NextCompanyQuote get next company daily quote, return io.EOF after the last one
+	NextCompanyQuote() (*CompanyDailyQuote, error)
+}
+
+// ExchangeDailyQuoteReader read exchange daily quote company by company, memory stays flat regardless of exchange size
+type ExchangeDailyQuoteReader struct {
+	Exchange  string
+	Date      time.Time
+	Companies map[string]*Company
+	br        *bio.BinaryReader
+	codec     SerialCodec
+	count     int
+	read      int
+}
+
+// NewExchangeDailyQuoteReader create exchange daily quote reader, exchange, date and companies are decoded immediately
+func NewExchangeDailyQuoteReader(r io.Reader, codec SerialCodec) (*ExchangeDailyQuoteReader, error) {
+	br := bio.NewBinaryReader(r)
+
+	exchange, err := br.String()
+	if err != nil {
+		zap.L().Error("decode exchange failed", zap.Error(err))
+		return nil, err
+	}
+
+	date, err := br.Time()
+	if err != nil {
+		zap.L().Error("decode date failed", zap.Error(err))
+		return nil, err
+	}
+
+	count, err := br.Int()
+	if err != nil {
+		zap.L().Error("decode company count failed", zap.Error(err))
+		return nil, err
+	}
+
+	companies := make(map[string]*Company, count)
+	for index := 0; index < count; index++ {
+		company := new(Company)
+		err = company.Decode(br)
+		if err != nil {
+			zap.L().Error("decode company failed", zap.Error(err))
+			return nil, err
+		}
+
+		companies[company.Code] = company
+	}
+
+	count, err = br.Int()
+	if err != nil {
+		zap.L().Error("decode quotes count failed", zap.Error(err))
+		return nil, err
+	}
+
+	return &ExchangeDailyQuoteReader{
+		Exchange:  exchange,
+		Date:      date,
+		Companies: companies,
+		br:        br,
+		codec:     codec,
+		count:     count,
+	}, nil
+}
+
+// Len get company daily quote count
+func (r ExchangeDailyQuoteReader) Len() int {
+	return r.count
+}
+
+// NextCompanyQuote decode next company daily quote, return io.EOF after the last one
+func (r *ExchangeDailyQuoteReader) NextCompanyQuote() (*CompanyDailyQuote, error) {
+	if r.read >= r.count {
+		return nil, io.EOF
+	}
+
+	cdq := new(CompanyDailyQuote)
+	err := cdq.DecodeCodec(r.br, r.codec)
+	if err != nil {
+		zap.L().Error("decode daily quote failed", zap.Error(err), zap.Int("index", r.read))
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	r.read++
+
+	return cdq, nil
+}
+
+// ReadAll read remaining company daily quotes into exchange daily quote
+func (r *ExchangeDailyQuoteReader) ReadAll() (*ExchangeDailyQuote, error) {
+	cdqs := make(map[string]*CompanyDailyQuote, r.count-r.read)
+	for {
+		cdq, err := r.NextCompanyQuote()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		cdqs[cdq.Company.Code] = cdq
+	}
+
+	return &ExchangeDailyQuote{
+		Exchange:  r.Exchange,
+		Date:      r.Date,
+		Companies: r.Companies,
+		Quotes:    cdqs,
+	}, nil
+}
+
+// Iterator iterate company daily quotes of exchange daily quote in memory
+func (q ExchangeDailyQuote) Iterator() CompanyQuoteIterator {
+	cdqs := make([]*CompanyDailyQuote, 0, len(q.Quotes))
+	for _, cdq := range q.Quotes {
+		cdqs = append(cdqs, cdq)
+	}
+
+	return &sliceIterator{cdqs: cdqs}
+}
+
+// sliceIterator iterate company daily quotes in slice
+type sliceIterator struct {
+	cdqs []*CompanyDailyQuote
+}
+
+// NextCompanyQuote get next company daily quote, return io.EOF after the last one
+func (i *sliceIterator) NextCompanyQuote() (*CompanyDailyQuote, error) {
+	if len(i.cdqs) == 0 {
+		return nil, io.EOF
+	}
+
+	cdq := i.cdqs[0]
+	i.cdqs = i.cdqs[1:]
+
+	return cdq, nil
+}
+
+// ExchangeDailyQuoteWriter write exchange daily quote company by company
+// the binary format is count-prefixed, so company daily quote count must be known before writing
+type ExchangeDailyQuoteWriter struct {
+	bw      *bio.BinaryWriter
+	codec   SerialCodec
+	count   int
+	written int
+}
+
+// NewExchangeDailyQuoteWriter create exchange daily quote writer, exchange, date and companies are encoded immediately
+func NewExchangeDailyQuoteWriter(w io.Writer, codec SerialCodec, exchange string, date time.Time, companies map[string]*Company, count int) (*ExchangeDailyQuoteWriter, error) {
+	bw := bio.NewBinaryWriter(w)
+
+	_, err := bw.String(exchange)
+	if err != nil {
+		zap.L().Error("encode exchange code failed", zap.Error(err), zap.String("exchange", exchange))
+		return nil, err
+	}
+
+	_, err = bw.Time(date)
+	if err != nil {
+		zap.L().Error("encode date failed", zap.Error(err), zap.Time("date", date))
+		return nil, err
+	}
+
+	_, err = bw.Int(len(companies))
+	if err != nil {
+		zap.L().Error("encode company count failed", zap.Error(err), zap.Int("count", len(companies)))
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(companies))
+	for code := range companies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		company := companies[code]
+		err = company.Encode(bw)
+		if err != nil {
+			zap.L().Error("encode companye failed", zap.Error(err), zap.Any("company", company))
+			return nil, err
+		}
+	}
+
+	_, err = bw.Int(count)
+	if err != nil {
+		zap.L().Error("encode quotes count failed", zap.Error(err), zap.Int("count", count))
+		return nil, err
+	}
+
+	return &ExchangeDailyQuoteWriter{bw: bw, codec: codec, count: count}, nil
+}
+
+// WriteCompanyQuote encode company daily quote
+func (w *ExchangeDailyQuoteWriter) WriteCompanyQuote(cdq *CompanyDailyQuote) error {
+	if w.written >= w.count {
+		return fmt.Errorf("company daily quote count exceeded %d", w.count)
+	}
+
+	err := cdq.EncodeCodec(w.bw, w.codec)
+	if err != nil {
+		zap.L().Error("encode daily quote failed", zap.Error(err), zap.Any("company", cdq.Company))
+		return err
+	}
+	w.written++
+
+	return nil
+}
+
+// Close check every declared company daily quote is written, does not close the underlying writer
+func (w *ExchangeDailyQuoteWriter) Close() error {
+	if w.written != w.count {
+		return fmt.Errorf("company daily quote written %d, declared %d", w.written, w.count)
+	}
+
+	return nil
+}
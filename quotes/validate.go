@@ -0,0 +1,331 @@
+package quotes
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Issue define data quality issue of crawled quote bar
+type Issue string
+
+const (
+	// IssueUnsorted timestamp is before previous bar
+	IssueUnsorted Issue = "timestamp_unsorted"
+	// IssueDuplicate timestamp is the same as previous bar
+	IssueDuplicate Issue = "timestamp_duplicate"
+	// IssueOutOfSession timestamp is outside the session window of its serial
+	IssueOutOfSession Issue = "out_of_session"
+	// IssuePriceRange high is lower than low, or open or close is outside high and low
+	IssuePriceRange Issue = "price_out_of_range"
+	// IssueZeroPrice price is zero, negative, NaN or infinite
+	IssueZeroPrice Issue = "price_zero"
+	// IssueZeroVolume bar extends a run of zero volume regular bars longer than allowed
+	IssueZeroVolume Issue = "zero_volume_run"
+	// IssuePriceJump open or close changes too much from previous bar close
+	IssuePriceJump Issue = "price_jump"
+)
+
+// allIssues list all issues
+var allIssues = []Issue{IssueUnsorted, IssueDuplicate, IssueOutOfSession, IssuePriceRange, IssueZeroPrice, IssueZeroVolume, IssuePriceJump}
+
+// ParseIssue parse issue name
+func ParseIssue(name string) (Issue, error) {
+	for _, issue := range allIssues {
+		if string(issue) == name {
+			return issue, nil
+		}
+	}
+
+	return "", fmt.Errorf("issue invalid: %s", name)
+}
+
+// Issues define issue counts
+type Issues map[Issue]int
+
+// Policy define how to handle bar with issue, policies are ordered by severity
+type Policy int
+
+const (
+	// PolicyFlag keep bar and record issue only
+	PolicyFlag Policy = iota
+	// PolicyDropBar drop bar
+	PolicyDropBar
+	// PolicyDropCompany drop company daily quote
+	PolicyDropCompany
+	// PolicyFailDay fail the whole exchange day
+	PolicyFailDay
+)
+
+var policyNames = map[Policy]string{
+	PolicyFlag:        "flag",
+	PolicyDropBar:     "drop_bar",
+	PolicyDropCompany: "drop_company",
+	PolicyFailDay:     "fail_day",
+}
+
+func (p Policy) String() string {
+	name, found := policyNames[p]
+	if !found {
+		return fmt.Sprintf("policy(%d)", int(p))
+	}
+
+	return name
+}
+
+// ParsePolicy parse policy name
+func ParsePolicy(name string) (Policy, error) {
+	for policy, policyName := range policyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("policy invalid: %s", name)
+}
+
+// SessionWindow report whether time is inside the trading session of serial type
+type SessionWindow func(SerialType, time.Time) bool
+
+// ErrQualityFailed exchange daily quote failed quality validation
+var ErrQualityFailed = errors.New("quote quality validation failed")
+
+// Quality define data quality summary of exchange daily quote
+type Quality struct {
+	Companies        int    `json:"companies"`
+	Bars             int    `json:"bars"`
+	Issues           Issues `json:"issues,omitempty"`
+	DroppedBars      int    `json:"dropped_bars,omitempty"`
+	DroppedCompanies int    `json:"dropped_companies,omitempty"`
+}
+
+// Validation define validation result of company daily quote
+// quote is the company daily quote without dropped bars, policy is the most severe policy of issues found
+type Validation struct {
+	Quote       *CompanyDailyQuote
+	Issues      Issues
+	DroppedBars int
+	Policy      Policy
+}
+
+// Validator define crawled quote validation pipeline
+// max jump is the largest relative change of open or close from previous bar close, zero disables the check
+// max zero volume run is the longest run of zero volume regular bars allowed, zero disables the check
+type Validator struct {
+	Policies         map[Issue]Policy
+	MaxJump          float64
+	MaxZeroVolumeRun int
+}
+
+// NewValidator create validator with default policies, malformed bars are dropped and suspicious bars are flagged
+func NewValidator() *Validator {
+	return &Validator{
+		Policies: map[Issue]Policy{
+			IssueUnsorted:     PolicyDropBar,
+			IssueDuplicate:    PolicyDropBar,
+			IssueOutOfSession: PolicyFlag,
+			IssuePriceRange:   PolicyDropBar,
+			IssueZeroPrice:    PolicyDropBar,
+			IssueZeroVolume:   PolicyFlag,
+			IssuePriceJump:    PolicyFlag,
+		},
+		MaxJump:          0.5,
+		MaxZeroVolumeRun: 30,
+	}
+}
+
+// SetPolicy set policy of issue
+func (v Validator) SetPolicy(issue Issue, policy Policy) error {
+	_, err := ParseIssue(string(issue))
+	if err != nil {
+		return err
+	}
+
+	if _, found := policyNames[policy]; !found {
+		return fmt.Errorf("policy invalid: %d", policy)
+	}
+
+	v.Policies[issue] = policy
+	return nil
+}
+
+// Validate validate company daily quote, session window is not checked if it is nil
+func (v Validator) Validate(cdq *CompanyDailyQuote, window SessionWindow) *Validation {
+	validation := &Validation{
+		Quote: &CompanyDailyQuote{
+			Company: cdq.Company,
+			Actions: cdq.Actions,
+			Pre:     new(Serial),
+			Regular: new(Serial),
+			Post:    new(Serial),
+			Meta:    cdq.Meta,
+		},
+		Issues: make(Issues),
+	}
+
+	// previous kept bar of the day, bars are compared across serials
+	var previous *Quote
+	for _, serialType := range []SerialType{SerialTypePre, SerialTypeRegular, SerialTypePost} {
+		serial := cdq.serial(serialType)
+		if serial == nil {
+			continue
+		}
+
+		kept := validation.Quote.serial(serialType)
+		zeroVolumes := 0
+		for _, quote := range *serial {
+			issues := v.check(quote, previous, serialType, window)
+
+			if serialType == SerialTypeRegular && v.MaxZeroVolumeRun > 0 {
+				if quote.Volume == 0 {
+					zeroVolumes++
+				} else {
+					zeroVolumes = 0
+				}
+
+				if zeroVolumes > v.MaxZeroVolumeRun {
+					issues = append(issues, IssueZeroVolume)
+				}
+			}
+
+			drop := false
+			for _, issue := range issues {
+				validation.Issues[issue]++
+
+				policy := v.Policies[issue]
+				if policy > validation.Policy {
+					validation.Policy = policy
+				}
+
+				if policy == PolicyDropBar {
+					drop = true
+				}
+			}
+
+			if drop {
+				validation.DroppedBars++
+				continue
+			}
+
+			*kept = append(*kept, quote)
+			previous = &(*kept)[len(*kept)-1]
+		}
+	}
+
+	return validation
+}
+
+// check check issues of single bar against previous kept bar
+func (v Validator) check(quote Quote, previous *Quote, serialType SerialType, window SessionWindow) []Issue {
+	var issues []Issue
+
+	validPrice := true
+	for _, price := range []float32{quote.Open, quote.Close, quote.High, quote.Low} {
+		value := float64(price)
+		if math.IsNaN(value) || math.IsInf(value, 0) || value <= 0 {
+			validPrice = false
+			issues = append(issues, IssueZeroPrice)
+			break
+		}
+	}
+
+	if validPrice && (quote.High < quote.Low ||
+		quote.Open > quote.High || quote.Open < quote.Low ||
+		quote.Close > quote.High || quote.Close < quote.Low) {
+		issues = append(issues, IssuePriceRange)
+	}
+
+	if previous != nil {
+		if quote.Timestamp == previous.Timestamp {
+			issues = append(issues, IssueDuplicate)
+		} else if quote.Timestamp < previous.Timestamp {
+			issues = append(issues, IssueUnsorted)
+		}
+	}
+
+	if window != nil && !window(serialType, time.Unix(int64(quote.Timestamp), 0)) {
+		issues = append(issues, IssueOutOfSession)
+	}
+
+	if validPrice && v.MaxJump > 0 && previous != nil && previous.Close > 0 {
+		for _, price := range []float32{quote.Open, quote.Close} {
+			if math.Abs(float64(price)/float64(previous.Close)-1) > v.MaxJump {
+				issues = append(issues, IssuePriceJump)
+				break
+			}
+		}
+	}
+
+	return issues
+}
+
+// ValidateDay validate company quotes of exchange daily quote
+// bars and companies are dropped by policies, issues of companies are recorded in company meta and quality summary in meta,
+// return ErrQualityFailed if any issue fails the day
+func (v Validator) ValidateDay(edq *ExchangeDailyQuote, window SessionWindow) error {
+	if edq.Meta == nil {
+		edq.Meta = &Meta{Version: MetaVersion}
+	}
+
+	if edq.Meta.Companies == nil {
+		edq.Meta.Companies = make(map[string]*CompanyMeta)
+	}
+
+	quality := &Quality{Issues: make(Issues)}
+	var failed []string
+	for code, cdq := range edq.Quotes {
+		quality.Companies++
+		quality.Bars += cdq.bars()
+
+		validation := v.Validate(cdq, window)
+		quality.DroppedBars += validation.DroppedBars
+		for issue, count := range validation.Issues {
+			quality.Issues[issue] += count
+		}
+
+		if len(validation.Issues) > 0 {
+			cm, found := edq.Meta.Companies[code]
+			if !found {
+				cm = new(CompanyMeta)
+				edq.Meta.Companies[code] = cm
+			}
+			cm.Issues = validation.Issues
+		}
+
+		switch {
+		case validation.Policy == PolicyFailDay:
+			failed = append(failed, code)
+		case validation.Policy == PolicyDropCompany || validation.Quote.IsEmpty():
+			quality.DroppedCompanies++
+			delete(edq.Quotes, code)
+		default:
+			edq.Quotes[code] = validation.Quote
+		}
+	}
+
+	if len(quality.Issues) == 0 {
+		quality.Issues = nil
+	}
+	edq.Meta.Quality = quality
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%w: companies %v have issues failing the day", ErrQualityFailed, failed)
+	}
+
+	return nil
+}
+
+// bars get bar count of all serials
+func (q CompanyDailyQuote) bars() int {
+	count := 0
+	for _, serial := range []*Serial{q.Pre, q.Regular, q.Post} {
+		if serial != nil {
+			count += len(*serial)
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,137 @@
+package quotes
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	open := uint64(time.Date(2023, 3, 6, 14, 30, 0, 0, time.UTC).Unix())
+	cdq := &CompanyDailyQuote{
+		Company: &Company{Code: "AAPL", Name: "Apple Inc."},
+		Pre:     &Serial{{Timestamp: open - 60, Open: 10, Close: 10, High: 10, Low: 10, Volume: 1}},
+		Regular: &Serial{
+			{Timestamp: open, Open: 10, Close: 11, High: 11, Low: 10, Volume: 10},
+			{Timestamp: open, Open: 11, Close: 11, High: 11, Low: 11, Volume: 10},
+			{Timestamp: open + 60, Open: 11, Close: 12, High: 11.5, Low: 11, Volume: 10},
+			{Timestamp: open + 120, Open: 0, Close: 12, High: 12, Low: 12, Volume: 10},
+			{Timestamp: open + 180, Open: float32(math.NaN()), Close: 12, High: 12, Low: 12, Volume: 10},
+			{Timestamp: open + 240, Open: 11, Close: 30, High: 30, Low: 11, Volume: 0},
+			{Timestamp: open + 300, Open: 30, Close: 30, High: 30, Low: 30, Volume: 0},
+			{Timestamp: open + 360, Open: 30, Close: 30, High: 30, Low: 30, Volume: 0},
+			{Timestamp: open + 30, Open: 30, Close: 30, High: 30, Low: 30, Volume: 10},
+		},
+		Post: &Serial{{Timestamp: open + 7*3600, Open: 30, Close: 30, High: 30, Low: 30, Volume: 1}},
+	}
+
+	// pre market is out of session
+	window := func(serialType SerialType, t time.Time) bool {
+		return serialType != SerialTypePre
+	}
+
+	validator := NewValidator()
+	validator.MaxZeroVolumeRun = 2
+
+	validation := validator.Validate(cdq, window)
+	want := Issues{
+		IssueOutOfSession: 1,
+		IssueDuplicate:    1,
+		IssuePriceRange:   1,
+		IssueZeroPrice:    2,
+		IssuePriceJump:    1,
+		IssueZeroVolume:   1,
+		IssueUnsorted:     1,
+	}
+
+	for issue, count := range want {
+		if validation.Issues[issue] != count {
+			t.Errorf("Validate() %s = %d, want %d", issue, validation.Issues[issue], count)
+		}
+	}
+
+	if validation.DroppedBars != 5 {
+		t.Errorf("Validate() dropped bars = %d, want 5", validation.DroppedBars)
+	}
+
+	if len(*validation.Quote.Pre) != 1 || len(*validation.Quote.Regular) != 4 || len(*validation.Quote.Post) != 1 {
+		t.Errorf("Validate() kept %d %d %d bars, want 1 4 1", len(*validation.Quote.Pre), len(*validation.Quote.Regular), len(*validation.Quote.Post))
+	}
+
+	if validation.Policy != PolicyDropBar {
+		t.Errorf("Validate() policy = %s, want %s", validation.Policy, PolicyDropBar)
+	}
+
+	// raw quote is not changed
+	if len(*cdq.Regular) != 9 {
+		t.Errorf("Validate() changed raw quote")
+	}
+}
+
+func TestValidator_ValidateDay(t *testing.T) {
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, time.UTC)
+	open := uint64(date.Add(time.Hour * 14).Unix())
+	newQuote := func(code string, serial Serial) *CompanyDailyQuote {
+		return &CompanyDailyQuote{Company: &Company{Code: code}, Pre: &Serial{}, Regular: &serial, Post: &Serial{}}
+	}
+
+	newDay := func() *ExchangeDailyQuote {
+		return &ExchangeDailyQuote{
+			Exchange: "Nasdaq",
+			Date:     date,
+			Quotes: map[string]*CompanyDailyQuote{
+				"AAPL": newQuote("AAPL", Serial{{Timestamp: open, Open: 1, Close: 1, High: 1, Low: 1, Volume: 1}}),
+				"MSFT": newQuote("MSFT", Serial{{Timestamp: open, Open: 1, Close: 1, High: 1, Low: 1, Volume: 1}, {Timestamp: open, Open: 1, Close: 1, High: 1, Low: 1, Volume: 1}}),
+				"GOOG": newQuote("GOOG", Serial{{Timestamp: open, Open: 1, Close: 1, High: 0.5, Low: 1, Volume: 1}}),
+			},
+			Meta: NewMeta("v0.1.0", date, date, 3, 0),
+		}
+	}
+
+	edq := newDay()
+	validator := NewValidator()
+	err := validator.ValidateDay(edq, nil)
+	if err != nil {
+		t.Fatalf("ValidateDay() error = %v", err)
+	}
+
+	// goog has no bar left
+	if len(edq.Quotes) != 2 || len(*edq.Quotes["MSFT"].Regular) != 1 {
+		t.Errorf("ValidateDay() quotes = %v", edq.Quotes)
+	}
+
+	quality := edq.Meta.Quality
+	if quality.Companies != 3 || quality.Bars != 4 || quality.DroppedBars != 2 || quality.DroppedCompanies != 1 {
+		t.Errorf("ValidateDay() quality = %+v", quality)
+	}
+
+	if edq.Meta.Companies["MSFT"].Issues[IssueDuplicate] != 1 || edq.Meta.Companies["GOOG"].Issues[IssuePriceRange] != 1 {
+		t.Errorf("ValidateDay() company issues = %+v %+v", edq.Meta.Companies["MSFT"], edq.Meta.Companies["GOOG"])
+	}
+
+	err = validator.SetPolicy(IssueDuplicate, PolicyDropCompany)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edq = newDay()
+	err = validator.ValidateDay(edq, nil)
+	if err != nil {
+		t.Fatalf("ValidateDay() error = %v", err)
+	}
+
+	if _, found := edq.Quotes["MSFT"]; found {
+		t.Errorf("ValidateDay() kept company MSFT dropped by policy")
+	}
+
+	err = validator.SetPolicy(IssueDuplicate, PolicyFailDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = validator.ValidateDay(newDay(), nil)
+	if !errors.Is(err, ErrQualityFailed) {
+		t.Errorf("ValidateDay() error = %v, want %v", err, ErrQualityFailed)
+	}
+}
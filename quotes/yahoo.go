@@ -3,6 +3,7 @@ package quotes
 import (
 	"encoding/json"
 	"errors"
+	"sort"
 
 	"github.com/nzai/qr/utils"
 	"go.uber.org/zap"
@@ -84,18 +85,6 @@ func (q YahooQuote) Validate() error {
 	// 	return errors.New("quote.Chart.Result[0].Meta.TradingPeriods invalie")
 	// }
 
-	if len(q.Chart.Result[0].Events.Dividends) > 1 {
-		zap.L().Warn("dividends count > 1",
-			zap.Int("count", len(q.Chart.Result[0].Events.Dividends)),
-			zap.Any("dividends", q.Chart.Result[0].Events.Dividends))
-	}
-
-	if len(q.Chart.Result[0].Events.Splits) > 1 {
-		zap.L().Warn("splits count > 1",
-			zap.Int("count", len(q.Chart.Result[0].Events.Splits)),
-			zap.Any("splits", q.Chart.Result[0].Events.Splits))
-	}
-
 	result, _quote := q.Chart.Result[0], q.Chart.Result[0].Indicators.Quotes[0]
 
 	// quotes count mismatch
@@ -112,13 +101,20 @@ func (q YahooQuote) Validate() error {
 
 // ToCompanyDailyQuote convert yahoo finance response to company daily quote
 func (q YahooQuote) ToCompanyDailyQuote(company *Company, start, end uint64) *CompanyDailyQuote {
+	meta := q.Chart.Result[0].Meta
 	cdq := &CompanyDailyQuote{
-		Company:  company,
-		Dividend: &Dividend{Enable: false, Timestamp: 0, Amount: 0},
-		Split:    &Split{Enable: false, Timestamp: 0, Numerator: 0, Denominator: 0},
-		Pre:      new(Serial),
-		Regular:  new(Serial),
-		Post:     new(Serial),
+		Company: company,
+		Pre:     new(Serial),
+		Regular: new(Serial),
+		Post:    new(Serial),
+		Meta: &CompanyMeta{
+			Source:         SourceYahoo,
+			Currency:       meta.Currency,
+			PreviousClose:  meta.PreviousClose,
+			ExchangeName:   meta.ExchangeName,
+			InstrumentType: meta.InstrumentType,
+			Timezone:       meta.Timezone,
+		},
 	}
 
 	for _, dividend := range q.Chart.Result[0].Events.Dividends {
@@ -126,10 +122,11 @@ func (q YahooQuote) ToCompanyDailyQuote(company *Company, start, end uint64) *Co
 			continue
 		}
 
-		cdq.Dividend.Enable = true
-		cdq.Dividend.Timestamp = dividend.Date
-		cdq.Dividend.Amount = dividend.Amount
-		break
+		cdq.Actions = append(cdq.Actions, Action{
+			Type:      ActionCashDividend,
+			Timestamp: dividend.Date,
+			Amount:    dividend.Amount,
+		})
 	}
 
 	for _, split := range q.Chart.Result[0].Events.Splits {
@@ -137,12 +134,14 @@ func (q YahooQuote) ToCompanyDailyQuote(company *Company, start, end uint64) *Co
 			continue
 		}
 
-		cdq.Split.Enable = true
-		cdq.Split.Timestamp = split.Date
-		cdq.Split.Numerator = float32(split.Numerator)
-		cdq.Split.Denominator = float32(split.Denominator)
-		break
+		cdq.Actions = append(cdq.Actions, Action{
+			Type:        ActionSplit,
+			Timestamp:   split.Date,
+			Numerator:   float32(split.Numerator),
+			Denominator: float32(split.Denominator),
+		})
 	}
+	sort.Stable(cdq.Actions)
 
 	regularPeroid := q.getRegularTradingPeroid()
 	if regularPeroid == nil {
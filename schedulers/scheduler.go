@@ -1,6 +1,7 @@
 package schedulers
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -20,37 +21,65 @@ type Scheduler struct {
 	// notifier  notifiers.Notifier
 	exchanges []exchanges.Exchange
 	limiter   *Limiter
+	validator *quotes.Validator
 }
 
 // NewScheduler create crawl scheduler
 func NewScheduler(store stores.Store, exchanges ...exchanges.Exchange) *Scheduler {
+	// report replicas which missed a write reached quorum
+	tee, ok := store.(*stores.Tee)
+	if ok {
+		tee.SetDivergeHandler(diverged)
+	}
+
 	return &Scheduler{
 		store: store,
 		// notifier:  notifier,
 		exchanges: exchanges,
 		limiter:   NewLimiter(constants.DefaultParallel),
+		validator: quotes.NewValidator(),
 	}
 }
 
-// Run jobs
-func (s Scheduler) Run(start time.Time) *sync.WaitGroup {
+// diverged report tee store replicas failed to write exchange daily quote, the day need re-sync by qsync
+func diverged(exchange exchanges.Exchange, date time.Time, teeErr stores.TeeError) {
+	zap.L().Error("tee store replicas diverged",
+		zap.Error(teeErr),
+		zap.String("exchange", exchange.Code()),
+		zap.Time("date", date))
+
+	err := utils.GetWeChatService().SendMessage(fmt.Sprintf("%s %s replicas diverged due to %s", exchange.Code(), date.Format("2006-01-02"), teeErr))
+	if err != nil {
+		zap.L().Error("send replicas diverged message failed", zap.Error(err))
+	}
+}
+
+// SetValidator replace validator of crawled quotes
+func (s *Scheduler) SetValidator(validator *quotes.Validator) {
+	s.validator = validator
+}
+
+// Run jobs, jobs stop when context done
+func (s Scheduler) Run(ctx context.Context, start time.Time) *sync.WaitGroup {
 	wg := new(sync.WaitGroup)
 
 	for _, exchange := range s.exchanges {
 		wg.Add(2)
 
 		// start history job
-		go s.historyJob(wg, exchange, start)
+		go s.historyJob(ctx, wg, exchange, start)
 
 		// start daily job
-		go s.dailyJob(wg, exchange)
+		go s.dailyJob(ctx, wg, exchange)
 	}
 
 	return wg
 }
 
 // historyJob crawl exchange history quotes
-func (s Scheduler) historyJob(wg *sync.WaitGroup, exchange exchanges.Exchange, start time.Time) {
+func (s Scheduler) historyJob(ctx context.Context, wg *sync.WaitGroup, exchange exchanges.Exchange, start time.Time) {
+	defer wg.Done()
+
 	// fix start time
 	start = utils.TodayZero(start.In(exchange.Location()))
 	end := utils.YesterdayZero(time.Now().In(exchange.Location()))
@@ -59,22 +88,19 @@ func (s Scheduler) historyJob(wg *sync.WaitGroup, exchange exchanges.Exchange, s
 		zap.Time("start", start),
 		zap.Time("end", end))
 
-	var dates []time.Time
-	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
-		exists, err := s.store.Exists(exchange, date)
-		if err != nil {
-			zap.L().Error("check exchange daily quote exists failed",
-				zap.Error(err),
-				zap.String("exchange", exchange.Code()),
-				zap.Time("date", date))
-		} else {
-			if !exists {
-				dates = append(dates, date)
-			}
-		}
+	dates, err := s.missingDates(ctx, exchange, start, end)
+	if err != nil {
+		return
+	}
+
+	err = s.crawl(ctx, "history", exchange, dates...)
+	if err != nil && ctx.Err() != nil {
+		zap.L().Info("exchange history job cancelled",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()))
+		return
 	}
 
-	err := s.crawl(exchange, dates...)
 	if err != nil {
 		err = utils.GetWeChatService().SendMessage(fmt.Sprintf("history job failed due to %s", err))
 		if err != nil {
@@ -94,8 +120,45 @@ func (s Scheduler) historyJob(wg *sync.WaitGroup, exchange exchanges.Exchange, s
 		zap.Time("end", end))
 }
 
+// Backfill crawl trading days not stored between start and end date (inclusive), days from today on are not finished and ignored
+func (s Scheduler) Backfill(ctx context.Context, exchange exchanges.Exchange, start, end time.Time) error {
+	yesterday := utils.YesterdayZero(time.Now().In(exchange.Location()))
+	if end.After(yesterday) {
+		end = yesterday
+	}
+
+	dates, err := s.missingDates(ctx, exchange, start, end)
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info("exchange backfill start",
+		zap.String("exchange", exchange.Code()),
+		zap.Time("start", start),
+		zap.Time("end", end),
+		zap.Int("dates", len(dates)))
+
+	return s.crawl(ctx, "backfill", exchange, dates...)
+}
+
+// missingDates list trading days not stored between start and end date (inclusive)
+// holidays out of calendar data are unknown, those days are crawled as trading days
+func (s Scheduler) missingDates(ctx context.Context, exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	calendar := exchanges.GetCalendar(exchange)
+	if !calendar.Covers(start) || !calendar.Covers(end) {
+		zap.L().Warn("exchange calendar data does not cover date range, holidays out of it are crawled as trading days",
+			zap.String("exchange", exchange.Code()),
+			zap.Time("start", start),
+			zap.Time("end", end))
+	}
+
+	return stores.MissingContext(ctx, s.store, exchange, calendar.TradingDays(start, end))
+}
+
 // dailyJob crawl exchange daily qoutes
-func (s Scheduler) dailyJob(wg *sync.WaitGroup, exchange exchanges.Exchange) {
+func (s Scheduler) dailyJob(ctx context.Context, wg *sync.WaitGroup, exchange exchanges.Exchange) {
+	defer wg.Done()
+
 	now := time.Now().In(exchange.Location())
 	duration2Tomorrow := utils.TomorrowZero(now).Sub(now)
 	zap.L().Info("exchange daily job start",
@@ -105,7 +168,14 @@ func (s Scheduler) dailyJob(wg *sync.WaitGroup, exchange exchanges.Exchange) {
 	var err error
 	for {
 		// wait for tomorrow zero clock
-		beforeCrawl := <-time.After(duration2Tomorrow)
+		var beforeCrawl time.Time
+		select {
+		case <-ctx.Done():
+			zap.L().Info("exchange daily job stopped", zap.String("exchange", exchange.Code()))
+			return
+		case beforeCrawl = <-time.After(duration2Tomorrow):
+		}
+
 		yesterday := utils.YesterdayZero(beforeCrawl.In(exchange.Location()))
 		zap.L().Info("exchange daily job start",
 			zap.String("exchange", exchange.Code()),
@@ -113,19 +183,26 @@ func (s Scheduler) dailyJob(wg *sync.WaitGroup, exchange exchanges.Exchange) {
 
 		for index := 0; index < constants.RetryCount; index++ {
 			// crawl
-			err = s.crawl(exchange, yesterday)
-			if err != nil && index < constants.RetryCount-1 {
+			err = s.crawl(ctx, "daily", exchange, yesterday)
+			if err != nil && ctx.Err() == nil && index < constants.RetryCount-1 {
 				zap.L().Warn("crawl exchange daily quote failed",
 					zap.Error(err),
 					zap.Duration("retry in", constants.RetryInterval),
 					zap.String("retries", fmt.Sprintf("%d/%d", index+1, constants.RetryCount)))
-				time.Sleep(constants.RetryInterval)
+				utils.Sleep(ctx, constants.RetryInterval)
 				continue
 			}
 
 			break
 		}
 
+		if ctx.Err() != nil {
+			zap.L().Info("exchange daily job stopped",
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", yesterday))
+			return
+		}
+
 		afterCrawl := time.Now().In(exchange.Location())
 		duration2Tomorrow = utils.TomorrowZero(afterCrawl).Sub(afterCrawl)
 		if err != nil {
@@ -150,8 +227,8 @@ func (s Scheduler) dailyJob(wg *sync.WaitGroup, exchange exchanges.Exchange) {
 	}
 }
 
-// crawl crawl exchange quotes in special days
-func (s Scheduler) crawl(exchange exchanges.Exchange, dates ...time.Time) error {
+// crawl crawl exchange quotes in special days, job is recorded as revision source
+func (s Scheduler) crawl(ctx context.Context, job string, exchange exchanges.Exchange, dates ...time.Time) error {
 	if len(dates) == 0 {
 		return nil
 	}
@@ -159,7 +236,7 @@ func (s Scheduler) crawl(exchange exchanges.Exchange, dates ...time.Time) error
 	zap.L().Debug("try to get exchange companies", zap.String("exchange", exchange.Code()))
 
 	// get companies
-	companies, err := exchange.Companies()
+	companies, err := exchanges.WithContext(exchange).CompaniesContext(ctx)
 	if err != nil {
 		zap.L().Error("get exchange companies failed",
 			zap.Error(err),
@@ -178,7 +255,7 @@ func (s Scheduler) crawl(exchange exchanges.Exchange, dates ...time.Time) error
 			Success:  true,
 		}
 
-		err = s.crawlOneDay(exchange, companies, date)
+		err = s.crawlOneDay(ctx, job, exchange, companies, date)
 		if err != nil {
 			zap.L().Error("crawl exchange companies failed",
 				zap.Error(err),
@@ -198,9 +275,10 @@ func (s Scheduler) crawl(exchange exchanges.Exchange, dates ...time.Time) error
 }
 
 // crawlOneDay crawl exchange quotes in special day
-func (s Scheduler) crawlOneDay(exchange exchanges.Exchange, companies map[string]*quotes.Company, date time.Time) error {
+func (s Scheduler) crawlOneDay(ctx context.Context, job string, exchange exchanges.Exchange, companies map[string]*quotes.Company, date time.Time) error {
 	// crawl
-	cdqs, err := s.crawlCompaniesDailyQuote(exchange, companies, date)
+	crawled := time.Now()
+	cdqs, failed, err := s.crawlCompaniesDailyQuote(ctx, exchange, companies, date)
 	if err != nil {
 		zap.L().Error("get exchange company quotes failed",
 			zap.Error(err),
@@ -209,9 +287,11 @@ func (s Scheduler) crawlOneDay(exchange exchanges.Exchange, companies map[string
 		return err
 	}
 
-	// make empty companies map if is not trading day
-	if len(cdqs) == 0 {
-		companies = make(map[string]*quotes.Company)
+	meta := quotes.NewMeta(constants.Version, crawled, time.Now(), len(companies), failed)
+	for code, cdq := range cdqs {
+		if cdq.Meta != nil {
+			meta.Companies[code] = cdq.Meta
+		}
 	}
 
 	edq := &quotes.ExchangeDailyQuote{
@@ -219,10 +299,16 @@ func (s Scheduler) crawlOneDay(exchange exchanges.Exchange, companies map[string
 		Date:      date,
 		Companies: companies,
 		Quotes:    cdqs,
+		Meta:      meta,
+	}
+
+	err = s.validateDay(exchange, date, edq)
+	if err != nil {
+		return err
 	}
 
 	// save
-	err = s.store.Save(exchange, date, edq)
+	err = s.save(ctx, job, exchange, date, edq, crawled)
 	if err != nil {
 		zap.L().Error("save exchange daily quote failed",
 			zap.Error(err),
@@ -236,30 +322,95 @@ func (s Scheduler) crawlOneDay(exchange exchanges.Exchange, companies map[string
 		zap.String("exchange", exchange.Code()),
 		zap.Time("date", date),
 		zap.Int("total companies", len(companies)),
-		zap.Int("valid companies", len(cdqs)))
+		zap.Int("valid companies", len(edq.Quotes)))
+
+	return nil
+}
+
+// validateDay drop bad bars and companies of crawled exchange daily quote, record quality summary in meta
+func (s Scheduler) validateDay(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	err := s.validator.ValidateDay(edq, exchanges.GetCalendar(exchange).SessionWindow(date))
+	if err != nil {
+		zap.L().Error("validate exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date),
+			zap.Any("quality", edq.Meta.Quality))
+		return err
+	}
+
+	// make empty companies map if is not trading day, or every company is dropped by validator
+	if len(edq.Quotes) == 0 {
+		edq.Companies = make(map[string]*quotes.Company)
+	}
+
+	if len(edq.Meta.Quality.Issues) > 0 {
+		zap.L().Warn("exchange daily quote has quality issues",
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date),
+			zap.Any("issues", edq.Meta.Quality.Issues),
+			zap.Int("dropped bars", edq.Meta.Quality.DroppedBars),
+			zap.Int("dropped companies", edq.Meta.Quality.DroppedCompanies))
+	}
 
 	return nil
 }
 
-// crawlCompaniesDailyQuote crawl company quotes in special day
-func (s Scheduler) crawlCompaniesDailyQuote(exchange exchanges.Exchange, companies map[string]*quotes.Company, date time.Time) (map[string]*quotes.CompanyDailyQuote, error) {
+// save save exchange daily quote, record a revision and log what changed if the store keeps revisions
+func (s Scheduler) save(ctx context.Context, job string, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote, crawled time.Time) error {
+	rs, ok := s.store.(stores.RevisionStore)
+	if !ok {
+		return stores.WithContext(s.store).SaveContext(ctx, exchange, date, edq)
+	}
+
+	revision, err := rs.SaveRevision(ctx, exchange, date, edq, crawled, job)
+	if err != nil {
+		return err
+	}
+
+	// first revision has nothing to compare, unchanged save returns the previous revision
+	if revision.Change == nil || !revision.Crawled.Equal(crawled) {
+		return nil
+	}
+
+	zap.L().Info("exchange daily quote revised",
+		zap.String("exchange", exchange.Code()),
+		zap.Time("date", date),
+		zap.Int("revision", revision.Number),
+		zap.Strings("added", revision.Change.Added),
+		zap.Strings("removed", revision.Change.Removed),
+		zap.Strings("changed", revision.Change.Changed),
+		zap.Int("bars", revision.Change.Bars))
+
+	return nil
+}
+
+// crawlCompaniesDailyQuote crawl company quotes in special day, return quotes and failed company count
+func (s Scheduler) crawlCompaniesDailyQuote(ctx context.Context, exchange exchanges.Exchange, companies map[string]*quotes.Company, date time.Time) (map[string]*quotes.CompanyDailyQuote, int, error) {
 	wg := new(sync.WaitGroup)
 	wg.Add(len(companies))
 
 	zap.S().Infow("companies daili", "exchange", exchange.Code(), "companies", len(companies), "date", date.Format("20060102"))
 	mutex := new(sync.Mutex)
 	cdqs := make(map[string]*quotes.CompanyDailyQuote, len(companies))
+	failed := 0
+	ce := exchanges.WithContext(exchange)
 	for _, company := range companies {
 		go func(_company *quotes.Company) {
-			cdq, err := exchange.Crawl(_company, date)
-			if err == nil && !cdq.IsEmpty() {
-				mutex.Lock()
+			crawlCtx, cancel := context.WithTimeout(ctx, constants.CrawlTimeout)
+			cdq, err := ce.CrawlContext(crawlCtx, _company, date)
+			cancel()
+
+			mutex.Lock()
+			if err != nil {
+				failed++
+			} else if cdq != nil && !cdq.IsEmpty() {
 				cdqs[_company.Code] = cdq
 				// if len(cdqs)%10 == 0 {
 				// 	zap.S().Infow("continue", "companies", len(cdqs), "total", len(companies))
 				// }
-				mutex.Unlock()
 			}
+			mutex.Unlock()
 
 			s.limiter.Release()
 			wg.Done()
@@ -269,5 +420,11 @@ func (s Scheduler) crawlCompaniesDailyQuote(exchange exchanges.Exchange, compani
 	}
 	wg.Wait()
 
-	return cdqs, nil
+	// partial quotes must not be saved
+	err := ctx.Err()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return cdqs, failed, nil
 }
@@ -0,0 +1,52 @@
+package schedulers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"github.com/nzai/qr/stores"
+)
+
+func TestScheduler_ValidateDayDropsEveryCompany(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	open := uint64(time.Date(2023, 3, 6, 9, 30, 0, 0, exchange.Location()).Unix())
+
+	company := &quotes.Company{Code: "AAPL", Name: "Apple Inc."}
+	edq := &quotes.ExchangeDailyQuote{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: map[string]*quotes.Company{company.Code: company},
+		Quotes: map[string]*quotes.CompanyDailyQuote{
+			// every bar is zero priced
+			company.Code: {
+				Company: company,
+				Pre:     &quotes.Serial{},
+				Regular: &quotes.Serial{{Timestamp: open, Open: 0, Close: 0, High: 0, Low: 0, Volume: 10}},
+				Post:    &quotes.Serial{},
+			},
+		},
+		Meta: quotes.NewMeta("v0.1.0", date, date, 1, 0),
+	}
+
+	store := stores.NewLevelDB(t.TempDir())
+	defer store.Close()
+
+	scheduler := NewScheduler(store, exchange)
+	err := scheduler.validateDay(exchange, date, edq)
+	if err != nil {
+		t.Fatalf("validateDay() error = %v", err)
+	}
+
+	if len(edq.Quotes) != 0 || len(edq.Companies) != 0 {
+		t.Fatalf("validateDay() kept %d quotes and %d companies, want none", len(edq.Quotes), len(edq.Companies))
+	}
+
+	// the day is saved as not trading day
+	err = store.Save(exchange, date, edq)
+	if err != nil {
+		t.Errorf("Save() error = %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package sources
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -24,28 +25,32 @@ func NewIFengFinance() *IFengFinance {
 	return &IFengFinance{pattern}
 }
 
-// QuerySplitAndDividend 查询拆股和除权
-func (s IFengFinance) QuerySplitAndDividend(company *quotes.Company, date time.Time) (*quotes.Dividend, *quotes.Split, error) {
+// QueryActions 查询分红送股
+func (s IFengFinance) QueryActions(company *quotes.Company, date time.Time) (quotes.Actions, error) {
+	return s.QueryActionsContext(context.Background(), company, date)
+}
+
+// QueryActionsContext 查询分红送股, context结束时取消
+func (s IFengFinance) QueryActionsContext(ctx context.Context, company *quotes.Company, date time.Time) (quotes.Actions, error) {
 	url := fmt.Sprintf("http://app.finance.ifeng.com/data/stock/tab_fhpxjl.php?symbol=%s", company.Code)
 
 	// 查询凤凰财经数据接口,返回分红配股信息
-	code, buffer, err := utils.TryDownloadBytes(url, constants.RetryCount, constants.RetryInterval)
+	code, buffer, err := utils.TryDownloadBytesContext(ctx, url, constants.RetryCount, constants.RetryInterval)
 	if err != nil {
 		zap.L().Warn("download dividend and split failed", zap.Error(err), zap.String("url", url))
-		return nil, nil, err
+		return nil, err
 	}
 
-	dividend := &quotes.Dividend{Enable: false, Timestamp: 0, Amount: 0}
-	split := &quotes.Split{Enable: false, Timestamp: 0, Numerator: 0, Denominator: 0}
+	var actions quotes.Actions
 
 	// ignore on server forbidden
 	if code == http.StatusForbidden {
-		return dividend, split, nil
+		return actions, nil
 	}
 
 	if code != http.StatusOK {
 		zap.L().Warn("unexpected response status", zap.Int("code", code))
-		return nil, nil, fmt.Errorf("unexpected response status (%d)%s", code, http.StatusText(code))
+		return nil, fmt.Errorf("unexpected response status (%d)%s", code, http.StatusText(code))
 	}
 
 	dateText := date.Format("2006-01-02")
@@ -54,7 +59,7 @@ func (s IFengFinance) QuerySplitAndDividend(company *quotes.Company, date time.T
 			zap.L().Warn("ifeng finance html match count invalid",
 				zap.Any("matches", matches),
 				zap.ByteString("html", buffer))
-			return nil, nil, fmt.Errorf("ifeng finance html match count invalid due to matches count: %d", len(matches))
+			return nil, fmt.Errorf("ifeng finance html match count invalid due to matches count: %d", len(matches))
 		}
 
 		// 只需要指定日期的
@@ -66,38 +71,42 @@ func (s IFengFinance) QuerySplitAndDividend(company *quotes.Company, date time.T
 		amount, err := strconv.ParseFloat(matches[1], 32)
 		if err != nil {
 			zap.L().Warn("ifeng finance dividend invalid", zap.String("dividend", matches[3]))
-			return nil, nil, fmt.Errorf("ifeng finance dividend invalid due to dividend: %s", matches[3])
+			return nil, fmt.Errorf("ifeng finance dividend invalid due to dividend: %s", matches[3])
 		}
 
 		if amount > 0 {
-			dividend.Enable = true
-			dividend.Timestamp = uint64(date.Unix())
-			dividend.Amount = float32(amount / 10) // A股都是按每十股计算
+			actions = append(actions, quotes.Action{
+				Type:      quotes.ActionCashDividend,
+				Timestamp: uint64(date.Unix()),
+				Amount:    float32(amount / 10), // A股都是按每十股计算
+			})
 		}
 
 		// 送股
 		numerator1, err := strconv.ParseFloat(matches[2], 32)
 		if err != nil {
 			zap.L().Warn("ifeng finance split numerator invalid", zap.String("numerator", matches[1]))
-			return nil, nil, fmt.Errorf("ifeng finance split numerator invalid due to numerator: %s", matches[1])
+			return nil, fmt.Errorf("ifeng finance split numerator invalid due to numerator: %s", matches[1])
 		}
 
 		// 转增
 		numerator2, err := strconv.ParseFloat(matches[3], 32)
 		if err != nil {
 			zap.L().Warn("ifeng finance split numerator invalid", zap.String("numerator", matches[2]))
-			return nil, nil, fmt.Errorf("ifeng finance split numerator invalid due to numerator: %s", matches[2])
+			return nil, fmt.Errorf("ifeng finance split numerator invalid due to numerator: %s", matches[2])
 		}
 
 		if numerator1+numerator2 > 0 {
-			split.Enable = true
-			split.Timestamp = uint64(date.Unix())
-			split.Numerator = float32(numerator1 + numerator2)
-			split.Denominator = 10 // A股都是按每十股计算
+			actions = append(actions, quotes.Action{
+				Type:        quotes.ActionStockDividend,
+				Timestamp:   uint64(date.Unix()),
+				Numerator:   float32(numerator1 + numerator2),
+				Denominator: 10, // A股都是按每十股计算
+			})
 		}
 
 		break
 	}
 
-	return dividend, split, nil
+	return actions, nil
 }
@@ -1,6 +1,7 @@
 package sources
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -18,12 +19,17 @@ func NewNasdaqSource() *NasdaqSource {
 }
 
 func (s NasdaqSource) Companies(exchange string) (map[string]*quotes.Company, error) {
+	return s.CompaniesContext(context.Background(), exchange)
+}
+
+// CompaniesContext get exchange companies until context done
+func (s NasdaqSource) CompaniesContext(ctx context.Context, exchange string) (map[string]*quotes.Company, error) {
 	url := fmt.Sprintf("https://api.nasdaq.com/api/screener/stocks?tableonly=true&limit=25&exchange=%s&download=true", strings.ToUpper(exchange))
 	headers := map[string]string{
 		"Accept":     "*/*",
 		"User-Agent": "PostmanRuntime/7.32.2",
 	}
-	_, content, err := utils.TryDownloadBytesWithHeader(url, headers, constants.RetryCount, constants.RetryInterval)
+	_, content, err := utils.TryDownloadBytesWithHeaderContext(ctx, url, headers, constants.RetryCount, constants.RetryInterval)
 	if err != nil {
 		zap.L().Error("download company symbol list failed", zap.Error(err), zap.String("url", url))
 		return nil, err
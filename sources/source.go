@@ -1,6 +1,7 @@
 package sources
 
 import (
+	"context"
 	"time"
 
 	"github.com/nzai/qr/quotes"
@@ -12,7 +13,61 @@ type Source interface {
 	Crawl(*quotes.Company, time.Time, string) (*quotes.CompanyDailyQuote, error)
 }
 
-// SplitDividendSource define company daily split and dividend source
-type SplitDividendSource interface {
-	QuerySplitAndDividend(*quotes.Company, time.Time) (*quotes.Dividend, *quotes.Split, error)
+// ContextSource define company daily quote source can be cancelled by context
+type ContextSource interface {
+	Source
+	// CrawlContext crawl company daily quote until context done
+	CrawlContext(context.Context, *quotes.Company, time.Time, string) (*quotes.CompanyDailyQuote, error)
+}
+
+// ActionSource define company daily corporate action source
+type ActionSource interface {
+	QueryActions(*quotes.Company, time.Time) (quotes.Actions, error)
+}
+
+// ContextActionSource define company daily corporate action source can be cancelled by context
+type ContextActionSource interface {
+	ActionSource
+	// QueryActionsContext query company daily corporate actions until context done
+	QueryActionsContext(context.Context, *quotes.Company, time.Time) (quotes.Actions, error)
+}
+
+// WithContext adapt source to ContextSource, use native implement if source is a ContextSource
+func WithContext(source Source) ContextSource {
+	if cs, ok := source.(ContextSource); ok {
+		return cs
+	}
+
+	return contextSource{source}
+}
+
+// contextSource adapt Source to ContextSource, give up waiting the crawl when context done
+type contextSource struct {
+	Source
+}
+
+// CrawlContext crawl company daily quote until context done
+func (s contextSource) CrawlContext(ctx context.Context, company *quotes.Company, date time.Time, suffix string) (*quotes.CompanyDailyQuote, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		cdq *quotes.CompanyDailyQuote
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		cdq, err := s.Crawl(company, date, suffix)
+		done <- result{cdq, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.cdq, r.err
+	}
 }
@@ -1,6 +1,7 @@
 package sources
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -22,13 +23,18 @@ func NewYahooFinance() *YahooFinance {
 
 // Crawl crawl company daily quote
 func (yahoo YahooFinance) Crawl(company *quotes.Company, date time.Time, suffix string) (*quotes.CompanyDailyQuote, error) {
+	return yahoo.CrawlContext(context.Background(), company, date, suffix)
+}
+
+// CrawlContext crawl company daily quote until context done
+func (yahoo YahooFinance) CrawlContext(ctx context.Context, company *quotes.Company, date time.Time, suffix string) (*quotes.CompanyDailyQuote, error) {
 	tomorrow := date.AddDate(0, 0, 1)
 	symbol := company.Code + suffix
 	pattern := "https://query2.finance.yahoo.com/v8/finance/chart/%s?symbol=%s&period1=%d&period2=%d&interval=1m&includePrePost=true&events=div|split|earn&corsDomain=finance.yahoo.com"
 	url := fmt.Sprintf(pattern, symbol, symbol, date.Unix(), tomorrow.Unix())
 
 	// query quote date from yahoo api
-	code, buffer, err := utils.TryDownloadBytes(url, constants.RetryCount, constants.RetryInterval)
+	code, buffer, err := utils.TryDownloadBytesContext(ctx, url, constants.RetryCount, constants.RetryInterval)
 	if err != nil {
 		// zap.L().Warn("download yahoo finance quote failed", zap.Error(err), zap.String("url", url))
 		return nil, err
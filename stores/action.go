@@ -0,0 +1,63 @@
+package stores
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nzai/qr/quotes"
+)
+
+// formatActions format corporate actions to key value store text
+// value: {type},{timestamp},{amount},{numerator},{denominator};...
+func formatActions(actions quotes.Actions) string {
+	values := make([]string, len(actions))
+	for index, action := range actions {
+		values[index] = fmt.Sprintf("%s,%d,%v,%v,%v", action.Type, action.Timestamp, action.Amount, action.Numerator, action.Denominator)
+	}
+
+	return strings.Join(values, ";")
+}
+
+// parseActions parse corporate actions from key value store text
+func parseActions(value string) (quotes.Actions, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var actions quotes.Actions
+	for _, text := range strings.Split(value, ";") {
+		parts := strings.SplitN(text, ",", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("action invalid: %s", text)
+		}
+
+		actionType, err := quotes.ParseActionType(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		action := quotes.Action{Type: actionType}
+		_, err = fmt.Sscanf(parts[1], "%d,%f,%f,%f", &action.Timestamp, &action.Amount, &action.Numerator, &action.Denominator)
+		if err != nil {
+			return nil, fmt.Errorf("action %s invalid due to %v", text, err)
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+// mergeActions merge corporate actions saved as legacy dividend and split into actions order
+func mergeActions(actions quotes.Actions, dividend *quotes.Dividend, split *quotes.Split) quotes.Actions {
+	legacy := quotes.NewActions(dividend, split)
+	if len(legacy) == 0 {
+		return actions
+	}
+
+	actions = append(actions, legacy...)
+	sort.Stable(actions)
+
+	return actions
+}
@@ -0,0 +1,170 @@
+package stores
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestActions(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	timestamp := uint64(date.Unix())
+
+	leveldb := NewLevelDB(t.TempDir())
+	defer leveldb.Close()
+
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sqlite.Close()
+
+	_stores := map[string]Store{
+		"fs":      NewFileSystem(t.TempDir()),
+		"leveldb": leveldb,
+		"sqlite":  sqlite,
+		"memory":  NewObject(NewMemoryBlob(), DefaultFormat),
+	}
+
+	for name, store := range _stores {
+		t.Run(name, func(t *testing.T) {
+			edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+			edq.Quotes["AAPL"].Actions = quotes.Actions{
+				{Type: quotes.ActionCashDividend, Timestamp: timestamp, Amount: 0.05},
+				{Type: quotes.ActionCashDividend, Timestamp: timestamp, Amount: 0.125},
+				{Type: quotes.ActionStockDividend, Timestamp: timestamp, Numerator: 3, Denominator: 10},
+				{Type: quotes.ActionRightsIssue, Timestamp: timestamp + 60, Amount: 7.5, Numerator: 2, Denominator: 10},
+			}
+			edq.Quotes["MSFT"].Actions = quotes.Actions{{Type: quotes.ActionSplit, Timestamp: timestamp, Numerator: 4, Denominator: 1}}
+
+			err := store.Save(exchange, date, edq)
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			loaded, err := store.Load(exchange, date)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			err = edq.Equal(*loaded)
+			if err != nil {
+				t.Errorf("Load() not equal: %v", err)
+			}
+
+			cdqs, err := LoadCompany(store, exchange, "AAPL", date, date)
+			if err != nil {
+				t.Fatalf("LoadCompany() error = %v", err)
+			}
+
+			if len(cdqs) != 1 || len(cdqs[0].Actions) != 4 {
+				t.Fatalf("LoadCompany() = %+v, want 4 actions", cdqs)
+			}
+		})
+	}
+}
+
+func TestLevelDB_LegacyActions(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	timestamp := uint64(date.Unix())
+
+	store := NewLevelDB(t.TempDir())
+	defer store.Close()
+
+	err := store.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// dividend and split keys written before actions
+	dateText := date.Format(constants.DatePattern)
+	err = store.db.Put([]byte(fmt.Sprintf("%s:AAPL:dividend:%s", exchange.Code(), dateText)), []byte(fmt.Sprintf("%d,%f", timestamp, 0.23)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.db.Put([]byte(fmt.Sprintf("%s:AAPL:split:%s", exchange.Code(), dateText)), []byte(fmt.Sprintf("%d,%f,%f", timestamp, 4.0, 1.0)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load(exchange, date)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := quotes.Actions{
+		{Type: quotes.ActionCashDividend, Timestamp: timestamp, Amount: 0.23},
+		{Type: quotes.ActionSplit, Timestamp: timestamp, Numerator: 4, Denominator: 1},
+	}
+
+	err = loaded.Quotes["AAPL"].Actions.Equal(want)
+	if err != nil {
+		t.Errorf("Load() legacy actions not equal: %v", err)
+	}
+
+	// deleting the day removes legacy keys too
+	err = store.Delete(exchange, date)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	_, err = store.db.Get([]byte(fmt.Sprintf("%s:AAPL:dividend:%s", exchange.Code(), dateText)), nil)
+	if err == nil {
+		t.Errorf("Delete() kept legacy dividend key")
+	}
+}
+
+func TestLevelDB_SaveLegacyActionsAgain(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	timestamp := uint64(date.Unix())
+
+	store := NewLevelDB(t.TempDir())
+	defer store.Close()
+
+	err := store.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// day stored with a dividend key written before actions
+	dividendKey := []byte(fmt.Sprintf("%s:AAPL:dividend:%s", exchange.Code(), date.Format(constants.DatePattern)))
+	err = store.db.Put(dividendKey, []byte(fmt.Sprintf("%d,%f", timestamp, 0.23)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load(exchange, date)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// save the loaded day again, the dividend is written as action and must not be merged twice
+	err = store.Save(exchange, date, loaded)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	saved, err := store.Load(exchange, date)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	err = saved.Quotes["AAPL"].Actions.Equal(quotes.Actions{{Type: quotes.ActionCashDividend, Timestamp: timestamp, Amount: 0.23}})
+	if err != nil {
+		t.Errorf("Load() actions not equal: %v", err)
+	}
+
+	_, err = store.db.Get(dividendKey, nil)
+	if err == nil {
+		t.Errorf("Save() kept legacy dividend key")
+	}
+}
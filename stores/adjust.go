@@ -0,0 +1,45 @@
+package stores
+
+import (
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// splitConventions split convention of exchanges whose splits are not crawled from yahoo
+// sse and szse bonus shares from ifeng finance were stored as splits before stock dividend action
+var splitConventions = map[string]quotes.SplitConvention{
+	"Sse":  quotes.SplitBonus,
+	"Szse": quotes.SplitBonus,
+}
+
+// SplitConvention get split convention of stored exchange splits
+func SplitConvention(exchange exchanges.Exchange) quotes.SplitConvention {
+	convention, found := splitConventions[exchange.Code()]
+	if !found {
+		return quotes.SplitRatio
+	}
+
+	return convention
+}
+
+// LoadAdjusted load company daily quotes between start and end date from any store and adjust them by dividends and splits
+// only dividends and splits between start and end date are applied
+func LoadAdjusted(store Store, exchange exchanges.Exchange, companyCode string, start, end time.Time, mode quotes.AdjustMode) ([]*quotes.CompanyDailyQuote, *quotes.Adjustment, error) {
+	cdqs, err := LoadCompany(store, exchange, companyCode, start, end)
+	if err != nil {
+		zap.L().Error("load company daily quotes failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, nil, err
+	}
+
+	adjustment := quotes.NewAdjustment(cdqs, mode, SplitConvention(exchange))
+
+	return adjustment.Apply(cdqs), adjustment, nil
+}
@@ -0,0 +1,391 @@
+package stores
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"path"
+	"time"
+
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// archive layout, a tar stream of
+//
+//	archive.json					archive header, always the first entry
+//	days/{exchange}/{20060102}.qr	exchange daily quote in file format, one entry per stored day
+//	index.json						entry list with size and checksum, always the last entry
+//
+// days are written in file format so every day carries its own version, codec and payload checksum.
+const (
+	// ArchiveVersion current version of archive layout
+	ArchiveVersion = 1
+
+	archiveHeaderName = "archive.json"
+	archiveIndexName  = "index.json"
+)
+
+// ArchiveHeader define archive description
+type ArchiveHeader struct {
+	Version     int       `json:"version"`
+	Software    string    `json:"software,omitempty"`
+	Created     time.Time `json:"created"`
+	Compression string    `json:"compression"`
+	Codec       string    `json:"codec"`
+}
+
+// ArchiveEntry define one archived exchange daily quote
+type ArchiveEntry struct {
+	Exchange  string `json:"exchange"`
+	Date      string `json:"date"`
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	Checksum  string `json:"checksum"`
+	Companies int    `json:"companies"`
+	Quotes    int    `json:"quotes"`
+}
+
+// ArchiveIndex define archived entries in archive order
+type ArchiveIndex struct {
+	Entries []*ArchiveEntry `json:"entries"`
+}
+
+// archiveDate format exchange daily quote date in exchange location
+func archiveDate(edq *quotes.ExchangeDailyQuote) string {
+	exchange, found := exchanges.Get(edq.Exchange)
+	if !found {
+		return edq.Date.Format(constants.DatePattern)
+	}
+
+	return edq.Date.In(exchange.Location()).Format(constants.DatePattern)
+}
+
+// ArchiveWriter write exchange daily quotes into archive
+type ArchiveWriter struct {
+	tw     *tar.Writer
+	format Format
+	index  *ArchiveIndex
+}
+
+// NewArchiveWriter create archive writer and write archive header, days are encoded in format
+func NewArchiveWriter(w io.Writer, format Format) (*ArchiveWriter, error) {
+	aw := &ArchiveWriter{tw: tar.NewWriter(w), format: format, index: new(ArchiveIndex)}
+
+	err := aw.writeJSON(archiveHeaderName, &ArchiveHeader{
+		Version:     ArchiveVersion,
+		Software:    constants.Version,
+		Created:     time.Now(),
+		Compression: format.Compression.String(),
+		Codec:       format.Codec.String(),
+	})
+	if err != nil {
+		zap.L().Error("write archive header failed", zap.Error(err))
+		return nil, err
+	}
+
+	return aw, nil
+}
+
+// WriteDay append exchange daily quote to archive
+func (w *ArchiveWriter) WriteDay(edq *quotes.ExchangeDailyQuote) error {
+	buffer := new(bytes.Buffer)
+	err := encodeFormat(buffer, edq, w.format)
+	if err != nil {
+		return err
+	}
+
+	entry := &ArchiveEntry{
+		Exchange:  edq.Exchange,
+		Date:      archiveDate(edq),
+		Size:      int64(buffer.Len()),
+		Checksum:  fmt.Sprintf("%08x", crc32.ChecksumIEEE(buffer.Bytes())),
+		Companies: len(edq.Companies),
+		Quotes:    len(edq.Quotes),
+	}
+	entry.Name = path.Join("days", entry.Exchange, entry.Date+".qr")
+
+	err = w.writeFile(entry.Name, buffer.Bytes())
+	if err != nil {
+		zap.L().Error("write archive day failed", zap.Error(err), zap.String("name", entry.Name))
+		return err
+	}
+
+	w.index.Entries = append(w.index.Entries, entry)
+
+	return nil
+}
+
+// Index get entries written so far
+func (w *ArchiveWriter) Index() *ArchiveIndex {
+	return w.index
+}
+
+// Close write archive index and finish tar stream, underlying writer is not closed
+func (w *ArchiveWriter) Close() error {
+	err := w.writeJSON(archiveIndexName, w.index)
+	if err != nil {
+		zap.L().Error("write archive index failed", zap.Error(err))
+		return err
+	}
+
+	return w.tw.Close()
+}
+
+func (w *ArchiveWriter) writeJSON(name string, v interface{}) error {
+	buffer, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return w.writeFile(name, buffer)
+}
+
+func (w *ArchiveWriter) writeFile(name string, data []byte) error {
+	err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.tw.Write(data)
+	return err
+}
+
+// ArchiveReader read exchange daily quotes from archive
+type ArchiveReader struct {
+	tr     *tar.Reader
+	Header *ArchiveHeader
+	read   []*ArchiveEntry
+}
+
+// NewArchiveReader create archive reader and read archive header
+func NewArchiveReader(r io.Reader) (*ArchiveReader, error) {
+	ar := &ArchiveReader{tr: tar.NewReader(r)}
+
+	th, err := ar.tr.Next()
+	if err != nil {
+		zap.L().Error("read archive header failed", zap.Error(err))
+		return nil, err
+	}
+
+	if th.Name != archiveHeaderName {
+		return nil, fmt.Errorf("archive header not found, first entry is %s", th.Name)
+	}
+
+	ar.Header = new(ArchiveHeader)
+	err = json.NewDecoder(ar.tr).Decode(ar.Header)
+	if err != nil {
+		zap.L().Error("decode archive header failed", zap.Error(err))
+		return nil, err
+	}
+
+	if ar.Header.Version > ArchiveVersion {
+		return nil, fmt.Errorf("archive version %d is not supported, latest is %d", ar.Header.Version, ArchiveVersion)
+	}
+
+	return ar, nil
+}
+
+// Next read next exchange daily quote
+// return io.EOF at the end of archive, after entries read are verified against archive index
+func (r *ArchiveReader) Next() (*ArchiveEntry, *quotes.ExchangeDailyQuote, error) {
+	th, err := r.tr.Next()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("archive index not found, archive is truncated")
+	}
+
+	if err != nil {
+		zap.L().Error("read archive entry failed", zap.Error(err))
+		return nil, nil, err
+	}
+
+	if th.Name == archiveIndexName {
+		err = r.verifyIndex()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, io.EOF
+	}
+
+	data, err := io.ReadAll(r.tr)
+	if err != nil {
+		zap.L().Error("read archive entry failed", zap.Error(err), zap.String("name", th.Name))
+		return nil, nil, err
+	}
+
+	edq, err := decodeFormat(bytes.NewReader(data))
+	if err != nil {
+		zap.L().Error("decode archive entry failed", zap.Error(err), zap.String("name", th.Name))
+		return nil, nil, fmt.Errorf("decode archive entry %s failed: %w", th.Name, err)
+	}
+
+	entry := &ArchiveEntry{
+		Exchange:  edq.Exchange,
+		Date:      archiveDate(edq),
+		Name:      th.Name,
+		Size:      int64(len(data)),
+		Checksum:  fmt.Sprintf("%08x", crc32.ChecksumIEEE(data)),
+		Companies: len(edq.Companies),
+		Quotes:    len(edq.Quotes),
+	}
+	r.read = append(r.read, entry)
+
+	return entry, edq, nil
+}
+
+// verifyIndex check entries read are exactly the entries in archive index
+func (r *ArchiveReader) verifyIndex() error {
+	index := new(ArchiveIndex)
+	err := json.NewDecoder(r.tr).Decode(index)
+	if err != nil {
+		zap.L().Error("decode archive index failed", zap.Error(err))
+		return err
+	}
+
+	if len(index.Entries) != len(r.read) {
+		return fmt.Errorf("archive index has %d entries, but %d read", len(index.Entries), len(r.read))
+	}
+
+	for i, want := range index.Entries {
+		if *want != *r.read[i] {
+			return fmt.Errorf("archive entry %s is different from index: %+v, want %+v", want.Name, *r.read[i], *want)
+		}
+	}
+
+	return nil
+}
+
+// Backup write stored days of exchange between start and end date into archive, return days written
+func Backup(ctx context.Context, store Store, w *ArchiveWriter, exchange exchanges.Exchange, start, end time.Time) (int, error) {
+	dates, err := Dates(store, exchange, start, end)
+	if err != nil {
+		zap.L().Error("list stored dates failed", zap.Error(err), zap.String("exchange", exchange.Code()))
+		return 0, err
+	}
+
+	for index, date := range dates {
+		err = ctx.Err()
+		if err != nil {
+			return index, err
+		}
+
+		edq, err := store.Load(exchange, date)
+		if err != nil {
+			zap.L().Error("load exchange daily quote failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return index, err
+		}
+
+		err = w.WriteDay(edq)
+		if err != nil {
+			return index, err
+		}
+	}
+
+	return len(dates), nil
+}
+
+// VerifyArchive read the whole archive, check every day by its payload checksum and entries against archive index,
+// return archive header and days archived
+func VerifyArchive(ctx context.Context, r io.Reader) (*ArchiveHeader, int, error) {
+	ar, err := NewArchiveReader(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var days int
+	for {
+		err = ctx.Err()
+		if err != nil {
+			return nil, days, err
+		}
+
+		_, _, err = ar.Next()
+		if err == io.EOF {
+			return ar.Header, days, nil
+		}
+
+		if err != nil {
+			return nil, days, err
+		}
+
+		days++
+	}
+}
+
+// Restore save every archived day into store, return archive header and days restored
+// the whole archive is verified first and nothing is saved if it is broken, then it is read again from the start to save days
+func Restore(ctx context.Context, rs io.ReadSeeker, store Store) (*ArchiveHeader, int, error) {
+	offset, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		zap.L().Error("get archive offset failed", zap.Error(err))
+		return nil, 0, err
+	}
+
+	_, days, err := VerifyArchive(ctx, bufio.NewReader(rs))
+	if err != nil {
+		zap.L().Error("verify archive failed", zap.Error(err), zap.Int("days", days))
+		return nil, 0, err
+	}
+
+	_, err = rs.Seek(offset, io.SeekStart)
+	if err != nil {
+		zap.L().Error("seek archive failed", zap.Error(err), zap.Int64("offset", offset))
+		return nil, 0, err
+	}
+
+	ar, err := NewArchiveReader(bufio.NewReader(rs))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var restored int
+	for {
+		err = ctx.Err()
+		if err != nil {
+			return ar.Header, restored, err
+		}
+
+		entry, edq, err := ar.Next()
+		if err == io.EOF {
+			return ar.Header, restored, nil
+		}
+
+		if err != nil {
+			return ar.Header, restored, err
+		}
+
+		exchange, found := exchanges.Get(entry.Exchange)
+		if !found {
+			return ar.Header, restored, fmt.Errorf("archive entry %s has unknown exchange %s", entry.Name, entry.Exchange)
+		}
+
+		date := edq.Date.In(exchange.Location())
+		err = store.Save(exchange, date, edq)
+		if err != nil {
+			zap.L().Error("save exchange daily quote failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return ar.Header, restored, err
+		}
+
+		restored++
+	}
+}
@@ -0,0 +1,99 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestArchive(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	start := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	end := start.AddDate(0, 0, 2)
+
+	source := NewFileSystem(t.TempDir())
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+		edq.Meta = quotes.NewMeta("v1.2.3", date.UTC(), date.UTC(), 2, 0)
+
+		err := source.Save(exchange, date, edq)
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	buffer := new(bytes.Buffer)
+	aw, err := NewArchiveWriter(buffer, DefaultFormat)
+	if err != nil {
+		t.Fatalf("NewArchiveWriter() error = %v", err)
+	}
+
+	days, err := Backup(context.Background(), source, aw, exchange, start, end)
+	if err != nil || days != 3 {
+		t.Fatalf("Backup() = %d, %v, want 3", days, err)
+	}
+
+	err = aw.Close()
+	if err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	target, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer target.Close()
+
+	header, days, err := Restore(context.Background(), bytes.NewReader(buffer.Bytes()), target)
+	if err != nil || days != 3 || header.Version != ArchiveVersion {
+		t.Fatalf("Restore() = %d, %v, want 3", days, err)
+	}
+
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		restored, err := target.Load(exchange, date)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+
+		err = testExchangeDailyQuote(exchange, date, "AAPL", "MSFT").Equal(*restored)
+		if err != nil {
+			t.Errorf("Load() restored not equal: %v", err)
+		}
+
+		if restored.Meta == nil || restored.Meta.Software != "v1.2.3" {
+			t.Errorf("Load() restored meta = %+v", restored.Meta)
+		}
+	}
+
+	// truncated archive misses index, nothing is restored
+	empty := NewObject(NewMemoryBlob(), DefaultFormat)
+	_, days, err = Restore(context.Background(), bytes.NewReader(buffer.Bytes()[:buffer.Len()-2048]), empty)
+	if err == nil || days != 0 {
+		t.Errorf("Restore() truncated archive = %d, %v, want error", days, err)
+	}
+
+	dates, err := empty.Dates(exchange, start, end)
+	if err != nil || len(dates) != 0 {
+		t.Errorf("Dates() after truncated restore = %v, %v, want none", dates, err)
+	}
+
+	// corrupted day payload, nothing is restored
+	data := append([]byte(nil), buffer.Bytes()...)
+	// payload follows the 512 bytes tar header of the last day, the name also appears in index later
+	index := bytes.Index(data, []byte("days/Nasdaq/20230308.qr"))
+	data[index+512+64]++
+	_, _, err = Restore(context.Background(), bytes.NewReader(data), empty)
+	if err == nil {
+		t.Errorf("Restore() corrupted archive error = nil")
+	}
+
+	dates, err = empty.Dates(exchange, start, end)
+	if err != nil || len(dates) != 0 {
+		t.Errorf("Dates() after corrupted restore = %v, %v, want none", dates, err)
+	}
+}
@@ -0,0 +1,236 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBlobNotFound object not found in blob
+var ErrBlobNotFound = errors.New("blob not found")
+
+// ErrBlobConflict object was changed since it was read
+var ErrBlobConflict = errors.New("blob changed by others")
+
+// Blob define object storage, keys are slash separated paths like 2006/01/02/Nasdaq
+type Blob interface {
+	// Put put object, replace existing one
+	Put(ctx context.Context, key string, data []byte) error
+	// Get get object reader, close it after read, return ErrBlobNotFound if object not exists
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Head get object info, return ErrBlobNotFound if object not exists
+	Head(ctx context.Context, key string) (*BlobInfo, error)
+	// List list objects which key has prefix, ordered by key
+	List(ctx context.Context, prefix string) ([]*BlobInfo, error)
+	// Delete delete object, delete not exists object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// BlobInfo define object info, etag changes whenever object is replaced
+type BlobInfo struct {
+	Key      string
+	Size     int64
+	Modified time.Time
+	ETag     string
+}
+
+// ConditionalBlob define blob supports conditional put
+type ConditionalBlob interface {
+	// PutIf put object only if its etag is still etag, empty etag means object must not exist,
+	// return etag of the new object, or ErrBlobConflict if object was changed
+	PutIf(ctx context.Context, key string, data []byte, etag string) (string, error)
+}
+
+// PutIf put object only if its etag is still etag
+// use blob native implement if it is a ConditionalBlob, otherwise compare etag by Head before Put,
+// which still leaves a short window for concurrent writers
+func PutIf(ctx context.Context, blob Blob, key string, data []byte, etag string) (string, error) {
+	conditional, ok := blob.(ConditionalBlob)
+	if ok {
+		return conditional.PutIf(ctx, key, data, etag)
+	}
+
+	current, err := headETag(ctx, blob, key)
+	if err != nil {
+		return "", err
+	}
+
+	if current != etag {
+		return "", ErrBlobConflict
+	}
+
+	err = blob.Put(ctx, key, data)
+	if err != nil {
+		return "", err
+	}
+
+	// etag of the put object is unknown, others may have replaced it already
+	return "", nil
+}
+
+// headETag get object etag, return empty string if object not exists
+func headETag(ctx context.Context, blob Blob, key string) (string, error) {
+	info, err := blob.Head(ctx, key)
+	if err == ErrBlobNotFound {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return info.ETag, nil
+}
+
+// MemoryBlob in-memory blob, for tests and temporary stores
+type MemoryBlob struct {
+	mutex   *sync.RWMutex
+	objects map[string]*memoryObject
+}
+
+type memoryObject struct {
+	data     []byte
+	modified time.Time
+	etag     string
+}
+
+func newMemoryObject(data []byte) *memoryObject {
+	modified := time.Now()
+	return &memoryObject{
+		data:     append([]byte(nil), data...),
+		modified: modified,
+		etag:     fmt.Sprintf("%08x-%x", crc32.ChecksumIEEE(data), modified.UnixNano()),
+	}
+}
+
+func (o memoryObject) info(key string) *BlobInfo {
+	return &BlobInfo{Key: key, Size: int64(len(o.data)), Modified: o.modified, ETag: o.etag}
+}
+
+// NewMemoryBlob create in-memory blob
+func NewMemoryBlob() *MemoryBlob {
+	return &MemoryBlob{
+		mutex:   new(sync.RWMutex),
+		objects: make(map[string]*memoryObject),
+	}
+}
+
+// Put put object, replace existing one
+func (b MemoryBlob) Put(ctx context.Context, key string, data []byte) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.objects[key] = newMemoryObject(data)
+
+	return nil
+}
+
+// PutIf put object only if its etag is still etag
+func (b MemoryBlob) PutIf(ctx context.Context, key string, data []byte, etag string) (string, error) {
+	err := ctx.Err()
+	if err != nil {
+		return "", err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	current := ""
+	if object, found := b.objects[key]; found {
+		current = object.etag
+	}
+
+	if current != etag {
+		return "", ErrBlobConflict
+	}
+
+	object := newMemoryObject(data)
+	b.objects[key] = object
+
+	return object.etag, nil
+}
+
+// Get get object reader
+func (b MemoryBlob) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	object, found := b.objects[key]
+	if !found {
+		return nil, ErrBlobNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(object.data)), nil
+}
+
+// Head get object info
+func (b MemoryBlob) Head(ctx context.Context, key string) (*BlobInfo, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	object, found := b.objects[key]
+	if !found {
+		return nil, ErrBlobNotFound
+	}
+
+	return object.info(key), nil
+}
+
+// List list objects which key has prefix, ordered by key
+func (b MemoryBlob) List(ctx context.Context, prefix string) ([]*BlobInfo, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	var infos []*BlobInfo
+	for key, object := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, object.info(key))
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+
+	return infos, nil
+}
+
+// Delete delete object
+func (b MemoryBlob) Delete(ctx context.Context, key string) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.objects, key)
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mozillazg/go-cos"
+	"go.uber.org/zap"
+)
+
+// CosBlob tencent cos blob
+type CosBlob struct {
+	client *cos.Client
+}
+
+// NewCosBlob create tencent cos blob
+func NewCosBlob(client *cos.Client) *CosBlob {
+	return &CosBlob{client: client}
+}
+
+// isNotFound check cos error is object not found
+func (b CosBlob) isNotFound(err error) bool {
+	var er *cos.ErrorResponse
+	return errors.As(err, &er) && er.Response != nil && er.Response.StatusCode == http.StatusNotFound
+}
+
+// Put put object
+func (b CosBlob) Put(ctx context.Context, key string, data []byte) error {
+	_, err := b.client.Object.Put(ctx, key, bytes.NewReader(data), nil)
+	if err != nil {
+		zap.L().Error("put object failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	return nil
+}
+
+// Get get object reader
+func (b CosBlob) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	response, err := b.client.Object.Get(ctx, key, nil)
+	if b.isNotFound(err) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		zap.L().Error("get object failed", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+// Head get object info
+func (b CosBlob) Head(ctx context.Context, key string) (*BlobInfo, error) {
+	response, err := b.client.Object.Head(ctx, key, nil)
+	if b.isNotFound(err) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		zap.L().Error("head object failed", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	modified, _ := http.ParseTime(response.Header.Get("Last-Modified"))
+
+	return &BlobInfo{Key: key, Size: response.ContentLength, Modified: modified, ETag: response.Header.Get("ETag")}, nil
+}
+
+// List list objects which key has prefix, ordered by key
+func (b CosBlob) List(ctx context.Context, prefix string) ([]*BlobInfo, error) {
+	var infos []*BlobInfo
+	marker := ""
+	for {
+		result, _, err := b.client.Bucket.Get(ctx, &cos.BucketGetOptions{
+			Prefix: prefix,
+			Marker: marker,
+		})
+		if err != nil {
+			zap.L().Error("list objects failed", zap.Error(err), zap.String("prefix", prefix), zap.String("marker", marker))
+			return nil, err
+		}
+
+		for _, object := range result.Contents {
+			modified, _ := time.Parse(time.RFC3339, object.LastModified)
+			infos = append(infos, &BlobInfo{Key: object.Key, Size: int64(object.Size), Modified: modified, ETag: object.ETag})
+		}
+
+		if !result.IsTruncated {
+			break
+		}
+
+		marker = result.NextMarker
+		if marker == "" && len(result.Contents) > 0 {
+			marker = result.Contents[len(result.Contents)-1].Key
+		}
+	}
+
+	return infos, nil
+}
+
+// Delete delete object
+func (b CosBlob) Delete(ctx context.Context, key string) error {
+	_, err := b.client.Object.Delete(ctx, key)
+	if err != nil {
+		zap.L().Error("delete object failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	return nil
+}
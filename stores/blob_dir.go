@@ -0,0 +1,195 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// DirBlob local directory blob, every object is a file under root
+type DirBlob struct {
+	root string
+}
+
+// NewDirBlob create local directory blob
+func NewDirBlob(root string) *DirBlob {
+	return &DirBlob{root: root}
+}
+
+// objectPath return file path of object key
+func (b DirBlob) objectPath(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(key))
+}
+
+// Put put object, write to temporary file then rename, so readers never see partial object
+func (b DirBlob) Put(ctx context.Context, key string, data []byte) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	filePath := b.objectPath(key)
+	err = os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		zap.L().Error("create blob dir failed", zap.Error(err), zap.String("path", filePath))
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		zap.L().Error("create blob temp file failed", zap.Error(err), zap.String("path", filePath))
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if err != nil {
+		file.Close()
+		zap.L().Error("write blob temp file failed", zap.Error(err), zap.String("path", file.Name()))
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		zap.L().Error("close blob temp file failed", zap.Error(err), zap.String("path", file.Name()))
+		return err
+	}
+
+	err = os.Rename(file.Name(), filePath)
+	if err != nil {
+		zap.L().Error("rename blob temp file failed", zap.Error(err), zap.String("path", filePath))
+		return err
+	}
+
+	return nil
+}
+
+// Get get object reader
+func (b DirBlob) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(b.objectPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		zap.L().Error("open blob file failed", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Head get object info
+func (b DirBlob) Head(ctx context.Context, key string) (*BlobInfo, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(b.objectPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		zap.L().Error("stat blob file failed", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	return fileInfo(key, fi), nil
+}
+
+// fileInfo create object info from file info, etag is made of size and modify time
+func fileInfo(key string, fi fs.FileInfo) *BlobInfo {
+	return &BlobInfo{
+		Key:      key,
+		Size:     fi.Size(),
+		Modified: fi.ModTime(),
+		ETag:     fmt.Sprintf("%x-%x", fi.Size(), fi.ModTime().UnixNano()),
+	}
+}
+
+// List list objects which key has prefix, ordered by key
+func (b DirBlob) List(ctx context.Context, prefix string) ([]*BlobInfo, error) {
+	// only walk the deepest directory of prefix
+	dir := b.root
+	if index := strings.LastIndex(prefix, "/"); index >= 0 {
+		dir = b.objectPath(prefix[:index])
+	}
+
+	var infos []*BlobInfo
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		err = ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			return nil
+		}
+
+		relative, err := filepath.Rel(b.root, filePath)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		fi, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		infos = append(infos, fileInfo(key, fi))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		zap.L().Error("list blob files failed", zap.Error(err), zap.String("prefix", prefix))
+		return nil, err
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+
+	return infos, nil
+}
+
+// Delete delete object
+func (b DirBlob) Delete(ctx context.Context, key string) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(b.objectPath(path.Clean(key)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		zap.L().Error("delete blob file failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+)
+
+// S3Config aws s3 or s3 compatible (minio, ceph...) blob config
+type S3Config struct {
+	AccessKeyID     string `yaml:"id"`
+	SecretAccessKey string `yaml:"secret"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	Endpoint        string `yaml:"endpoint"`
+	ForcePathStyle  bool   `yaml:"path_style"`
+	StorageClass    string `yaml:"storage_class"`
+}
+
+// S3Blob aws s3 blob
+type S3Blob struct {
+	config *S3Config
+	client *s3.S3
+}
+
+// NewS3Blob create aws s3 blob, use aws default credential chain if access key undefined
+func NewS3Blob(config *S3Config) (*S3Blob, error) {
+	conf := aws.Config{
+		Region:           aws.String(config.Region),
+		MaxRetries:       aws.Int(5),
+		S3ForcePathStyle: aws.Bool(config.ForcePathStyle),
+	}
+
+	if config.AccessKeyID != "" {
+		conf.Credentials = credentials.NewStaticCredentialsFromCreds(credentials.Value{
+			AccessKeyID:     config.AccessKeyID,
+			SecretAccessKey: config.SecretAccessKey,
+		})
+	}
+
+	if config.Endpoint != "" {
+		conf.Endpoint = aws.String(config.Endpoint)
+	}
+
+	sess, err := session.NewSession(&conf)
+	if err != nil {
+		zap.L().Error("create aws session failed", zap.Error(err), zap.String("region", config.Region), zap.String("endpoint", config.Endpoint))
+		return nil, err
+	}
+
+	return &S3Blob{config: config, client: s3.New(sess)}, nil
+}
+
+// isNotFound check aws error is object not found
+func (b S3Blob) isNotFound(err error) bool {
+	ae, ok := err.(awserr.Error)
+	return ok && (ae.Code() == "NotFound" || ae.Code() == s3.ErrCodeNoSuchKey)
+}
+
+// isConflict check aws error is conditional put failed
+func (b S3Blob) isConflict(err error) bool {
+	rf, ok := err.(awserr.RequestFailure)
+	return ok && (rf.StatusCode() == http.StatusPreconditionFailed || rf.StatusCode() == http.StatusConflict)
+}
+
+// putInput create put object input
+func (b S3Blob) putInput(key string, data []byte) *s3.PutObjectInput {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(b.config.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	}
+
+	if b.config.StorageClass != "" {
+		input.StorageClass = aws.String(b.config.StorageClass)
+	}
+
+	return input
+}
+
+// Put put object
+func (b S3Blob) Put(ctx context.Context, key string, data []byte) error {
+	_, err := b.client.PutObjectWithContext(ctx, b.putInput(key, data))
+	if err != nil {
+		zap.L().Error("put object failed", zap.Error(err), zap.String("bucket", b.config.Bucket), zap.String("key", key))
+		return err
+	}
+
+	return nil
+}
+
+// PutIf put object only if its etag is still etag, by If-Match or If-None-Match header
+func (b S3Blob) PutIf(ctx context.Context, key string, data []byte, etag string) (string, error) {
+	header := map[string]string{"If-Match": etag}
+	if etag == "" {
+		header = map[string]string{"If-None-Match": "*"}
+	}
+
+	output, err := b.client.PutObjectWithContext(ctx, b.putInput(key, data), request.WithSetRequestHeaders(header))
+	if b.isConflict(err) {
+		return "", ErrBlobConflict
+	}
+
+	if err != nil {
+		zap.L().Error("put object failed", zap.Error(err), zap.String("bucket", b.config.Bucket), zap.String("key", key))
+		return "", err
+	}
+
+	return aws.StringValue(output.ETag), nil
+}
+
+// Get get object reader
+func (b S3Blob) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	response, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if b.isNotFound(err) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		zap.L().Error("get object failed", zap.Error(err), zap.String("bucket", b.config.Bucket), zap.String("key", key))
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+// Head get object info
+func (b S3Blob) Head(ctx context.Context, key string) (*BlobInfo, error) {
+	response, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if b.isNotFound(err) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		zap.L().Error("head object failed", zap.Error(err), zap.String("bucket", b.config.Bucket), zap.String("key", key))
+		return nil, err
+	}
+
+	return &BlobInfo{
+		Key:      key,
+		Size:     aws.Int64Value(response.ContentLength),
+		Modified: aws.TimeValue(response.LastModified),
+		ETag:     aws.StringValue(response.ETag),
+	}, nil
+}
+
+// List list objects which key has prefix, ordered by key
+func (b S3Blob) List(ctx context.Context, prefix string) ([]*BlobInfo, error) {
+	var infos []*BlobInfo
+	err := b.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.config.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range output.Contents {
+			infos = append(infos, &BlobInfo{
+				Key:      aws.StringValue(object.Key),
+				Size:     aws.Int64Value(object.Size),
+				Modified: aws.TimeValue(object.LastModified),
+				ETag:     aws.StringValue(object.ETag),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		zap.L().Error("list objects failed", zap.Error(err), zap.String("bucket", b.config.Bucket), zap.String("prefix", prefix))
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// Delete delete object
+func (b S3Blob) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		zap.L().Error("delete object failed", zap.Error(err), zap.String("bucket", b.config.Bucket), zap.String("key", key))
+		return err
+	}
+
+	return nil
+}
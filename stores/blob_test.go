@@ -0,0 +1,344 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+)
+
+func TestBlob(t *testing.T) {
+	blobs := map[string]Blob{
+		"memory": NewMemoryBlob(),
+		"dir":    NewDirBlob(t.TempDir()),
+	}
+
+	ctx := context.Background()
+	for name, blob := range blobs {
+		t.Run(name, func(t *testing.T) {
+			_, err := blob.Head(ctx, "2023/03/06/Nasdaq")
+			if err != ErrBlobNotFound {
+				t.Errorf("Head() missing object error = %v, want %v", err, ErrBlobNotFound)
+			}
+
+			_, err = blob.Get(ctx, "2023/03/06/Nasdaq")
+			if err != ErrBlobNotFound {
+				t.Errorf("Get() missing object error = %v, want %v", err, ErrBlobNotFound)
+			}
+
+			for _, key := range []string{"2023/03/07/Nasdaq", "2023/03/06/Nasdaq", "2023/04/03/Nasdaq"} {
+				err = blob.Put(ctx, key, []byte(key))
+				if err != nil {
+					t.Fatalf("Put(%s) error = %v", key, err)
+				}
+			}
+
+			info, err := blob.Head(ctx, "2023/03/06/Nasdaq")
+			if err != nil || info.Size != int64(len("2023/03/06/Nasdaq")) {
+				t.Errorf("Head() = %+v, %v", info, err)
+			}
+
+			body, err := blob.Get(ctx, "2023/03/06/Nasdaq")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			data, _ := io.ReadAll(body)
+			body.Close()
+
+			if string(data) != "2023/03/06/Nasdaq" {
+				t.Errorf("Get() = %s, want 2023/03/06/Nasdaq", data)
+			}
+
+			infos, err := blob.List(ctx, "2023/03/")
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			if len(infos) != 2 || infos[0].Key != "2023/03/06/Nasdaq" || infos[1].Key != "2023/03/07/Nasdaq" {
+				t.Errorf("List() got %d objects, want 2023/03/06/Nasdaq and 2023/03/07/Nasdaq", len(infos))
+			}
+
+			err = blob.Delete(ctx, "2023/03/06/Nasdaq")
+			if err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+
+			err = blob.Delete(ctx, "2023/03/06/Nasdaq")
+			if err != nil {
+				t.Errorf("Delete() missing object error = %v", err)
+			}
+
+			_, err = blob.Head(ctx, "2023/03/06/Nasdaq")
+			if err != ErrBlobNotFound {
+				t.Errorf("Head() deleted object error = %v, want %v", err, ErrBlobNotFound)
+			}
+		})
+	}
+}
+
+func TestObject(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+
+	store, err := Parse("memory://?codec=columnar")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	defer store.Close()
+
+	exists, err := store.Exists(exchange, date)
+	if err != nil || exists {
+		t.Errorf("Exists() = %v, %v, want false", exists, err)
+	}
+
+	err = store.Save(exchange, date, edq)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	exists, err = store.Exists(exchange, date)
+	if err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+
+	saved, err := store.Load(exchange, date)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	err = edq.Equal(*saved)
+	if err != nil {
+		t.Errorf("Load() not equal: %v", err)
+	}
+
+	err = store.Delete(exchange, date)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	_, err = store.Load(exchange, date)
+	if err != ErrBlobNotFound {
+		t.Errorf("Load() deleted error = %v, want %v", err, ErrBlobNotFound)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	start := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	blob := NewMemoryBlob()
+	store := NewObject(blob, DefaultFormat)
+	for _, offset := range []int{0, 1, 2} {
+		date := start.AddDate(0, 0, offset)
+		err := store.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL", "MSFT"))
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	err := store.Delete(exchange, start.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	// a new store reads the manifest written by the previous one
+	store = NewObject(blob, DefaultFormat)
+	manifest, err := store.loadManifest(context.Background(), exchange, start)
+	if err != nil || manifest == nil {
+		t.Fatalf("loadManifest() = %v, %v", manifest, err)
+	}
+
+	if len(manifest.Entries) != 2 || manifest.Entries[0].Date != "20230306" || manifest.Entries[1].Date != "20230308" {
+		t.Fatalf("loadManifest() got %d entries, want 20230306 and 20230308", len(manifest.Entries))
+	}
+
+	if manifest.Entries[0].Companies != 2 || manifest.Entries[0].Quotes != 2 || manifest.Entries[0].Size == 0 {
+		t.Errorf("loadManifest() entry = %+v", manifest.Entries[0])
+	}
+
+	// drift: object saved behind the manifest's back
+	date := start.AddDate(0, 0, 3)
+	buffer := new(bytes.Buffer)
+	err = encodeFormat(buffer, testExchangeDailyQuote(exchange, date, "AAPL"), DefaultFormat)
+	if err != nil {
+		t.Fatalf("encodeFormat() error = %v", err)
+	}
+
+	err = blob.Put(context.Background(), store.objectKey(exchange, date), buffer.Bytes())
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	exists, err := store.Exists(exchange, date)
+	if err != nil || exists {
+		t.Errorf("Exists() before repair = %v, %v, want false", exists, err)
+	}
+
+	err = store.RepairManifests(exchange, start, start.AddDate(0, 0, 10))
+	if err != nil {
+		t.Fatalf("RepairManifests() error = %v", err)
+	}
+
+	exists, err = store.Exists(exchange, date)
+	if err != nil || !exists {
+		t.Errorf("Exists() after repair = %v, %v, want true", exists, err)
+	}
+
+	stat, err := store.Stat(exchange, date)
+	if err != nil || stat.Companies != 1 || stat.Quotes != 1 {
+		t.Errorf("Stat() = %+v, %v, want 1 company and 1 quote", stat, err)
+	}
+}
+
+func TestMemoryBlob_PutIf(t *testing.T) {
+	ctx := context.Background()
+	blob := NewMemoryBlob()
+
+	etag, err := blob.PutIf(ctx, "manifests/Nasdaq/202303", []byte("first"), "")
+	if err != nil || etag == "" {
+		t.Fatalf("PutIf() new object = %s, %v", etag, err)
+	}
+
+	_, err = blob.PutIf(ctx, "manifests/Nasdaq/202303", []byte("second"), "")
+	if err != ErrBlobConflict {
+		t.Errorf("PutIf() existing object error = %v, want %v", err, ErrBlobConflict)
+	}
+
+	err = blob.Put(ctx, "manifests/Nasdaq/202303", []byte("changed"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	_, err = blob.PutIf(ctx, "manifests/Nasdaq/202303", []byte("second"), etag)
+	if err != ErrBlobConflict {
+		t.Errorf("PutIf() stale etag error = %v, want %v", err, ErrBlobConflict)
+	}
+
+	info, err := blob.Head(ctx, "manifests/Nasdaq/202303")
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+
+	_, err = blob.PutIf(ctx, "manifests/Nasdaq/202303", []byte("second"), info.ETag)
+	if err != nil {
+		t.Errorf("PutIf() current etag error = %v", err)
+	}
+}
+
+func TestManifest_SharedBlob(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	start := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	blob := NewMemoryBlob()
+	first := NewObject(blob, DefaultFormat)
+	second := NewObject(blob, DefaultFormat)
+
+	err := first.Save(exchange, start, testExchangeDailyQuote(exchange, start, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// the other store writes after first cached the manifest
+	date := start.AddDate(0, 0, 1)
+	err = second.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// cached manifest is used within ttl
+	exists, err := first.Exists(exchange, date)
+	if err != nil || exists {
+		t.Errorf("Exists() day saved by other store within ttl = %v, %v, want false", exists, err)
+	}
+
+	first.manifests.ttl = 0
+	exists, err = first.Exists(exchange, date)
+	if err != nil || !exists {
+		t.Errorf("Exists() day saved by other store after ttl = %v, %v, want true", exists, err)
+	}
+	first.manifests.ttl = manifestTTL
+
+	// concurrent writers must not lose manifest entries
+	var wg sync.WaitGroup
+	for offset := 2; offset < 12; offset++ {
+		store := first
+		if offset%2 == 0 {
+			store = second
+		}
+
+		date := start.AddDate(0, 0, offset)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := store.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+			if err != nil {
+				t.Errorf("Save() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	manifest, err := NewObject(blob, DefaultFormat).loadManifest(context.Background(), exchange, start)
+	if err != nil || manifest == nil {
+		t.Fatalf("loadManifest() = %v, %v", manifest, err)
+	}
+
+	if len(manifest.Entries) != 12 {
+		t.Errorf("loadManifest() got %d entries, want 12", len(manifest.Entries))
+	}
+}
+
+// countingBlob count head and get requests
+type countingBlob struct {
+	Blob
+	mutex    sync.Mutex
+	requests int
+}
+
+func (b *countingBlob) Head(ctx context.Context, key string) (*BlobInfo, error) {
+	b.mutex.Lock()
+	b.requests++
+	b.mutex.Unlock()
+
+	return b.Blob.Head(ctx, key)
+}
+
+func (b *countingBlob) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	b.mutex.Lock()
+	b.requests++
+	b.mutex.Unlock()
+
+	return b.Blob.Get(ctx, key)
+}
+
+func TestManifest_Exists(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	start := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	blob := &countingBlob{Blob: NewMemoryBlob()}
+	err := NewObject(blob, DefaultFormat).Save(exchange, start, testExchangeDailyQuote(exchange, start, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	store := NewObject(blob, DefaultFormat)
+	blob.requests = 0
+	for offset := 0; offset < 20; offset++ {
+		date := start.AddDate(0, 0, offset)
+		exists, err := store.Exists(exchange, date)
+		if err != nil || exists != (offset == 0) {
+			t.Errorf("Exists(%s) = %v, %v, want %v", date.Format("20060102"), exists, err, offset == 0)
+		}
+	}
+
+	// one head and one get of the manifest, every day is served from it
+	if blob.requests != 2 {
+		t.Errorf("Exists() made %d requests, want 2", blob.requests)
+	}
+}
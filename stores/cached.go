@@ -0,0 +1,281 @@
+package stores
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// CacheStats define cache hit / miss stats
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// Cached read-through store wrapper, keep recently loaded quotes in a size-bounded lru
+// cached quotes are shared between callers, do not modify them
+type Cached struct {
+	store Store
+	size  int
+
+	mutex   *sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	stats   *CacheStats
+	// generation increase on every invalidation, loads started before it are not cached
+	generation *uint64
+}
+
+// cacheEntry define lru entry
+type cacheEntry struct {
+	key      string
+	exchange string
+	start    time.Time
+	end      time.Time
+	edq      *quotes.ExchangeDailyQuote
+	cdqs     []*quotes.CompanyDailyQuote
+}
+
+// NewCached create cached store keep at most size entries
+func NewCached(store Store, size int) *Cached {
+	return &Cached{
+		store:      store,
+		size:       size,
+		mutex:      new(sync.Mutex),
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		stats:      new(CacheStats),
+		generation: new(uint64),
+	}
+}
+
+// parseCached parse cached store argument like <size>|<store>
+func parseCached(arg string) (*Cached, error) {
+	parts := strings.SplitN(arg, "|", 2)
+	if len(parts) < 2 {
+		zap.L().Error("cached store arg invalid", zap.String("arg", arg))
+		return nil, fmt.Errorf("cached store arg invalid: %s", arg)
+	}
+
+	size, err := strconv.Atoi(parts[0])
+	if err != nil || size <= 0 {
+		zap.L().Error("cached store size invalid", zap.Error(err), zap.String("size", parts[0]))
+		return nil, fmt.Errorf("cached store size invalid: %s", parts[0])
+	}
+
+	store, err := Parse(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCached(store, size), nil
+}
+
+// Stats get cache stats
+func (s Cached) Stats() CacheStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := *s.stats
+	stats.Entries = s.lru.Len()
+
+	return stats
+}
+
+func (s Cached) dailyKey(exchange exchanges.Exchange, date time.Time) string {
+	return fmt.Sprintf("%s:%s", exchange.Code(), date.Format(constants.DatePattern))
+}
+
+func (s Cached) companyKey(exchange exchanges.Exchange, companyCode string, start, end time.Time) string {
+	return fmt.Sprintf("%s:%s:%s:%s", exchange.Code(), companyCode, start.Format(constants.DatePattern), end.Format(constants.DatePattern))
+}
+
+// get get entry and mark it recently used, return current generation to put loaded entry on miss
+func (s Cached) get(key string) (*cacheEntry, uint64, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, found := s.entries[key]
+	if !found {
+		s.stats.Misses++
+		return nil, *s.generation, false
+	}
+
+	s.stats.Hits++
+	s.lru.MoveToFront(element)
+
+	return element.Value.(*cacheEntry), *s.generation, true
+}
+
+// put add entry loaded at generation and evict least recently used entries
+// entry is dropped if any invalidation happened since, it may be loaded before a write finished
+func (s Cached) put(entry *cacheEntry, generation uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if generation != *s.generation {
+		return
+	}
+
+	element, found := s.entries[entry.key]
+	if found {
+		element.Value = entry
+		s.lru.MoveToFront(element)
+		return
+	}
+
+	s.entries[entry.key] = s.lru.PushFront(entry)
+	for s.lru.Len() > s.size {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*cacheEntry).key)
+		s.stats.Evictions++
+	}
+}
+
+// invalidate remove every entry contains exchange daily quote
+func (s Cached) invalidate(exchange exchanges.Exchange, date time.Time) {
+	date, _ = exchangeDateRange(exchange, date, date)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	*s.generation++
+	for element := s.lru.Front(); element != nil; {
+		next := element.Next()
+
+		entry := element.Value.(*cacheEntry)
+		if entry.exchange == exchange.Code() && !date.Before(entry.start) && !date.After(entry.end) {
+			s.lru.Remove(element)
+			delete(s.entries, entry.key)
+		}
+
+		element = next
+	}
+}
+
+// Exists check quote exists
+func (s Cached) Exists(exchange exchanges.Exchange, date time.Time) (bool, error) {
+	s.mutex.Lock()
+	_, found := s.entries[s.dailyKey(exchange, date)]
+	s.mutex.Unlock()
+
+	if found {
+		return true, nil
+	}
+
+	return s.store.Exists(exchange, date)
+}
+
+// write run write of exchange daily quote, invalidate entries contain it before and after the write
+// so neither a load during the write nor a load before it can keep the stale day cached
+func (s Cached) write(exchange exchanges.Exchange, date time.Time, fn func() error) error {
+	s.invalidate(exchange, date)
+	defer s.invalidate(exchange, date)
+
+	return fn()
+}
+
+// Save save exchange daily quote
+func (s Cached) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return s.write(exchange, date, func() error { return s.store.Save(exchange, date, edq) })
+}
+
+// Replace replace exchange daily quote
+func (s Cached) Replace(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return s.write(exchange, date, func() error { return Replace(s.store, exchange, date, edq) })
+}
+
+// Load load exchange daily quote
+func (s Cached) Load(exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	key := s.dailyKey(exchange, date)
+	entry, generation, found := s.get(key)
+	if found {
+		return entry.edq, nil
+	}
+
+	edq, err := s.store.Load(exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
+	day, _ := exchangeDateRange(exchange, date, date)
+	s.put(&cacheEntry{key: key, exchange: exchange.Code(), start: day, end: day, edq: edq}, generation)
+
+	return edq, nil
+}
+
+// Delete delete exchange daily quote
+func (s Cached) Delete(exchange exchanges.Exchange, date time.Time) error {
+	return s.write(exchange, date, func() error { return s.store.Delete(exchange, date) })
+}
+
+// SaveCompany add or replace company daily quote
+func (s Cached) SaveCompany(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	return s.write(exchange, date, func() error { return SaveCompany(s.store, exchange, date, cdq) })
+}
+
+// DeleteCompany remove company daily quote
+func (s Cached) DeleteCompany(exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	return s.write(exchange, date, func() error { return DeleteCompany(s.store, exchange, date, companyCode) })
+}
+
+// Close close underlying store
+func (s Cached) Close() error {
+	return s.store.Close()
+}
+
+// LoadCompany load company daily quotes between start and end date
+func (s Cached) LoadCompany(exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	key := s.companyKey(exchange, companyCode, start, end)
+	entry, generation, found := s.get(key)
+	if found {
+		return entry.cdqs, nil
+	}
+
+	cdqs, err := LoadCompany(s.store, exchange, companyCode, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	s.put(&cacheEntry{key: key, exchange: exchange.Code(), start: start, end: end, cdqs: cdqs}, generation)
+
+	return cdqs, nil
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s Cached) Exchanges() ([]string, error) {
+	catalog, ok := s.store.(Catalog)
+	if !ok {
+		return nil, fmt.Errorf("store %T is not a catalog", s.store)
+	}
+
+	return catalog.Exchanges()
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s Cached) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	return Dates(s.store, exchange, start, end)
+}
+
+// Stat get stored exchange daily quote stats
+func (s Cached) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	catalog, ok := s.store.(Catalog)
+	if ok {
+		return catalog.Stat(exchange, date)
+	}
+
+	return statByLoad(s, exchange, date)
+}
@@ -0,0 +1,106 @@
+package stores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestCached(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	start := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	store, err := Parse("cached|2|fs|" + t.TempDir())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	defer store.Close()
+
+	cached := store.(*Cached)
+	for offset := 0; offset < 3; offset++ {
+		date := start.AddDate(0, 0, offset)
+		err = cached.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	// miss, hit, miss, miss (evict first day), miss
+	for _, offset := range []int{0, 0, 1, 2, 0} {
+		_, err = cached.Load(exchange, start.AddDate(0, 0, offset))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+	}
+
+	stats := cached.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Evictions != 2 || stats.Entries != 2 {
+		t.Errorf("Stats() = %+v, want 1 hits, 4 misses, 2 evictions and 2 entries", stats)
+	}
+
+	cdqs, err := cached.LoadCompany(exchange, "AAPL", start, start.AddDate(0, 0, 2))
+	if err != nil || len(cdqs) != 3 {
+		t.Fatalf("LoadCompany() = %d, %v, want 3 days", len(cdqs), err)
+	}
+
+	// save invalidate cached company quotes contains the day
+	date := start.AddDate(0, 0, 1)
+	err = cached.Save(exchange, date, testExchangeDailyQuote(exchange, date, "MSFT"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	cdqs, err = cached.LoadCompany(exchange, "AAPL", start, start.AddDate(0, 0, 2))
+	if err != nil || len(cdqs) != 2 {
+		t.Errorf("LoadCompany() after save = %d, %v, want 2 days", len(cdqs), err)
+	}
+}
+
+// loadOnSave store run hook before every save
+type loadOnSave struct {
+	Store
+	hook func()
+}
+
+func (s loadOnSave) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	s.hook()
+	return s.Store.Save(exchange, date, edq)
+}
+
+func TestCached_LoadDuringSave(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	inner := NewObject(NewMemoryBlob(), DefaultFormat)
+	err := inner.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	hooked := &loadOnSave{Store: inner}
+	cached := NewCached(hooked, 4)
+
+	// a concurrent load reads the stale day while the save is running
+	hooked.hook = func() {
+		_, err := cached.Load(exchange, date)
+		if err != nil {
+			t.Errorf("Load() during save error = %v", err)
+		}
+	}
+
+	err = cached.Save(exchange, date, testExchangeDailyQuote(exchange, date, "MSFT"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	edq, err := cached.Load(exchange, date)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if _, found := edq.Quotes["MSFT"]; !found || len(edq.Quotes) != 1 {
+		t.Errorf("Load() after save got stale companies %d, want MSFT", len(edq.Quotes))
+	}
+}
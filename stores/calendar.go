@@ -0,0 +1,57 @@
+package stores
+
+import (
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"go.uber.org/zap"
+)
+
+// learnedHoliday holiday name of days learned from store
+const learnedHoliday = "no quotes stored"
+
+// Stat get stored exchange daily quote stats
+// use store native implement if it is a Catalog, otherwise load the whole exchange daily quote
+func Stat(store Store, exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	catalog, ok := store.(Catalog)
+	if ok {
+		return catalog.Stat(exchange, date)
+	}
+
+	return statByLoad(store, exchange, date)
+}
+
+// LearnCalendar mark stored days without any quote between start and end date (inclusive) as holidays of calendar,
+// days covered by calendar data are skipped, return days learned
+func LearnCalendar(store Store, exchange exchanges.Exchange, calendar *exchanges.Calendar, start, end time.Time) ([]time.Time, error) {
+	dates, err := Dates(store, exchange, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var learned []time.Time
+	for _, date := range dates {
+		if calendar.Covers(date) || !calendar.IsTradingDay(date) {
+			continue
+		}
+
+		stat, err := Stat(store, exchange, date)
+		if err != nil {
+			zap.L().Error("stat exchange daily quote failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return learned, err
+		}
+
+		if stat.Quotes > 0 {
+			continue
+		}
+
+		if calendar.AddHoliday(date, learnedHoliday) {
+			learned = append(learned, date)
+		}
+	}
+
+	return learned, nil
+}
@@ -0,0 +1,42 @@
+package stores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestLearnCalendar(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	calendar := exchanges.NewCalendar(exchange.Code(), exchange.Location(), exchanges.Sessions{}, time.Saturday, time.Sunday)
+
+	// monday has quotes, tuesday is closed
+	monday := time.Date(2030, 3, 4, 0, 0, 0, 0, exchange.Location())
+	tuesday := monday.AddDate(0, 0, 1)
+
+	store := NewFileSystem(t.TempDir())
+	err := store.Save(exchange, monday, testExchangeDailyQuote(exchange, monday, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	err = store.Save(exchange, tuesday, &quotes.ExchangeDailyQuote{Exchange: exchange.Code(), Date: tuesday})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	learned, err := LearnCalendar(store, exchange, calendar, monday, monday.AddDate(0, 0, 6))
+	if err != nil {
+		t.Fatalf("LearnCalendar() error = %v", err)
+	}
+
+	if len(learned) != 1 || !learned[0].Equal(tuesday) {
+		t.Errorf("LearnCalendar() = %v, want [%v]", learned, tuesday)
+	}
+
+	if !calendar.IsTradingDay(monday) || calendar.IsTradingDay(tuesday) {
+		t.Errorf("LearnCalendar() learned wrong holidays: %v", calendar.Holidays(monday, tuesday))
+	}
+}
@@ -0,0 +1,233 @@
+package stores
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"go.uber.org/zap"
+)
+
+// DailyStat define exchange daily quote stats in store
+type DailyStat struct {
+	Exchange  string
+	Date      time.Time
+	Companies int
+	Quotes    int
+}
+
+// Catalog define store can enumerate stored exchange daily quotes
+type Catalog interface {
+	// Exchanges list exchange codes which has stored daily quote
+	Exchanges() ([]string, error)
+	// Dates list stored dates of exchange between start and end date (inclusive), ordered by date
+	Dates(exchanges.Exchange, time.Time, time.Time) ([]time.Time, error)
+	// Stat get stored exchange daily quote stats
+	Stat(exchanges.Exchange, time.Time) (*DailyStat, error)
+}
+
+// Dates list stored dates of exchange between start and end date (inclusive)
+// use store native implement if it is a Catalog, otherwise check exists day by day
+func Dates(store Store, exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	return DatesContext(context.Background(), store, exchange, start, end)
+}
+
+// DatesContext list stored dates of exchange between start and end date (inclusive) until context done
+func DatesContext(ctx context.Context, store Store, exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	store, bound := bind(ctx, store)
+	catalog, ok := store.(Catalog)
+	if ok && bound {
+		return catalog.Dates(exchange, start, end)
+	}
+
+	if ok {
+		var dates []time.Time
+		err := waitContext(ctx, func() error {
+			var err error
+			dates, err = catalog.Dates(exchange, start, end)
+			return err
+		})
+
+		return dates, err
+	}
+
+	cs := WithContext(store)
+	var dates []time.Time
+	for _, date := range exchangeDates(exchange, start, end) {
+		exists, err := cs.ExistsContext(ctx, exchange, date)
+		if err != nil {
+			zap.L().Error("check exchange daily quote exists failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return nil, err
+		}
+
+		if exists {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates, nil
+}
+
+// MissingDates list dates stored in src but not in dst between start and end date (inclusive), ordered by date
+func MissingDates(src, dst Store, exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	return MissingDatesContext(context.Background(), src, dst, exchange, start, end)
+}
+
+// MissingDatesContext list dates stored in src but not in dst between start and end date (inclusive) until context done
+func MissingDatesContext(ctx context.Context, src, dst Store, exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	dates, err := DatesContext(ctx, src, exchange, start, end)
+	if err != nil {
+		zap.L().Error("list source exchange dates failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	return MissingContext(ctx, dst, exchange, dates)
+}
+
+// Missing filter ordered dates not stored in store
+func Missing(store Store, exchange exchanges.Exchange, dates []time.Time) ([]time.Time, error) {
+	return MissingContext(context.Background(), store, exchange, dates)
+}
+
+// MissingContext filter ordered dates not stored in store until context done
+func MissingContext(ctx context.Context, store Store, exchange exchanges.Exchange, dates []time.Time) ([]time.Time, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	start, end := dates[0], dates[len(dates)-1]
+	stored, err := DatesContext(ctx, store, exchange, start, end)
+	if err != nil {
+		zap.L().Error("list stored exchange dates failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	exists := make(map[int64]bool, len(stored))
+	for _, date := range stored {
+		exists[date.Unix()] = true
+	}
+
+	var missing []time.Time
+	for _, date := range dates {
+		if !exists[date.Unix()] {
+			missing = append(missing, date)
+		}
+	}
+
+	return missing, nil
+}
+
+// statByLoad get exchange daily quote stats by load whole exchange daily quote
+func statByLoad(store Store, exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	edq, err := store.Load(exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	return &DailyStat{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: len(edq.Companies),
+		Quotes:    len(edq.Quotes),
+	}, nil
+}
+
+// parseDate parse compact date text in exchange location, return false if out of [start, end]
+func parseDate(exchange exchanges.Exchange, text string, start, end time.Time) (time.Time, bool) {
+	date, err := time.ParseInLocation(constants.DatePattern, text, exchange.Location())
+	if err != nil {
+		return date, false
+	}
+
+	if date.Before(start) || date.After(end) {
+		return date, false
+	}
+
+	return date, true
+}
+
+// objectMonthPrefixes return object key prefix of every month between start and end, like 2006/01/
+func objectMonthPrefixes(start, end time.Time) []string {
+	var prefixes []string
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	for !month.After(end) {
+		prefixes = append(prefixes, month.Format("2006/01/"))
+		month = month.AddDate(0, 1, 0)
+	}
+
+	return prefixes
+}
+
+// parseObjectKey parse object key like 2006/01/02/{exchange}, return compact date and exchange code
+func parseObjectKey(key string) (string, string, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 4 {
+		return "", "", false
+	}
+
+	return parts[0] + parts[1] + parts[2], parts[3], true
+}
+
+// objectDates filter object keys by exchange and date range
+func objectDates(exchange exchanges.Exchange, keys []string, start, end time.Time) []time.Time {
+	var dates []time.Time
+	for _, key := range keys {
+		text, code, ok := parseObjectKey(key)
+		if !ok || code != exchange.Code() {
+			continue
+		}
+
+		date, ok := parseDate(exchange, text, start, end)
+		if !ok {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	return dates
+}
+
+// objectExchanges get distinct exchange codes from object keys
+func objectExchanges(keys []string) []string {
+	distinct := make(map[string]bool)
+	for _, key := range keys {
+		_, code, ok := parseObjectKey(key)
+		if ok {
+			distinct[code] = true
+		}
+	}
+
+	codes := make([]string, 0, len(distinct))
+	for code := range distinct {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
@@ -0,0 +1,110 @@
+package stores
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+)
+
+func TestCatalog(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	start := time.Date(2023, 3, 30, 0, 0, 0, 0, exchange.Location())
+
+	leveldb := NewLevelDB(t.TempDir())
+	defer leveldb.Close()
+
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sqlite.Close()
+
+	catalogs := map[string]Catalog{
+		"fs":      NewFileSystem(t.TempDir()),
+		"leveldb": leveldb,
+		"sqlite":  sqlite,
+		"memory":  NewObject(NewMemoryBlob(), DefaultFormat),
+		"dir":     NewObject(NewDirBlob(t.TempDir()), DefaultFormat),
+	}
+
+	for name, catalog := range catalogs {
+		t.Run(name, func(t *testing.T) {
+			store := catalog.(Store)
+			for _, offset := range []int{0, 1, 3, 5} {
+				date := start.AddDate(0, 0, offset)
+				err := store.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL", "MSFT"))
+				if err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+
+			codes, err := catalog.Exchanges()
+			if err != nil {
+				t.Fatalf("Exchanges() error = %v", err)
+			}
+
+			if len(codes) != 1 || codes[0] != exchange.Code() {
+				t.Errorf("Exchanges() = %v, want [%s]", codes, exchange.Code())
+			}
+
+			dates, err := catalog.Dates(exchange, start.AddDate(0, 0, 1), start.AddDate(0, 0, 4))
+			if err != nil {
+				t.Fatalf("Dates() error = %v", err)
+			}
+
+			want := []time.Time{start.AddDate(0, 0, 1), start.AddDate(0, 0, 3)}
+			if len(dates) != len(want) {
+				t.Fatalf("Dates() = %v, want %v", dates, want)
+			}
+
+			for index, date := range dates {
+				if !date.Equal(want[index]) {
+					t.Errorf("Dates()[%d] = %v, want %v", index, date, want[index])
+				}
+			}
+
+			stat, err := catalog.Stat(exchange, start)
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+
+			if stat.Companies != 2 || stat.Quotes != 2 {
+				t.Errorf("Stat() = %+v, want 2 companies and 2 quotes", stat)
+			}
+		})
+	}
+}
+
+func TestMissingDates(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	start := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	src := NewFileSystem(t.TempDir())
+	dst := NewObject(NewMemoryBlob(), DefaultFormat)
+	for _, offset := range []int{0, 1, 2, 3} {
+		date := start.AddDate(0, 0, offset)
+		err := src.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	for _, offset := range []int{1, 3, 10} {
+		date := start.AddDate(0, 0, offset)
+		err := dst.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	dates, err := MissingDates(src, dst, exchange, start, start.AddDate(0, 0, 10))
+	if err != nil {
+		t.Fatalf("MissingDates() error = %v", err)
+	}
+
+	if len(dates) != 2 || !dates[0].Equal(start) || !dates[1].Equal(start.AddDate(0, 0, 2)) {
+		t.Errorf("MissingDates() = %v, want %v and %v", dates, start, start.AddDate(0, 0, 2))
+	}
+}
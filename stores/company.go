@@ -0,0 +1,169 @@
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// CompanyWriter define store can save or delete single company daily quote without rewriting whole exchange day
+type CompanyWriter interface {
+	// SaveCompany add or replace company daily quote of exchange day, the day is created if not exists
+	SaveCompany(exchanges.Exchange, time.Time, *quotes.CompanyDailyQuote) error
+	// DeleteCompany remove company and its daily quote from exchange day, do nothing if not exists
+	DeleteCompany(exchanges.Exchange, time.Time, string) error
+}
+
+// SaveCompany add or replace company daily quote of exchange day
+// use store native implement if it is a CompanyWriter, otherwise load, modify and save the whole exchange day
+func SaveCompany(store Store, exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	writer, ok := store.(CompanyWriter)
+	if ok {
+		return writer.SaveCompany(exchange, date, cdq)
+	}
+
+	return saveCompanyByDay(store, exchange, date, cdq)
+}
+
+// DeleteCompany remove company and its daily quote from exchange day
+// use store native implement if it is a CompanyWriter, otherwise load, modify and save the whole exchange day
+func DeleteCompany(store Store, exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	writer, ok := store.(CompanyWriter)
+	if ok {
+		return writer.DeleteCompany(exchange, date, companyCode)
+	}
+
+	return deleteCompanyByDay(store, exchange, date, companyCode)
+}
+
+// SaveCompanyContext add or replace company daily quote of exchange day unless context done, a started save is not cancelled
+func SaveCompanyContext(ctx context.Context, store Store, exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return SaveCompany(store, exchange, date, cdq)
+}
+
+// DeleteCompanyContext remove company and its daily quote from exchange day unless context done, a started delete is not cancelled
+func DeleteCompanyContext(ctx context.Context, store Store, exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return DeleteCompany(store, exchange, date, companyCode)
+}
+
+// saveCompanyByDay add or replace company daily quote by rewriting the whole exchange day
+func saveCompanyByDay(store Store, exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	edq, err := loadOrCreateDay(store, exchange, date)
+	if err != nil {
+		return err
+	}
+
+	edq.Companies[cdq.Company.Code] = cdq.Company
+	edq.Quotes[cdq.Company.Code] = cdq
+
+	err = store.Save(exchange, date, edq)
+	if err != nil {
+		zap.L().Error("save exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", cdq.Company.Code),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+// deleteCompanyByDay remove company daily quote by rewriting the whole exchange day
+func deleteCompanyByDay(store Store, exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	exists, err := store.Exists(exchange, date)
+	if err != nil {
+		zap.L().Error("check exchange daily quote exists failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	if !exists {
+		return nil
+	}
+
+	edq, err := store.Load(exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	_, listed := edq.Companies[companyCode]
+	_, quoted := edq.Quotes[companyCode]
+	if !listed && !quoted {
+		return nil
+	}
+
+	delete(edq.Companies, companyCode)
+	delete(edq.Quotes, companyCode)
+
+	err = store.Save(exchange, date, edq)
+	if err != nil {
+		zap.L().Error("save exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+// loadOrCreateDay load exchange daily quote, return empty one if not exists
+func loadOrCreateDay(store Store, exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	exists, err := store.Exists(exchange, date)
+	if err != nil {
+		zap.L().Error("check exchange daily quote exists failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	if !exists {
+		return &quotes.ExchangeDailyQuote{
+			Exchange:  exchange.Code(),
+			Date:      date,
+			Companies: map[string]*quotes.Company{},
+			Quotes:    map[string]*quotes.CompanyDailyQuote{},
+		}, nil
+	}
+
+	edq, err := store.Load(exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	if edq.Companies == nil {
+		edq.Companies = map[string]*quotes.Company{}
+	}
+
+	if edq.Quotes == nil {
+		edq.Quotes = map[string]*quotes.CompanyDailyQuote{}
+	}
+
+	return edq, nil
+}
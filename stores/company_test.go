@@ -0,0 +1,102 @@
+package stores
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestSaveCompany(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	leveldb := NewLevelDB(t.TempDir())
+	defer leveldb.Close()
+
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sqlite.Close()
+
+	_stores := map[string]Store{
+		"fs":      NewFileSystem(t.TempDir()),
+		"leveldb": leveldb,
+		"sqlite":  sqlite,
+		"memory":  NewObject(NewMemoryBlob(), DefaultFormat),
+		"cached":  NewCached(NewObject(NewMemoryBlob(), DefaultFormat), 4),
+	}
+
+	for name, store := range _stores {
+		t.Run(name, func(t *testing.T) {
+			err := store.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL", "MSFT"))
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			// warm cache, later writes must invalidate it
+			_, err = store.Load(exchange, date)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			// replace with fewer bars, the stale bar must be removed
+			aapl := testExchangeDailyQuote(exchange, date, "AAPL").Quotes["AAPL"]
+			*aapl.Regular = (*aapl.Regular)[1:]
+			aapl.Actions = quotes.Actions{{Type: quotes.ActionCashDividend, Timestamp: (*aapl.Regular)[0].Timestamp, Amount: 0.5}}
+
+			goog := testExchangeDailyQuote(exchange, date, "GOOG").Quotes["GOOG"]
+
+			for _, cdq := range []*quotes.CompanyDailyQuote{aapl, goog} {
+				err = SaveCompany(store, exchange, date, cdq)
+				if err != nil {
+					t.Fatalf("SaveCompany(%s) error = %v", cdq.Company.Code, err)
+				}
+			}
+
+			for _, code := range []string{"MSFT", "TSLA"} {
+				err = DeleteCompany(store, exchange, date, code)
+				if err != nil {
+					t.Fatalf("DeleteCompany(%s) error = %v", code, err)
+				}
+			}
+
+			want := &quotes.ExchangeDailyQuote{
+				Exchange:  exchange.Code(),
+				Date:      date,
+				Companies: map[string]*quotes.Company{"AAPL": aapl.Company, "GOOG": goog.Company},
+				Quotes:    map[string]*quotes.CompanyDailyQuote{"AAPL": aapl, "GOOG": goog},
+			}
+
+			edq, err := store.Load(exchange, date)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			err = want.Equal(*edq)
+			if err != nil {
+				t.Errorf("Load() after SaveCompany() not equal: %v", err)
+			}
+
+			// save company into missing day creates the day
+			next := date.AddDate(0, 0, 1)
+			err = SaveCompany(store, exchange, next, testExchangeDailyQuote(exchange, next, "AAPL").Quotes["AAPL"])
+			if err != nil {
+				t.Fatalf("SaveCompany() error = %v", err)
+			}
+
+			edq, err = store.Load(exchange, next)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			err = testExchangeDailyQuote(exchange, next, "AAPL").Equal(*edq)
+			if err != nil {
+				t.Errorf("Load() of created day not equal: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+// ContextStore define exchange daily quote store can be cancelled by context
+type ContextStore interface {
+	Store
+	// ExistsContext exchange daily quote exists until context done
+	ExistsContext(context.Context, exchanges.Exchange, time.Time) (bool, error)
+	// SaveContext save exchange daily quote, not started if context done
+	// a started save always finishes, so a cancelled crawl never leaves a partially written day
+	SaveContext(context.Context, exchanges.Exchange, time.Time, *quotes.ExchangeDailyQuote) error
+	// LoadContext load exchange daily quote until context done
+	LoadContext(context.Context, exchanges.Exchange, time.Time) (*quotes.ExchangeDailyQuote, error)
+	// DeleteContext delete exchange daily quote, not started if context done, a started delete always finishes
+	DeleteContext(context.Context, exchanges.Exchange, time.Time) error
+}
+
+// WithContext adapt store to ContextStore, use native implement if store is a ContextStore
+func WithContext(store Store) ContextStore {
+	if cs, ok := store.(ContextStore); ok {
+		return cs
+	}
+
+	return contextStore{store}
+}
+
+// contextBinder define store can bind context to its requests, reads of the bound copy stop when context done,
+// capabilities of the bound copy like Catalog and SeriesReader included
+type contextBinder interface {
+	withContext(context.Context) Store
+}
+
+// bind bind context to requests of store, unwrap adapted store first, return false if store cannot bind context
+func bind(ctx context.Context, store Store) (Store, bool) {
+	if cs, ok := store.(contextStore); ok {
+		store = cs.Store
+	}
+
+	binder, ok := store.(contextBinder)
+	if !ok {
+		return store, false
+	}
+
+	return binder.withContext(ctx), true
+}
+
+// waitContext run fn in background and wait until it returns or context done
+// the abandoned fn keeps running in background until it returns
+func waitContext(ctx context.Context, fn func() error) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err = <-done:
+		return err
+	}
+}
+
+// contextStore adapt Store to ContextStore, give up waiting reads when context done
+// the abandoned read keeps running in background until the underlying store returns, writes are never abandoned
+// stores with cancellable requests, like Object, Redis, SQLite and TDEngine, implement ContextStore natively
+type contextStore struct {
+	Store
+}
+
+// wait run fn in background and wait until it returns or context done
+func (s contextStore) wait(ctx context.Context, fn func() error) error {
+	return waitContext(ctx, fn)
+}
+
+// write run fn unless context done, a started write is waited until it returns even if context is done meanwhile
+// so callers exiting after cancellation never cut a write off
+func (s contextStore) write(ctx context.Context, fn func() error) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return fn()
+}
+
+// ExistsContext check quote exists until context done
+func (s contextStore) ExistsContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (bool, error) {
+	var exists bool
+	err := s.wait(ctx, func() error {
+		var err error
+		exists, err = s.Exists(exchange, date)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// SaveContext save exchange daily quote unless context done
+func (s contextStore) SaveContext(ctx context.Context, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return s.write(ctx, func() error {
+		return s.Save(exchange, date, edq)
+	})
+}
+
+// Replace replace exchange daily quote of wrapped store
+func (s contextStore) Replace(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return Replace(s.Store, exchange, date, edq)
+}
+
+// LoadContext load exchange daily quote until context done
+func (s contextStore) LoadContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	var edq *quotes.ExchangeDailyQuote
+	err := s.wait(ctx, func() error {
+		var err error
+		edq, err = s.Load(exchange, date)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return edq, nil
+}
+
+// DeleteContext delete exchange daily quote unless context done
+func (s contextStore) DeleteContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) error {
+	return s.write(ctx, func() error {
+		return s.Delete(exchange, date)
+	})
+}
@@ -0,0 +1,138 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestWithContext(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	edq := testExchangeDailyQuote(exchange, date, "AAPL")
+
+	store := WithContext(NewFileSystem(t.TempDir()))
+
+	err := store.SaveContext(context.Background(), exchange, date, edq)
+	if err != nil {
+		t.Fatalf("SaveContext() error = %v", err)
+	}
+
+	saved, err := store.LoadContext(context.Background(), exchange, date)
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+
+	err = edq.Equal(*saved)
+	if err != nil {
+		t.Errorf("LoadContext() not equal: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = store.ExistsContext(ctx, exchange, date)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ExistsContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// cancelOnSave cancel context when save starts, then save slowly
+type cancelOnSave struct {
+	Store
+	cancel context.CancelFunc
+}
+
+func (s cancelOnSave) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	s.cancel()
+	time.Sleep(time.Millisecond * 20)
+	return s.Store.Save(exchange, date, edq)
+}
+
+func TestWithContext_SaveFinishes(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fs := NewFileSystem(t.TempDir())
+	store := WithContext(cancelOnSave{Store: fs, cancel: cancel})
+
+	// started save is not abandoned when context is cancelled meanwhile
+	err := store.SaveContext(ctx, exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+	if err != nil {
+		t.Fatalf("SaveContext() error = %v", err)
+	}
+
+	exists, err := fs.Exists(exchange, date)
+	if err != nil || !exists {
+		t.Errorf("SaveContext() returned before save finished, exists = %v, error = %v", exists, err)
+	}
+
+	// save is not started once context done
+	err = store.SaveContext(ctx, exchange, date.AddDate(0, 0, 1), testExchangeDailyQuote(exchange, date.AddDate(0, 0, 1), "AAPL"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SaveContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestContext_Capabilities(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sqlite.Close()
+
+	for name, store := range map[string]Store{
+		"sqlite": sqlite,
+		"memory": NewObject(NewMemoryBlob(), DefaultFormat),
+		"fs":     NewFileSystem(t.TempDir()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := store.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			dates, err := DatesContext(context.Background(), WithContext(store), exchange, date, date)
+			if err != nil || len(dates) != 1 {
+				t.Errorf("DatesContext() = %v, %v, want 1 date", dates, err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err = WithContext(store).ExistsContext(ctx, exchange, date)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("ExistsContext() error = %v, want %v", err, context.Canceled)
+			}
+
+			_, err = DatesContext(ctx, store, exchange, date, date)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("DatesContext() error = %v, want %v", err, context.Canceled)
+			}
+
+			_, err = LoadCompanyContext(ctx, store, exchange, "AAPL", date, date)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("LoadCompanyContext() error = %v, want %v", err, context.Canceled)
+			}
+
+			_, err = StreamContext(ctx, store, exchange, date)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("StreamContext() error = %v, want %v", err, context.Canceled)
+			}
+
+			err = SaveCompanyContext(ctx, store, exchange, date, testExchangeDailyQuote(exchange, date, "MSFT").Quotes["MSFT"])
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("SaveCompanyContext() error = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
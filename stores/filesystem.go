@@ -2,10 +2,12 @@ package stores
 
 import (
 	"bytes"
-	"compress/gzip"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/nzai/qr/exchanges"
@@ -15,12 +17,42 @@ import (
 
 // FileSystem define file system store
 type FileSystem struct {
-	root string
+	root   string
+	format Format
 }
 
-// NewFileSystem create file system store
+func init() {
+	Register("fs", openFileSystem)
+}
+
+// openFileSystem open file system store by dsn like fs:///data?compression=zstd&codec=row
+func openFileSystem(dsn *DSN) (Store, error) {
+	err := dsn.Validate("compression", "codec")
+	if err != nil {
+		return nil, err
+	}
+
+	if dsn.Location() == "" {
+		return nil, fmt.Errorf("file system store root undefined")
+	}
+
+	format, err := ParseFormat(dsn.Option("compression", ""), dsn.Option("codec", ""))
+	if err != nil {
+		zap.L().Error("parse file system format failed", zap.Error(err))
+		return nil, err
+	}
+
+	return NewFileSystemWithFormat(dsn.Location(), format), nil
+}
+
+// NewFileSystem create file system store with default format
 func NewFileSystem(root string) *FileSystem {
-	return &FileSystem{root: root}
+	return NewFileSystemWithFormat(root, DefaultFormat)
+}
+
+// NewFileSystemWithFormat create file system store with file format
+func NewFileSystemWithFormat(root string, format Format) *FileSystem {
+	return &FileSystem{root: root, format: format}
 }
 
 // storePath return store path
@@ -108,24 +140,12 @@ func (s FileSystem) Save(exchange exchanges.Exchange, date time.Time, edq *quote
 // save exchange daily quote to filePath
 func (s FileSystem) save(filePath string, edq *quotes.ExchangeDailyQuote) error {
 	buffer := new(bytes.Buffer)
-
-	// init gzip writer
-	gw, err := gzip.NewWriterLevel(buffer, gzip.BestCompression)
-	if err != nil {
-		zap.L().Error("create gzip writer failed", zap.Error(err), zap.String("filePath", filePath))
-		return err
-	}
-
-	// encode to gzip writer
-	err = edq.Encode(gw)
+	err := encodeFormat(buffer, edq, s.format)
 	if err != nil {
 		zap.L().Error("encode quote failed", zap.Error(err), zap.String("filePath", filePath))
 		return err
 	}
 
-	gw.Flush()
-	gw.Close()
-
 	file, err := os.Create(filePath)
 	if err != nil {
 		zap.L().Error("load quote failed", zap.Error(err), zap.String("filePath", filePath))
@@ -151,38 +171,43 @@ func (s FileSystem) Load(exchange exchanges.Exchange, date time.Time) (*quotes.E
 
 // load quote from path
 func (s FileSystem) load(filePath string) (*quotes.ExchangeDailyQuote, error) {
-	file, err := os.Open(filePath)
+	stream, err := s.stream(filePath)
 	if err != nil {
-		zap.L().Error("load quote failed", zap.Error(err), zap.String("pth", filePath))
 		return nil, err
 	}
-	defer file.Close()
+	defer stream.Close()
 
-	// init gzip reader
-	gr, err := gzip.NewReader(file)
+	edq, err := stream.ReadAll()
 	if err != nil {
-		zap.L().Error("create gzip reader failed", zap.Error(err), zap.String("pth", filePath))
+		zap.L().Error("decode quote failed", zap.Error(err), zap.String("pth", filePath))
 		return nil, err
 	}
-	defer gr.Close()
 
-	// read unzip bytes
-	buffer := new(bytes.Buffer)
-	_, err = io.Copy(buffer, gr)
+	return edq, nil
+}
+
+// Stream open exchange daily quote stream
+func (s FileSystem) Stream(exchange exchanges.Exchange, date time.Time) (*QuoteStream, error) {
+	return s.stream(s.storePath(exchange, date))
+}
+
+// stream open quote stream from path
+func (s FileSystem) stream(filePath string) (*QuoteStream, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		zap.L().Error("read gzip failed", zap.Error(err), zap.String("pth", filePath))
+		zap.L().Error("load quote failed", zap.Error(err), zap.String("pth", filePath))
 		return nil, err
 	}
 
-	// decode from bytes
-	edq := new(quotes.ExchangeDailyQuote)
-	err = edq.Decode(buffer)
+	stream, err := streamFormat(file)
 	if err != nil {
+		file.Close()
 		zap.L().Error("decode quote failed", zap.Error(err), zap.String("pth", filePath))
 		return nil, err
 	}
+	stream.closer = multiCloser{stream.closer, file}
 
-	return edq, nil
+	return stream, nil
 }
 
 // Delete delete exchange daily quote
@@ -201,11 +226,143 @@ func (s FileSystem) Delete(exchange exchanges.Exchange, date time.Time) error {
 	return nil
 }
 
+// SaveCompany add or replace company daily quote, the whole file is rewritten
+func (s FileSystem) SaveCompany(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	return saveCompanyByDay(s, exchange, date, cdq)
+}
+
+// DeleteCompany remove company daily quote, the whole file is rewritten
+func (s FileSystem) DeleteCompany(exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	return deleteCompanyByDay(s, exchange, date, companyCode)
+}
+
 // Close close store
 func (s FileSystem) Close() error {
 	return nil
 }
 
+// Exchanges list exchange codes which has stored daily quote
+func (s FileSystem) Exchanges() ([]string, error) {
+	distinct := make(map[string]bool)
+	err := s.walkDays(func(dir, date string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasSuffix(entry.Name(), ".temp") {
+				continue
+			}
+
+			distinct[entry.Name()] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("walk store days failed", zap.Error(err), zap.String("root", s.root))
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(distinct))
+	for code := range distinct {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes, nil
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s FileSystem) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	var dates []time.Time
+	err := s.walkDays(func(dir, text string) error {
+		date, ok := parseDate(exchange, text, start, end)
+		if !ok {
+			return nil
+		}
+
+		_, err := os.Stat(filepath.Join(dir, exchange.Code()))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		dates = append(dates, date)
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("walk store days failed",
+			zap.Error(err),
+			zap.String("root", s.root),
+			zap.String("exchange", exchange.Code()))
+		return nil, err
+	}
+
+	return dates, nil
+}
+
+// Stat get stored exchange daily quote stats
+func (s FileSystem) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	return statByLoad(s, exchange, date)
+}
+
+// walkDays walk every {root}/{year}/{month}/{day} dir in order, and pass dir and compact date to fn
+func (s FileSystem) walkDays(fn func(string, string) error) error {
+	years, err := s.readSubDirs(s.root)
+	if err != nil {
+		return err
+	}
+
+	for _, year := range years {
+		months, err := s.readSubDirs(filepath.Join(s.root, year))
+		if err != nil {
+			return err
+		}
+
+		for _, month := range months {
+			days, err := s.readSubDirs(filepath.Join(s.root, year, month))
+			if err != nil {
+				return err
+			}
+
+			for _, day := range days {
+				err = fn(filepath.Join(s.root, year, month, day), year+month+day)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// readSubDirs read sub dir names order by name
+func (s FileSystem) readSubDirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
 // EnsureDir ensure target dir exists
 func (s FileSystem) ensureDir(dir string) error {
 	_, err := os.Stat(dir)
@@ -0,0 +1,399 @@
+package stores
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// file format
+//
+//	+--------+---------+-------------+--------+----------+--------+-------------+--------+---------+
+//	| magic  | version | compression | codec  | checksum | length | meta length | meta   | payload |
+//	| 4 byte | 1 byte  | 1 byte      | 1 byte | 4 byte   | 8 byte | 4 byte      | ...    | ...     |
+//	+--------+---------+-------------+--------+----------+--------+-------------+--------+---------+
+//
+// meta is uncompressed json, meta length is 0 if the exchange daily quote has no meta.
+// checksum is crc32 (IEEE) of meta followed by the uncompressed payload, length is the uncompressed payload size.
+// legacy files have no header, they are gzip compressed exchange daily quote encode stream.
+var formatMagic = [4]byte{'Q', 'R', 'D', 'Q'}
+
+const (
+	// formatVersion exchange daily quote encode stream by serial codec as payload, with json meta
+	formatVersion byte = 1
+	// formatHeaderSize size of header before meta
+	formatHeaderSize = 23
+
+	// formatMaxMetaLength upper bound of meta length, meta is small json so anything larger is corrupted
+	formatMaxMetaLength = 1 << 20
+)
+
+// Format define file format options
+type Format struct {
+	Compression Compression
+	Codec       quotes.SerialCodec
+}
+
+// DefaultFormat zstd compressed row encoded payload
+var DefaultFormat = Format{Compression: CompressionZstd, Codec: quotes.SerialCodecRow}
+
+// ParseFormat parse format from compression and serial codec names, empty name means default
+func ParseFormat(compression, codec string) (Format, error) {
+	format := DefaultFormat
+
+	var err error
+	if compression != "" {
+		format.Compression, err = ParseCompression(compression)
+		if err != nil {
+			return format, err
+		}
+	}
+
+	if codec != "" {
+		format.Codec, err = quotes.ParseSerialCodec(codec)
+		if err != nil {
+			return format, err
+		}
+	}
+
+	return format, nil
+}
+
+// Compression define file payload compression codec
+type Compression byte
+
+const (
+	// CompressionNone store payload without compression
+	CompressionNone Compression = iota
+	// CompressionGzip compress payload with gzip
+	CompressionGzip
+	// CompressionZstd compress payload with zstd
+	CompressionZstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("compression(%d)", byte(c))
+	}
+}
+
+// ParseCompression parse compression codec name
+func ParseCompression(name string) (Compression, error) {
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		if compression.String() == name {
+			return compression, nil
+		}
+	}
+
+	return CompressionNone, fmt.Errorf("compression invalid: %s", name)
+}
+
+// formatHeader define file header
+type formatHeader struct {
+	Version     byte
+	Compression Compression
+	Codec       quotes.SerialCodec
+	Checksum    uint32
+	Length      uint64
+	Meta        []byte
+}
+
+func (h formatHeader) encode(w io.Writer) error {
+	buffer := make([]byte, 0, formatHeaderSize+len(h.Meta))
+	buffer = append(buffer, formatMagic[:]...)
+	buffer = append(buffer, h.Version, byte(h.Compression), byte(h.Codec))
+	buffer = binary.BigEndian.AppendUint32(buffer, h.Checksum)
+	buffer = binary.BigEndian.AppendUint64(buffer, h.Length)
+	buffer = binary.BigEndian.AppendUint32(buffer, uint32(len(h.Meta)))
+	buffer = append(buffer, h.Meta...)
+
+	_, err := w.Write(buffer)
+	return err
+}
+
+func (h *formatHeader) decode(r io.Reader) error {
+	buffer := make([]byte, formatHeaderSize)
+	_, err := io.ReadFull(r, buffer)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(buffer[:4], formatMagic[:]) {
+		return fmt.Errorf("format magic invalid: %x", buffer[:4])
+	}
+
+	h.Version = buffer[4]
+	if h.Version != formatVersion {
+		return fmt.Errorf("format version unsupported: %d", h.Version)
+	}
+
+	h.Compression = Compression(buffer[5])
+	h.Codec = quotes.SerialCodec(buffer[6])
+	h.Checksum = binary.BigEndian.Uint32(buffer[7:])
+	h.Length = binary.BigEndian.Uint64(buffer[11:])
+
+	length := binary.BigEndian.Uint32(buffer[19:])
+	if length > formatMaxMetaLength {
+		return fmt.Errorf("format meta length invalid: %d", length)
+	}
+
+	if length > 0 {
+		h.Meta = make([]byte, length)
+		_, err = io.ReadFull(r, h.Meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeFormat encode exchange daily quote with header and compressed payload
+// payload is encoded twice, the first pass only count length and checksum, so it is never buffered in memory
+func encodeFormat(w io.Writer, edq *quotes.ExchangeDailyQuote, format Format) error {
+	meta, err := quotes.MarshalMeta(edq.Meta)
+	if err != nil {
+		zap.L().Error("encode meta failed", zap.Error(err))
+		return err
+	}
+
+	// checksum covers meta too
+	counter := &checksumWriter{hash: crc32.NewIEEE()}
+	counter.hash.Write(meta)
+
+	err = edq.EncodeCodec(counter, format.Codec)
+	if err != nil {
+		zap.L().Error("encode exchange daily quote failed", zap.Error(err))
+		return err
+	}
+
+	header := formatHeader{
+		Version:     formatVersion,
+		Compression: format.Compression,
+		Codec:       format.Codec,
+		Checksum:    counter.hash.Sum32(),
+		Length:      counter.length,
+		Meta:        meta,
+	}
+
+	err = header.encode(w)
+	if err != nil {
+		zap.L().Error("encode format header failed", zap.Error(err))
+		return err
+	}
+
+	cw, err := newCompressWriter(w, format.Compression)
+	if err != nil {
+		zap.L().Error("create compress writer failed", zap.Error(err), zap.Stringer("compression", format.Compression))
+		return err
+	}
+
+	bw := bufio.NewWriter(cw)
+	err = edq.EncodeCodec(bw, format.Codec)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		cw.Close()
+		zap.L().Error("write compressed payload failed", zap.Error(err), zap.Stringer("compression", format.Compression))
+		return err
+	}
+
+	return cw.Close()
+}
+
+// checksumWriter count and checksum bytes written, discard the bytes
+type checksumWriter struct {
+	hash   hash.Hash32
+	length uint64
+}
+
+func (w *checksumWriter) Write(p []byte) (int, error) {
+	w.hash.Write(p)
+	w.length += uint64(len(p))
+
+	return len(p), nil
+}
+
+// decodeFormat decode exchange daily quote, support both legacy and versioned layout
+func decodeFormat(r io.Reader) (*quotes.ExchangeDailyQuote, error) {
+	stream, err := streamFormat(r)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return stream.ReadAll()
+}
+
+// streamFormat open exchange daily quote stream, support both legacy and versioned layout
+// payload length and checksum are verified after the last company daily quote
+func streamFormat(r io.Reader) (*QuoteStream, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(formatMagic))
+	if err != nil && err != io.EOF {
+		zap.L().Error("peek format magic failed", zap.Error(err))
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, formatMagic[:]) {
+		return streamLegacyFormat(br)
+	}
+
+	header := new(formatHeader)
+	err = header.decode(br)
+	if err != nil {
+		zap.L().Error("decode format header failed", zap.Error(err))
+		return nil, err
+	}
+
+	meta, err := quotes.UnmarshalMeta(header.Meta)
+	if err != nil {
+		zap.L().Error("decode meta failed", zap.Error(err))
+		return nil, err
+	}
+
+	cr, err := newDecompressReader(br, header.Compression)
+	if err != nil {
+		zap.L().Error("create decompress reader failed", zap.Error(err), zap.Stringer("compression", header.Compression))
+		return nil, err
+	}
+
+	// checksum covers meta too
+	payload := &checksumReader{r: bufio.NewReader(cr), hash: crc32.NewIEEE()}
+	payload.hash.Write(header.Meta)
+
+	er, err := quotes.NewExchangeDailyQuoteReader(payload, header.Codec)
+	if err != nil {
+		cr.Close()
+		zap.L().Error("decode exchange daily quote failed", zap.Error(err), zap.Stringer("codec", header.Codec))
+		return nil, err
+	}
+
+	stream := newQuoteStream(er, &verifyIterator{er: er, payload: payload, header: header}, cr)
+	stream.Meta = meta
+
+	return stream, nil
+}
+
+// streamLegacyFormat open gzip compressed exchange daily quote stream without header
+func streamLegacyFormat(r io.Reader) (*QuoteStream, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		zap.L().Error("create gzip reader failed", zap.Error(err))
+		return nil, err
+	}
+
+	er, err := quotes.NewExchangeDailyQuoteReader(bufio.NewReader(gr), quotes.SerialCodecRow)
+	if err != nil {
+		gr.Close()
+		zap.L().Error("decode exchange daily quote failed", zap.Error(err))
+		return nil, err
+	}
+
+	return newQuoteStream(er, er, gr), nil
+}
+
+// checksumReader count and checksum bytes read
+type checksumReader struct {
+	r      io.Reader
+	hash   hash.Hash32
+	length uint64
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.hash.Write(p[:n])
+	r.length += uint64(n)
+
+	return n, err
+}
+
+// verifyIterator verify payload length and checksum after the last company daily quote
+type verifyIterator struct {
+	er      *quotes.ExchangeDailyQuoteReader
+	payload *checksumReader
+	header  *formatHeader
+}
+
+// NextCompanyQuote get next company daily quote, return io.EOF after the last one
+func (i *verifyIterator) NextCompanyQuote() (*quotes.CompanyDailyQuote, error) {
+	cdq, err := i.er.NextCompanyQuote()
+	if err != io.EOF {
+		return cdq, err
+	}
+
+	// payload must end with the last company daily quote
+	_, err = io.Copy(io.Discard, i.payload)
+	if err != nil {
+		zap.L().Error("decompress payload failed", zap.Error(err), zap.Stringer("compression", i.header.Compression))
+		return nil, err
+	}
+
+	if i.payload.length != i.header.Length {
+		return nil, fmt.Errorf("payload length %d is different from header %d", i.payload.length, i.header.Length)
+	}
+
+	checksum := i.payload.hash.Sum32()
+	if checksum != i.header.Checksum {
+		return nil, fmt.Errorf("meta and payload checksum %08x is different from header %08x", checksum, i.header.Checksum)
+	}
+
+	return nil, io.EOF
+}
+
+// nopWriteCloser wrap writer with no-op Close
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// newCompressWriter create writer compress data by codec
+func newCompressWriter(w io.Writer, compression Compression) (io.WriteCloser, error) {
+	switch compression {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriterLevel(w, gzip.BestCompression)
+	case CompressionZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	default:
+		return nil, fmt.Errorf("compression unsupported: %s", compression)
+	}
+}
+
+// newDecompressReader create reader decompress data by codec
+func newDecompressReader(r io.Reader, compression Compression) (io.ReadCloser, error) {
+	switch compression {
+	case CompressionNone:
+		return io.NopCloser(r), nil
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("compression unsupported: %s", compression)
+	}
+}
@@ -0,0 +1,213 @@
+package stores
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestFormat(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		for _, codec := range []quotes.SerialCodec{quotes.SerialCodecRow, quotes.SerialCodecColumnar} {
+			format := Format{Compression: compression, Codec: codec}
+			t.Run(compression.String()+"/"+codec.String(), func(t *testing.T) {
+				buffer := new(bytes.Buffer)
+				err := encodeFormat(buffer, edq, format)
+				if err != nil {
+					t.Fatalf("encodeFormat() error = %v", err)
+				}
+
+				decoded, err := decodeFormat(bytes.NewReader(buffer.Bytes()))
+				if err != nil {
+					t.Fatalf("decodeFormat() error = %v", err)
+				}
+
+				err = edq.Equal(*decoded)
+				if err != nil {
+					t.Errorf("decodeFormat() not equal: %v", err)
+				}
+
+				// corrupt last byte of payload
+				corrupted := buffer.Bytes()
+				corrupted[len(corrupted)-1] ^= 0xff
+				_, err = decodeFormat(bytes.NewReader(corrupted))
+				if err == nil {
+					t.Errorf("decodeFormat() corrupted payload error = nil")
+				}
+			})
+		}
+	}
+
+	t.Run("legacy", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		gw := gzip.NewWriter(buffer)
+		err := edq.Encode(gw)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		gw.Close()
+
+		decoded, err := decodeFormat(buffer)
+		if err != nil {
+			t.Fatalf("decodeFormat() error = %v", err)
+		}
+
+		err = edq.Equal(*decoded)
+		if err != nil {
+			t.Errorf("decodeFormat() not equal: %v", err)
+		}
+	})
+
+	t.Run("meta checksum", func(t *testing.T) {
+		edq := testExchangeDailyQuote(exchange, date, "AAPL")
+		edq.Meta = quotes.NewMeta("v0.1.0", date, date, 1, 0)
+
+		buffer := new(bytes.Buffer)
+		err := encodeFormat(buffer, edq, DefaultFormat)
+		if err != nil {
+			t.Fatalf("encodeFormat() error = %v", err)
+		}
+
+		decoded, err := decodeFormat(bytes.NewReader(buffer.Bytes()))
+		if err != nil {
+			t.Fatalf("decodeFormat() error = %v", err)
+		}
+
+		if decoded.Meta == nil || decoded.Meta.Software != edq.Meta.Software {
+			t.Errorf("decodeFormat() meta = %+v, want %+v", decoded.Meta, edq.Meta)
+		}
+
+		// meta still parses after corruption, only checksum catches it
+		corrupted := bytes.Replace(buffer.Bytes(), []byte("v0.1.0"), []byte("v0.1.1"), 1)
+		_, err = decodeFormat(bytes.NewReader(corrupted))
+		if err == nil {
+			t.Errorf("decodeFormat() corrupted meta error = nil")
+		}
+	})
+
+	t.Run("meta length", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		header := formatHeader{Version: formatVersion, Compression: CompressionNone, Codec: quotes.SerialCodecRow}
+		err := header.encode(buffer)
+		if err != nil {
+			t.Fatalf("encode() error = %v", err)
+		}
+
+		// meta length is the last 4 bytes of header without meta
+		corrupted := buffer.Bytes()
+		binary.BigEndian.PutUint32(corrupted[len(corrupted)-4:], 0xffffffff)
+		_, err = decodeFormat(bytes.NewReader(corrupted))
+		if err == nil {
+			t.Errorf("decodeFormat() oversized meta length error = nil")
+		}
+	})
+}
+
+// benchmarkExchangeDailyQuote load real exchange daily quote file from QR_BENCH_FILE,
+// or generate random walk one minute quotes of 500 companies
+func benchmarkExchangeDailyQuote(b *testing.B) *quotes.ExchangeDailyQuote {
+	path := os.Getenv("QR_BENCH_FILE")
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			b.Fatalf("open %s error = %v", path, err)
+		}
+		defer file.Close()
+
+		edq, err := decodeFormat(file)
+		if err != nil {
+			b.Fatalf("decodeFormat() error = %v", err)
+		}
+
+		return edq
+	}
+
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	open := uint64(date.Add(time.Hour * 9).Add(time.Minute * 30).Unix())
+	random := rand.New(rand.NewSource(1))
+
+	edq := &quotes.ExchangeDailyQuote{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: make(map[string]*quotes.Company),
+		Quotes:    make(map[string]*quotes.CompanyDailyQuote),
+	}
+
+	for index := 0; index < 500; index++ {
+		company := &quotes.Company{Code: string(rune('A'+index%26)) + string(rune('A'+index/26)), Name: "Company"}
+		price := 10 + random.Float64()*200
+
+		regular := make(quotes.Serial, 390)
+		for minute := range regular {
+			next := price * (1 + random.NormFloat64()*0.001)
+			cents := func(value float64) float32 { return float32(int(value*100)) / 100 }
+			regular[minute] = quotes.Quote{
+				Timestamp: open + uint64(minute*60),
+				Open:      cents(price),
+				Close:     cents(next),
+				High:      cents(price + random.Float64()*price*0.001 + next - price),
+				Low:       cents(price - random.Float64()*price*0.001),
+				Volume:    uint64(random.Intn(100000)),
+			}
+			price = next
+		}
+
+		edq.Companies[company.Code] = company
+		edq.Quotes[company.Code] = &quotes.CompanyDailyQuote{
+			Company: company,
+			Pre:     &quotes.Serial{},
+			Regular: &regular,
+			Post:    &quotes.Serial{},
+		}
+	}
+
+	return edq
+}
+
+func BenchmarkFormat(b *testing.B) {
+	edq := benchmarkExchangeDailyQuote(b)
+
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		for _, codec := range []quotes.SerialCodec{quotes.SerialCodecRow, quotes.SerialCodecColumnar} {
+			format := Format{Compression: compression, Codec: codec}
+			buffer := new(bytes.Buffer)
+			err := encodeFormat(buffer, edq, format)
+			if err != nil {
+				b.Fatalf("encodeFormat() error = %v", err)
+			}
+			encoded := buffer.Bytes()
+
+			b.Run("encode/"+compression.String()+"/"+codec.String(), func(b *testing.B) {
+				b.ReportMetric(float64(len(encoded)), "file-bytes")
+				for index := 0; index < b.N; index++ {
+					err := encodeFormat(new(bytes.Buffer), edq, format)
+					if err != nil {
+						b.Fatalf("encodeFormat() error = %v", err)
+					}
+				}
+			})
+
+			b.Run("decode/"+compression.String()+"/"+codec.String(), func(b *testing.B) {
+				b.ReportMetric(float64(len(encoded)), "file-bytes")
+				for index := 0; index < b.N; index++ {
+					_, err := decodeFormat(bytes.NewReader(encoded))
+					if err != nil {
+						b.Fatalf("decodeFormat() error = %v", err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -3,6 +3,8 @@ package stores
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	_ "github.com/influxdata/influxdb1-client" // this is important because of the bug in go mod
@@ -17,8 +19,11 @@ const (
 	companiesMeasurementName     = "companies"
 	dividendMeasurementName      = "dividends"
 	splitMeasurementName         = "splits"
+	actionMeasurementName        = "actions"
 	minutelyQuoteMeasurementName = "q1m"
 	dailyQuoteMeasurementName    = "q1d"
+	metaMeasurementName          = "metas"
+	companyMetaMeasurementName   = "company_metas"
 )
 
 // InfluxDB influxdb store
@@ -27,6 +32,35 @@ type InfluxDB struct {
 	db     string
 }
 
+func init() {
+	Register("influxdb", openInfluxDB)
+}
+
+// openInfluxDB open influxdb store by dsn like influxdb://127.0.0.1:8086/quotes?tls=false
+func openInfluxDB(dsn *DSN) (Store, error) {
+	err := dsn.Validate("tls")
+	if err != nil {
+		return nil, err
+	}
+
+	tls, err := dsn.Bool("tls", false)
+	if err != nil {
+		return nil, err
+	}
+
+	address := "http://" + dsn.Host
+	if tls {
+		address = "https://" + dsn.Host
+	}
+
+	db := strings.TrimPrefix(dsn.Path, "/")
+	if db == "" {
+		return nil, fmt.Errorf("influxdb database undefined")
+	}
+
+	return NewInfluxDB(address, db), nil
+}
+
 // NewInfluxDB create new influxdb store
 func NewInfluxDB(address, db string) *InfluxDB {
 	c, err := client.NewHTTPClient(client.HTTPConfig{Addr: address})
@@ -108,6 +142,11 @@ func (s InfluxDB) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.
 		batchPoints = append(batchPoints, points...)
 	}
 
+	if edq.Meta != nil {
+		points = s.createMetaPoints(exchange, date, edq.Meta)
+		batchPoints = append(batchPoints, points...)
+	}
+
 	batchSize := 10240
 	var start, end int
 	for start = 0; start < len(batchPoints); start += batchSize {
@@ -136,6 +175,80 @@ func (s InfluxDB) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.
 	return nil
 }
 
+// Replace replace exchange daily quote, the new day is written before previous bars not in it are deleted,
+// quotes of companies not in the new day are deleted too
+func (s InfluxDB) Replace(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	exists, err := s.Exists(exchange, date)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return s.Save(exchange, date, edq)
+	}
+
+	previous, err := s.Load(exchange, date)
+	if err != nil {
+		return err
+	}
+
+	err = s.Save(exchange, date, edq)
+	if err != nil {
+		return err
+	}
+
+	dateText := date.Format(constants.DatePattern)
+	var commands []string
+	for code, cdq := range previous.Quotes {
+		current, found := edq.Quotes[code]
+		if !found {
+			current = new(quotes.CompanyDailyQuote)
+			commands = append(commands, fmt.Sprintf("drop series from %s, %s, %s where exchange='%s' and date='%s' and company='%s'",
+				dailyQuoteMeasurementName,
+				actionMeasurementName,
+				companyMetaMeasurementName,
+				exchange.Code(),
+				dateText,
+				code))
+		}
+
+		for _, stale := range staleRanges(cdq, current) {
+			commands = append(commands, fmt.Sprintf("delete from %s where exchange='%s' and date='%s' and company='%s' and time>=%ds and time<=%ds",
+				minutelyQuoteMeasurementName,
+				exchange.Code(),
+				dateText,
+				code,
+				stale.Start,
+				stale.End))
+		}
+	}
+
+	batchSize := 256
+	for start := 0; start < len(commands); start += batchSize {
+		end := start + batchSize
+		if end > len(commands) {
+			end = len(commands)
+		}
+
+		command := strings.Join(commands[start:end], ";")
+		response, err := s.client.Query(client.NewQuery(command, s.db, ""))
+		if err == nil {
+			err = response.Error()
+		}
+
+		if err != nil {
+			zap.L().Error("delete stale exchange daily quote failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date),
+				zap.Int("commands", end-start))
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s InfluxDB) createCompanyPoints(exchange exchanges.Exchange, date time.Time, companies map[string]*quotes.Company) []*client.Point {
 	tags := map[string]string{
 		"exchange": exchange.Code(),
@@ -157,6 +270,47 @@ func (s InfluxDB) createCompanyPoints(exchange exchanges.Exchange, date time.Tim
 	return points
 }
 
+// createMetaPoints create exchange daily meta point and one company meta point per company
+func (s InfluxDB) createMetaPoints(exchange exchanges.Exchange, date time.Time, meta *quotes.Meta) []*client.Point {
+	tags := map[string]string{
+		"exchange": exchange.Code(),
+		"date":     date.Format(constants.DatePattern),
+	}
+
+	points := make([]*client.Point, 0, len(meta.Companies)+1)
+	p, _ := client.NewPoint(metaMeasurementName, tags, map[string]interface{}{
+		"version":     meta.Version,
+		"software":    meta.Software,
+		"crawl_start": meta.CrawlStart.UnixNano(),
+		"crawl_end":   meta.CrawlEnd.UnixNano(),
+		"attempted":   meta.Attempted,
+		"failed":      meta.Failed,
+		"resolution":  int64(meta.Resolution),
+		"quality":     formatQuality(meta.Quality),
+	}, date)
+	points = append(points, p)
+
+	for code, cm := range meta.Companies {
+		p, _ = client.NewPoint(companyMetaMeasurementName, map[string]string{
+			"exchange": exchange.Code(),
+			"date":     date.Format(constants.DatePattern),
+			"company":  code,
+		}, map[string]interface{}{
+			"source":          cm.Source,
+			"event_source":    cm.EventSource,
+			"currency":        cm.Currency,
+			"previous_close":  cm.PreviousClose,
+			"exchange_name":   cm.ExchangeName,
+			"instrument_type": cm.InstrumentType,
+			"timezone":        cm.Timezone,
+			"issues":          formatIssues(cm.Issues),
+		}, date)
+		points = append(points, p)
+	}
+
+	return points
+}
+
 func (s InfluxDB) creteCompanyDailQuotePoints(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) []*client.Point {
 	points := make([]*client.Point, 0, len(*cdq.Pre)+len(*cdq.Regular)+len(*cdq.Post)+5)
 
@@ -176,23 +330,16 @@ func (s InfluxDB) creteCompanyDailQuotePoints(exchange exchanges.Exchange, date
 	}, date)
 	points = append(points, rollupPoint)
 
-	if cdq.Dividend != nil {
-		dividend, _ := client.NewPoint(dividendMeasurementName, tags, map[string]interface{}{
-			"enable":    cdq.Dividend.Enable,
-			"amount":    cdq.Dividend.Amount,
-			"timestamp": int64(cdq.Dividend.Timestamp),
-		}, date)
-		points = append(points, dividend)
-	}
-
-	if cdq.Split != nil && cdq.Split.Enable {
-		split, _ := client.NewPoint(splitMeasurementName, tags, map[string]interface{}{
-			"enable":      cdq.Split.Enable,
-			"numerator":   cdq.Split.Numerator,
-			"denominator": cdq.Split.Denominator,
-			"timestamp":   int64(cdq.Split.Timestamp),
-		}, date)
-		points = append(points, split)
+	// dividends and splits measurements are only read, actions of a day are points at date plus sequence nanoseconds
+	for seq, action := range cdq.Actions {
+		point, _ := client.NewPoint(actionMeasurementName, tags, map[string]interface{}{
+			"type":        action.Type.String(),
+			"timestamp":   int64(action.Timestamp),
+			"amount":      action.Amount,
+			"numerator":   action.Numerator,
+			"denominator": action.Denominator,
+		}, date.Add(time.Duration(seq)))
+		points = append(points, point)
 	}
 
 	points = append(points, s.createQuoteSerialPoints(cdq.Pre, quotes.SerialTypePre, tags)...)
@@ -240,14 +387,170 @@ func (s InfluxDB) Load(exchange exchanges.Exchange, date time.Time) (*quotes.Exc
 		cdqs[cdq.Company.Code] = cdq
 	}
 
+	meta, err := s.loadMeta(exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
 	return &quotes.ExchangeDailyQuote{
 		Exchange:  exchange.Code(),
 		Date:      date,
 		Companies: companies,
 		Quotes:    cdqs,
+		Meta:      meta,
 	}, nil
 }
 
+// loadMeta load exchange daily meta, return nil if not exists
+func (s InfluxDB) loadMeta(exchange exchanges.Exchange, date time.Time) (*quotes.Meta, error) {
+	command := fmt.Sprintf("select version, software, crawl_start, crawl_end, attempted, failed, resolution, quality from %s where exchange='%s' and date='%s'",
+		metaMeasurementName,
+		exchange.Code(),
+		date.Format(constants.DatePattern))
+
+	values, err := s.queryValues(command)
+	if err != nil {
+		zap.L().Error("query exchange daily meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	if len(values) == 0 || len(values[0]) < 7 {
+		return nil, nil
+	}
+
+	meta := &quotes.Meta{
+		Version:    int(influxInt64(values[0][1])),
+		Software:   influxString(values[0][2]),
+		CrawlStart: time.Unix(0, influxInt64(values[0][3])),
+		CrawlEnd:   time.Unix(0, influxInt64(values[0][4])),
+		Attempted:  int(influxInt64(values[0][5])),
+		Failed:     int(influxInt64(values[0][6])),
+		Companies:  make(map[string]*quotes.CompanyMeta),
+	}
+
+	// resolution column not exists before any downsampled day saved
+	if len(values[0]) > 7 {
+		meta.Resolution = time.Duration(influxInt64(values[0][7]))
+	}
+
+	// quality column not exists in days saved by older versions
+	if len(values[0]) > 8 {
+		meta.Quality, err = parseQuality(influxString(values[0][8]))
+		if err != nil {
+			zap.L().Error("parse exchange daily quality failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return nil, err
+		}
+	}
+
+	command = fmt.Sprintf("select source, event_source, currency, previous_close, exchange_name, instrument_type, timezone, issues from %s where exchange='%s' and date='%s' group by company",
+		companyMetaMeasurementName,
+		exchange.Code(),
+		date.Format(constants.DatePattern))
+
+	response, err := s.client.Query(client.NewQuery(command, s.db, ""))
+	if err == nil {
+		err = response.Error()
+	}
+	if err != nil {
+		zap.L().Error("query company daily meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	if len(response.Results) == 0 {
+		return meta, nil
+	}
+
+	for _, series := range response.Results[0].Series {
+		code := series.Tags["company"]
+		if code == "" || len(series.Values) == 0 || len(series.Values[0]) < 8 {
+			continue
+		}
+
+		values := series.Values[0]
+		previousClose, _ := values[4].(json.Number).Float64()
+		meta.Companies[code] = &quotes.CompanyMeta{
+			Source:         influxString(values[1]),
+			EventSource:    influxString(values[2]),
+			Currency:       influxString(values[3]),
+			PreviousClose:  float32(previousClose),
+			ExchangeName:   influxString(values[5]),
+			InstrumentType: influxString(values[6]),
+			Timezone:       influxString(values[7]),
+		}
+
+		// issues column not exists in days saved by older versions
+		if len(values) > 8 {
+			meta.Companies[code].Issues, err = parseIssues(influxString(values[8]))
+			if err != nil {
+				zap.L().Error("parse company issues failed",
+					zap.Error(err),
+					zap.String("exchange", exchange.Code()),
+					zap.String("company", code),
+					zap.Time("date", date))
+				return nil, err
+			}
+		}
+	}
+
+	return meta, nil
+}
+
+// queryValues query command and return values of first series
+func (s InfluxDB) queryValues(command string) ([][]interface{}, error) {
+	response, err := s.client.Query(client.NewQuery(command, s.db, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	err = response.Error()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Results) == 0 || len(response.Results[0].Series) == 0 {
+		return nil, nil
+	}
+
+	return response.Results[0].Series[0].Values, nil
+}
+
+// influxString convert query value to string, return empty string if invalid
+func influxString(value interface{}) string {
+	text, _ := value.(string)
+	return text
+}
+
+// influxFloat32 convert query value to float32, return 0 if invalid
+func influxFloat32(value interface{}) float32 {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0
+	}
+
+	f, _ := number.Float64()
+	return float32(f)
+}
+
+// influxInt64 convert query value to int64, return 0 if invalid
+func influxInt64(value interface{}) int64 {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0
+	}
+
+	n, _ := number.Int64()
+	return n
+}
+
 func (s InfluxDB) loadCompanies(exchange exchanges.Exchange, date time.Time) (map[string]*quotes.Company, error) {
 	command := fmt.Sprintf("select code, \"name\" from %s where exchange='%s' and date='%s'",
 		companiesMeasurementName,
@@ -311,6 +614,11 @@ func (s InfluxDB) loadCompanyDailyQuote(exchange exchanges.Exchange, date time.T
 		return nil, err
 	}
 
+	actions, err := s.loadActions(exchange, date, company)
+	if err != nil {
+		return nil, err
+	}
+
 	pre, err := s.loadCompanyDailyQuoteSerial(exchange, date, company, quotes.SerialTypePre)
 	if err != nil {
 		return nil, err
@@ -327,12 +635,11 @@ func (s InfluxDB) loadCompanyDailyQuote(exchange exchanges.Exchange, date time.T
 	}
 
 	return &quotes.CompanyDailyQuote{
-		Company:  company,
-		Dividend: dividend,
-		Split:    split,
-		Pre:      pre,
-		Regular:  regular,
-		Post:     post,
+		Company: company,
+		Actions: mergeActions(actions, dividend, split),
+		Pre:     pre,
+		Regular: regular,
+		Post:    post,
 	}, nil
 }
 
@@ -601,6 +908,51 @@ func (s InfluxDB) loadSplit(exchange exchanges.Exchange, date time.Time, company
 	}, nil
 }
 
+func (s InfluxDB) loadActions(exchange exchanges.Exchange, date time.Time, company *quotes.Company) (quotes.Actions, error) {
+	command := fmt.Sprintf("select type, timestamp, amount, numerator, denominator from %s where exchange='%s' and company='%s' and date='%s'",
+		actionMeasurementName,
+		exchange.Code(),
+		company.Code,
+		date.Format(constants.DatePattern))
+
+	values, err := s.queryValues(command)
+	if err != nil {
+		zap.L().Error("query company actions failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", company.Code),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	var actions quotes.Actions
+	for _, value := range values {
+		if len(value) != 6 {
+			continue
+		}
+
+		actionType, err := quotes.ParseActionType(influxString(value[1]))
+		if err != nil {
+			zap.L().Error("invalid action type",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", company.Code),
+				zap.Time("date", date))
+			return nil, err
+		}
+
+		actions = append(actions, quotes.Action{
+			Type:        actionType,
+			Timestamp:   uint64(influxInt64(value[2])),
+			Amount:      influxFloat32(value[3]),
+			Numerator:   influxFloat32(value[4]),
+			Denominator: influxFloat32(value[5]),
+		})
+	}
+
+	return actions, nil
+}
+
 func (s InfluxDB) loadCompanyDailyQuoteSerial(exchange exchanges.Exchange, date time.Time, company *quotes.Company, st quotes.SerialType) (*quotes.Serial, error) {
 	command := fmt.Sprintf("select open, close, high, low, volume from %s where exchange='%s' and company='%s' and serial='%s' and date='%s'",
 		minutelyQuoteMeasurementName,
@@ -730,6 +1082,219 @@ func (s InfluxDB) loadCompanyDailyQuoteSerial(exchange exchanges.Exchange, date
 	return &_serial, nil
 }
 
+// LoadCompany load company daily quotes between start and end date
+func (s InfluxDB) LoadCompany(exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	dates := exchangeDates(exchange, start, end)
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	companies, err := s.loadCompanyRange(exchange, companyCode, dates[0], dates[len(dates)-1].AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	var cdqs []*quotes.CompanyDailyQuote
+	for _, date := range dates {
+		company, found := companies[date.Format(constants.DatePattern)]
+		if !found {
+			continue
+		}
+
+		cdq, err := s.loadCompanyDailyQuote(exchange, date, company)
+		if err == constants.ErrRecordNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		cdqs = append(cdqs, cdq)
+	}
+
+	return cdqs, nil
+}
+
+// loadCompanyRange load company by listed date in [start, end)
+func (s InfluxDB) loadCompanyRange(exchange exchanges.Exchange, companyCode string, start, end time.Time) (map[string]*quotes.Company, error) {
+	command := fmt.Sprintf("select code, \"name\", \"date\" from %s where exchange='%s' and code='%s' and time>=%ds and time<%ds",
+		companiesMeasurementName,
+		exchange.Code(),
+		companyCode,
+		start.Unix(),
+		end.Unix())
+
+	response, err := s.client.Query(client.NewQuery(command, s.db, ""))
+	if err != nil {
+		zap.L().Error("query company failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	err = response.Error()
+	if err != nil {
+		zap.L().Error("query company failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	companies := make(map[string]*quotes.Company)
+	if len(response.Results) == 0 || len(response.Results[0].Series) == 0 {
+		return companies, nil
+	}
+
+	for _, values := range response.Results[0].Series[0].Values {
+		if len(values) != 4 {
+			continue
+		}
+
+		code, _ := values[1].(string)
+		name, _ := values[2].(string)
+		date, _ := values[3].(string)
+
+		companies[date] = &quotes.Company{Code: code, Name: name}
+	}
+
+	return companies, nil
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s InfluxDB) Exchanges() ([]string, error) {
+	command := fmt.Sprintf("show tag values from %s with key = \"exchange\"", companiesMeasurementName)
+	codes, err := s.queryTagValues(command)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(codes)
+
+	return codes, nil
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s InfluxDB) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	command := fmt.Sprintf("show tag values from %s with key = \"date\" where exchange='%s'",
+		companiesMeasurementName,
+		exchange.Code())
+	texts, err := s.queryTagValues(command)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, 0, len(texts))
+	for _, text := range texts {
+		date, ok := parseDate(exchange, text, start, end)
+		if !ok {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	return dates, nil
+}
+
+// Stat get stored exchange daily quote stats
+func (s InfluxDB) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	companies, err := s.queryCount(fmt.Sprintf("select count(code) from %s where exchange='%s' and date='%s'",
+		companiesMeasurementName,
+		exchange.Code(),
+		date.Format(constants.DatePattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	cdqs, err := s.queryCount(fmt.Sprintf("select count(open) from %s where exchange='%s' and date='%s'",
+		dailyQuoteMeasurementName,
+		exchange.Code(),
+		date.Format(constants.DatePattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DailyStat{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: int(companies),
+		Quotes:    int(cdqs),
+	}, nil
+}
+
+// queryTagValues query show tag values command and return values
+func (s InfluxDB) queryTagValues(command string) ([]string, error) {
+	response, err := s.client.Query(client.NewQuery(command, s.db, ""))
+	if err != nil {
+		zap.L().Error("query tag values failed", zap.Error(err), zap.String("command", command))
+		return nil, err
+	}
+
+	err = response.Error()
+	if err != nil {
+		zap.L().Error("query tag values failed", zap.Error(err), zap.String("command", command))
+		return nil, err
+	}
+
+	if len(response.Results) == 0 || len(response.Results[0].Series) == 0 {
+		return nil, nil
+	}
+
+	// values: [key, value]
+	values := make([]string, 0, len(response.Results[0].Series[0].Values))
+	for _, value := range response.Results[0].Series[0].Values {
+		if len(value) != 2 {
+			continue
+		}
+
+		text, ok := value[1].(string)
+		if ok {
+			values = append(values, text)
+		}
+	}
+
+	return values, nil
+}
+
+// queryCount query count command and return count
+func (s InfluxDB) queryCount(command string) (int64, error) {
+	response, err := s.client.Query(client.NewQuery(command, s.db, ""))
+	if err != nil {
+		zap.L().Error("query count failed", zap.Error(err), zap.String("command", command))
+		return 0, err
+	}
+
+	err = response.Error()
+	if err != nil {
+		zap.L().Error("query count failed", zap.Error(err), zap.String("command", command))
+		return 0, err
+	}
+
+	if len(response.Results) == 0 ||
+		len(response.Results[0].Series) == 0 ||
+		len(response.Results[0].Series[0].Values) == 0 ||
+		len(response.Results[0].Series[0].Values[0]) != 2 {
+		return 0, nil
+	}
+
+	count, err := response.Results[0].Series[0].Values[0][1].(json.Number).Int64()
+	if err != nil {
+		zap.L().Error("parse count failed", zap.Error(err), zap.String("command", command))
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // Delete delete exchange daily quote
 func (s InfluxDB) Delete(exchange exchanges.Exchange, date time.Time) error {
 	commands := []string{
@@ -745,6 +1310,10 @@ func (s InfluxDB) Delete(exchange exchanges.Exchange, date time.Time) error {
 			splitMeasurementName,
 			exchange.Code(),
 			date.Format(constants.DatePattern)),
+		fmt.Sprintf("drop series from %s where exchange='%s' and date='%s'",
+			actionMeasurementName,
+			exchange.Code(),
+			date.Format(constants.DatePattern)),
 		fmt.Sprintf("drop series from %s where exchange='%s' and date='%s'",
 			minutelyQuoteMeasurementName,
 			exchange.Code(),
@@ -753,6 +1322,14 @@ func (s InfluxDB) Delete(exchange exchanges.Exchange, date time.Time) error {
 			dailyQuoteMeasurementName,
 			exchange.Code(),
 			date.Format(constants.DatePattern)),
+		fmt.Sprintf("drop series from %s where exchange='%s' and date='%s'",
+			metaMeasurementName,
+			exchange.Code(),
+			date.Format(constants.DatePattern)),
+		fmt.Sprintf("drop series from %s where exchange='%s' and date='%s'",
+			companyMetaMeasurementName,
+			exchange.Code(),
+			date.Format(constants.DatePattern)),
 	}
 
 	for _, command := range commands {
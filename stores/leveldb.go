@@ -20,14 +20,34 @@ import (
 // exchange daily companies		key: {exchange}:{date}:{companyCode}								value:{companyName}
 // company daily rollup quote	key: {exchange}:{companyCode}:{date} 								value:{open},{close},{high},{low},{volume}
 // company daily quote serial	key: {exchange}:{companyCode}:{date}:{Pre|Regular|Post}:{timestamp}	value:{open},{close},{high},{low},{volume}
-// company daily dividend		key: {exchange}:{companyCode}:dividend:{date}						value:{timestamp},{amount}
-// company daily split			key: {exchange}:{companyCode}:split:{date}							value:{timestamp},{numerator},{denominator}
+// company daily dividend		key: {exchange}:{companyCode}:dividend:{date}						value:{timestamp},{amount} (written before actions, only read)
+// company daily split			key: {exchange}:{companyCode}:split:{date}							value:{timestamp},{numerator},{denominator} (written before actions, only read)
+// company daily actions		key: {exchange}:{companyCode}:actions:{date}						value:{type},{timestamp},{amount},{numerator},{denominator};...
+// exchange daily meta			key: {exchange}:meta:{date}											value:{meta json}
 
 // LevelDB level db store
 type LevelDB struct {
 	db *leveldb.DB
 }
 
+func init() {
+	Register("leveldb", openLevelDB)
+}
+
+// openLevelDB open leveldb store by dsn like leveldb:///data/leveldb
+func openLevelDB(dsn *DSN) (Store, error) {
+	err := dsn.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if dsn.Location() == "" {
+		return nil, fmt.Errorf("leveldb store root undefined")
+	}
+
+	return NewLevelDB(dsn.Location()), nil
+}
+
 var (
 	levelDBReadOption = &opt.ReadOptions{DontFillCache: true}
 )
@@ -58,8 +78,25 @@ func (s LevelDB) Exists(exchange exchanges.Exchange, date time.Time) (bool, erro
 
 // Save save exchange daily quote
 func (s LevelDB) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return s.save(exchange, date, edq, false)
+}
+
+// Replace replace exchange daily quote in one transaction, keys of the previous day are removed with the new day written
+func (s LevelDB) Replace(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return s.save(exchange, date, edq, true)
+}
+
+// save save exchange daily quote in one transaction, remove keys of the previous day first if replace
+func (s LevelDB) save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote, replace bool) error {
 	// create quote save batch
-	batch := s.createSaveBatch(exchange, date, edq)
+	batch, err := s.createSaveBatch(exchange, date, edq)
+	if err != nil {
+		zap.L().Error("create save batch failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
 	// zap.L().Debug("create save batch success",
 	// 	zap.String("exchange", exchange.Code()),
 	// 	zap.Time("date", date),
@@ -75,6 +112,13 @@ func (s LevelDB) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.E
 	}
 	defer trans.Discard()
 
+	if replace {
+		err = s.deletePrevious(trans, exchange, date)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = trans.Write(batch, nil)
 	if err != nil {
 		zap.L().Error("batch save failed",
@@ -127,14 +171,60 @@ func (s LevelDB) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.E
 	return nil
 }
 
-func (s LevelDB) createSaveBatch(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) *leveldb.Batch {
+// deletePrevious delete keys of stored exchange daily quote in transaction, do nothing if not exists
+func (s LevelDB) deletePrevious(trans *leveldb.Transaction, exchange exchanges.Exchange, date time.Time) error {
+	exists, err := trans.Has([]byte(fmt.Sprintf("%s:%s", exchange.Code(), date.Format(constants.DatePattern))), nil)
+	if err != nil {
+		zap.L().Error("check exchange daily quote exists failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	if !exists {
+		return nil
+	}
+
+	previous, err := s.load(trans, exchange, date)
+	if err != nil {
+		return err
+	}
+
+	err = trans.Write(s.createDeleteBatch(exchange, date, previous), nil)
+	if err != nil {
+		zap.L().Error("batch delete failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+func (s LevelDB) createSaveBatch(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) (*leveldb.Batch, error) {
 	batch := new(leveldb.Batch)
 
+	// save exchange daily meta, meta of the previous save is removed if there is none
+	// key: {exchange}:meta:{date} value:{meta json}
+	meta, err := quotes.MarshalMeta(edq.Meta)
+	if err != nil {
+		return nil, err
+	}
+
+	metaKey := []byte(fmt.Sprintf("%s:meta:%s", exchange.Code(), date.Format(constants.DatePattern)))
+	if meta != nil {
+		batch.Put(metaKey, meta)
+	} else {
+		batch.Delete(metaKey)
+	}
+
 	// save exchange daily
 	// key: {exchange}:{date} value:1 / 0 (is trading day)
 	if edq.IsEmpty() {
 		batch.Put([]byte(fmt.Sprintf("%s:%s", exchange.Code(), date.Format(constants.DatePattern))), []byte{0})
-		return batch
+		return batch, nil
 	}
 	batch.Put([]byte(fmt.Sprintf("%s:%s", exchange.Code(), date.Format(constants.DatePattern))), []byte{1})
 
@@ -146,40 +236,43 @@ func (s LevelDB) createSaveBatch(exchange exchanges.Exchange, date time.Time, ed
 
 	// save exchange daily company quotes
 	for _, cdq := range edq.Quotes {
-		// save company rollup
-		// key: {exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
-		rollup := cdq.Regular.Rollup()
-		batch.Put([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))),
-			s.createQuoteBuffer(*rollup))
-
-		// save dividend
-		// key: {exchange}:{companyCode}:dividend:{date} value:{timestamp},{amount}
-		if cdq.Dividend != nil && cdq.Dividend.Enable {
-			batch.Put([]byte(fmt.Sprintf("%s:%s:dividend:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))),
-				[]byte(fmt.Sprintf("%d,%f", cdq.Dividend.Timestamp, cdq.Dividend.Amount)))
-		}
+		s.putCompanyQuote(batch, exchange, date, cdq)
+	}
 
-		// save split
-		// key: {exchange}:{companyCode}:split:{date} value:{timestamp},{numerator},{denominator}
-		if cdq.Split != nil && cdq.Split.Enable {
-			batch.Put([]byte(fmt.Sprintf("%s:%s:split:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))),
-				[]byte(fmt.Sprintf("%d,%f,%f", cdq.Split.Timestamp, cdq.Split.Numerator, cdq.Split.Denominator)))
-		}
+	return batch, nil
+}
 
-		// save pre
-		// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
-		s.saveCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypePre, cdq.Pre)
+// putCompanyQuote put company daily quote keys to batch
+func (s LevelDB) putCompanyQuote(batch *leveldb.Batch, exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) {
+	// save company rollup
+	// key: {exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
+	rollup := cdq.Regular.Rollup()
+	batch.Put([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))),
+		s.createQuoteBuffer(*rollup))
 
-		// save regular
-		// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
-		s.saveCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypeRegular, cdq.Regular)
+	// delete dividend and split written before actions, or they are merged into the saved actions again on load
+	for _, name := range []string{"dividend", "split"} {
+		batch.Delete([]byte(fmt.Sprintf("%s:%s:%s:%s", exchange.Code(), cdq.Company.Code, name, date.Format(constants.DatePattern))))
+	}
 
-		// save post
-		// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
-		s.saveCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypePost, cdq.Post)
+	// save actions
+	// key: {exchange}:{companyCode}:actions:{date} value:{type},{timestamp},{amount},{numerator},{denominator};...
+	if len(cdq.Actions) > 0 {
+		batch.Put([]byte(fmt.Sprintf("%s:%s:actions:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))),
+			[]byte(formatActions(cdq.Actions)))
 	}
 
-	return batch
+	// save pre
+	// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
+	s.saveCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypePre, cdq.Pre)
+
+	// save regular
+	// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
+	s.saveCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypeRegular, cdq.Regular)
+
+	// save post
+	// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
+	s.saveCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypePost, cdq.Post)
 }
 
 func (s LevelDB) saveCompanyDailyQuoteSerial(batch *leveldb.Batch, exchange exchanges.Exchange, company *quotes.Company, date time.Time, serialType quotes.SerialType, serial *quotes.Serial) {
@@ -220,6 +313,16 @@ func (s LevelDB) load(reader leveldb.Reader, exchange exchanges.Exchange, date t
 		return nil, err
 	}
 
+	// load exchange daily meta
+	meta, err := s.loadExchangeDailyMeta(reader, exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
 	// is trading day
 	if bytes.Equal(isTradingDay, []byte{0}) {
 		return &quotes.ExchangeDailyQuote{
@@ -227,6 +330,7 @@ func (s LevelDB) load(reader leveldb.Reader, exchange exchanges.Exchange, date t
 			Date:      date,
 			Companies: map[string]*quotes.Company{},
 			Quotes:    map[string]*quotes.CompanyDailyQuote{},
+			Meta:      meta,
 		}, nil
 	}
 
@@ -255,11 +359,26 @@ func (s LevelDB) load(reader leveldb.Reader, exchange exchanges.Exchange, date t
 		Date:      date,
 		Companies: companies,
 		Quotes:    cdqs,
+		Meta:      meta,
 	}
 
 	return edq, nil
 }
 
+func (s LevelDB) loadExchangeDailyMeta(reader leveldb.Reader, exchange exchanges.Exchange, date time.Time) (*quotes.Meta, error) {
+	// key: {exchange}:meta:{date} value:{meta json}
+	buffer, err := reader.Get([]byte(fmt.Sprintf("%s:meta:%s", exchange.Code(), date.Format(constants.DatePattern))), levelDBReadOption)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return quotes.UnmarshalMeta(buffer)
+}
+
 func (s LevelDB) loadExchangeDailyCompanies(reader leveldb.Reader, exchange exchanges.Exchange, date time.Time) (map[string]*quotes.Company, error) {
 	// key: {exchange}:{date}:{companyCode} value:{companyName}
 	iter := reader.NewIterator(util.BytesPrefix([]byte(fmt.Sprintf("%s:%s:", exchange.Code(), date.Format(constants.DatePattern)))), levelDBReadOption)
@@ -285,60 +404,113 @@ func (s LevelDB) loadExchangeDailyCompanies(reader leveldb.Reader, exchange exch
 func (s LevelDB) loadCompanyQuotes(reader leveldb.Reader, exchange exchanges.Exchange, date time.Time, companies map[string]*quotes.Company) (map[string]*quotes.CompanyDailyQuote, error) {
 	cdqs := make(map[string]*quotes.CompanyDailyQuote, len(companies))
 	for companyCode, company := range companies {
-		// load company rollup
-		// key: {exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
-		_, err := reader.Get([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), companyCode, date.Format(constants.DatePattern))), levelDBReadOption)
+		cdq, err := s.loadCompanyQuote(reader, exchange, date, company)
 		if err != nil {
-			if err == leveldb.ErrNotFound {
-				continue
-			}
-
-			zap.L().Error("load company rollup failed",
-				zap.Error(err),
-				zap.String("exchange", exchange.Code()),
-				zap.Any("company", company),
-				zap.Time("date", date))
 			return nil, err
 		}
 
-		// load dividend
-		dividend, err := s.loadCompanyDividend(reader, exchange, date, company)
-		if err != nil {
-			return nil, err
+		if cdq == nil {
+			continue
 		}
 
-		// load split
-		split, err := s.loadCompanySplit(reader, exchange, date, company)
-		if err != nil {
-			return nil, err
-		}
+		cdqs[companyCode] = cdq
+	}
 
-		// load pre
-		pre, err := s.loadCompanyQuoteSerial(reader, exchange, date, company, quotes.SerialTypePre)
-		if err != nil {
-			return nil, err
+	return cdqs, nil
+}
+
+// loadCompanyQuote load company daily quote, return nil if company has no quote in the day
+func (s LevelDB) loadCompanyQuote(reader leveldb.Reader, exchange exchanges.Exchange, date time.Time, company *quotes.Company) (*quotes.CompanyDailyQuote, error) {
+	// load company rollup
+	// key: {exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
+	_, err := reader.Get([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern))), levelDBReadOption)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
 		}
 
-		// load regular
-		regular, err := s.loadCompanyQuoteSerial(reader, exchange, date, company, quotes.SerialTypeRegular)
+		zap.L().Error("load company rollup failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Any("company", company),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	// load dividend
+	dividend, err := s.loadCompanyDividend(reader, exchange, date, company)
+	if err != nil {
+		return nil, err
+	}
+
+	// load split
+	split, err := s.loadCompanySplit(reader, exchange, date, company)
+	if err != nil {
+		return nil, err
+	}
+
+	// load actions
+	actions, err := s.loadCompanyActions(reader, exchange, date, company)
+	if err != nil {
+		return nil, err
+	}
+
+	// load pre
+	pre, err := s.loadCompanyQuoteSerial(reader, exchange, date, company, quotes.SerialTypePre)
+	if err != nil {
+		return nil, err
+	}
+
+	// load regular
+	regular, err := s.loadCompanyQuoteSerial(reader, exchange, date, company, quotes.SerialTypeRegular)
+	if err != nil {
+		return nil, err
+	}
+
+	// load post
+	post, err := s.loadCompanyQuoteSerial(reader, exchange, date, company, quotes.SerialTypePost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quotes.CompanyDailyQuote{
+		Company: company,
+		Actions: mergeActions(actions, dividend, split),
+		Pre:     pre,
+		Regular: regular,
+		Post:    post,
+	}, nil
+}
+
+// LoadCompany load company daily quotes between start and end date
+func (s LevelDB) LoadCompany(exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	var cdqs []*quotes.CompanyDailyQuote
+	for _, date := range exchangeDates(exchange, start, end) {
+		// key: {exchange}:{date}:{companyCode} value:{companyName}
+		name, err := s.db.Get([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), date.Format(constants.DatePattern), companyCode)), levelDBReadOption)
 		if err != nil {
+			if err == leveldb.ErrNotFound {
+				continue
+			}
+
+			zap.L().Error("load exchange daily company failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", companyCode),
+				zap.Time("date", date))
 			return nil, err
 		}
 
-		// load post
-		post, err := s.loadCompanyQuoteSerial(reader, exchange, date, company, quotes.SerialTypePost)
+		cdq, err := s.loadCompanyQuote(s.db, exchange, date, &quotes.Company{Code: companyCode, Name: string(name)})
 		if err != nil {
 			return nil, err
 		}
 
-		cdqs[companyCode] = &quotes.CompanyDailyQuote{
-			Company:  company,
-			Dividend: dividend,
-			Split:    split,
-			Pre:      pre,
-			Regular:  regular,
-			Post:     post,
+		if cdq == nil {
+			continue
 		}
+
+		cdqs = append(cdqs, cdq)
 	}
 
 	return cdqs, nil
@@ -408,6 +580,36 @@ func (s LevelDB) loadCompanySplit(reader leveldb.Reader, exchange exchanges.Exch
 	return split, nil
 }
 
+func (s LevelDB) loadCompanyActions(reader leveldb.Reader, exchange exchanges.Exchange, date time.Time, company *quotes.Company) (quotes.Actions, error) {
+	// key: {exchange}:{companyCode}:actions:{date} value:{type},{timestamp},{amount},{numerator},{denominator};...
+	value, err := reader.Get([]byte(fmt.Sprintf("%s:%s:actions:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern))), levelDBReadOption)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+
+		zap.L().Error("load company actions failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Any("company", company),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	actions, err := parseActions(string(value))
+	if err != nil {
+		zap.L().Error("parse company actions failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Any("company", company),
+			zap.Time("date", date),
+			zap.ByteString("value", value))
+		return nil, err
+	}
+
+	return actions, nil
+}
+
 func (s LevelDB) loadCompanyQuoteSerial(reader leveldb.Reader, exchange exchanges.Exchange, date time.Time, company *quotes.Company, serialType quotes.SerialType) (*quotes.Serial, error) {
 	// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
 	prefix := util.BytesPrefix([]byte(fmt.Sprintf("%s:%s:%s:%s:", exchange.Code(), company.Code, date.Format(constants.DatePattern), serialType.String())))
@@ -508,6 +710,7 @@ func (s LevelDB) Delete(exchange exchanges.Exchange, date time.Time) error {
 func (s LevelDB) createDeleteBatch(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) *leveldb.Batch {
 	batch := new(leveldb.Batch)
 	batch.Delete([]byte(fmt.Sprintf("%s:%s", exchange.Code(), date.Format(constants.DatePattern))))
+	batch.Delete([]byte(fmt.Sprintf("%s:meta:%s", exchange.Code(), date.Format(constants.DatePattern))))
 
 	// delete exchange daily
 	// key: {exchange}:{date} value:1 / 0 (is trading day)
@@ -521,38 +724,36 @@ func (s LevelDB) createDeleteBatch(exchange exchanges.Exchange, date time.Time,
 		batch.Delete([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), date.Format(constants.DatePattern), company.Code)))
 	}
 
-	// save exchange daily company quotes
+	// delete exchange daily company quotes
 	for _, cdq := range edq.Quotes {
-		// delete company rollup
-		// key: {exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
-		batch.Delete([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))))
+		s.deleteCompanyQuote(batch, exchange, date, cdq)
+	}
 
-		// delete dividend
-		// key: {exchange}:{companyCode}:dividend:{date} value:{timestamp},{amount}
-		if cdq.Dividend != nil && cdq.Dividend.Enable {
-			batch.Delete([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))))
-		}
+	return batch
+}
 
-		// delete split
-		// key: {exchange}:{companyCode}:split:{date} value:{timestamp},{numerator},{denominator}
-		if cdq.Split != nil && cdq.Split.Enable {
-			batch.Delete([]byte(fmt.Sprintf("%s:%s:split:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))))
-		}
+// deleteCompanyQuote put company daily quote key deletions to batch
+func (s LevelDB) deleteCompanyQuote(batch *leveldb.Batch, exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) {
+	// delete company rollup
+	// key: {exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
+	batch.Delete([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))))
 
-		// delete pre
-		// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
-		s.deleteCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypePre, cdq.Pre)
+	// delete dividend, split and actions, deleting a missing key is not an error
+	for _, name := range []string{"dividend", "split", "actions"} {
+		batch.Delete([]byte(fmt.Sprintf("%s:%s:%s:%s", exchange.Code(), cdq.Company.Code, name, date.Format(constants.DatePattern))))
+	}
 
-		// delete regular
-		// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
-		s.deleteCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypeRegular, cdq.Regular)
+	// delete pre
+	// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
+	s.deleteCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypePre, cdq.Pre)
 
-		// delete post
-		// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
-		s.deleteCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypePost, cdq.Post)
-	}
+	// delete regular
+	// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
+	s.deleteCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypeRegular, cdq.Regular)
 
-	return batch
+	// delete post
+	// key: {exchange}:{companyCode}:{date}:Pre:{timestamp}	value:{open},{close},{high},{low},{volume}
+	s.deleteCompanyDailyQuoteSerial(batch, exchange, cdq.Company, date, quotes.SerialTypePost, cdq.Post)
 }
 
 func (s LevelDB) deleteCompanyDailyQuoteSerial(batch *leveldb.Batch, exchange exchanges.Exchange, company *quotes.Company, date time.Time, serialType quotes.SerialType, serial *quotes.Serial) {
@@ -570,3 +771,214 @@ func (s LevelDB) deleteCompanyDailyQuoteSerial(batch *leveldb.Batch, exchange ex
 			quote.Timestamp)))
 	}
 }
+
+// SaveCompany add or replace company daily quote in one transaction, the exchange day is marked as trading day
+func (s LevelDB) SaveCompany(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	trans, err := s.db.OpenTransaction()
+	if err != nil {
+		zap.L().Error("open db transaction failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+	defer trans.Discard()
+
+	// previous serials may have different timestamps, remove them first
+	previous, err := s.loadCompanyQuote(trans, exchange, date, cdq.Company)
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	if previous != nil {
+		s.deleteCompanyQuote(batch, exchange, date, previous)
+	}
+
+	// key: {exchange}:{date} value:1 / 0 (is trading day)
+	batch.Put([]byte(fmt.Sprintf("%s:%s", exchange.Code(), date.Format(constants.DatePattern))), []byte{1})
+	// key: {exchange}:{date}:{companyCode} value:{companyName}
+	batch.Put([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), date.Format(constants.DatePattern), cdq.Company.Code)), []byte(cdq.Company.Name))
+	s.putCompanyQuote(batch, exchange, date, cdq)
+
+	err = trans.Write(batch, nil)
+	if err != nil {
+		zap.L().Error("batch save company failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", cdq.Company.Code),
+			zap.Time("date", date))
+		return err
+	}
+
+	// validate
+	saved, err := s.loadCompanyQuote(trans, exchange, date, cdq.Company)
+	if err != nil {
+		return err
+	}
+
+	if saved == nil {
+		return fmt.Errorf("saved company daily quote not found: %s", cdq.Company.Code)
+	}
+
+	err = cdq.Equal(*saved)
+	if err != nil {
+		zap.L().Error("validate saved company daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", cdq.Company.Code),
+			zap.Time("date", date))
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		zap.L().Error("commit db transaction failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCompany remove company and its daily quote from exchange day in one transaction
+func (s LevelDB) DeleteCompany(exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	trans, err := s.db.OpenTransaction()
+	if err != nil {
+		zap.L().Error("open db transaction failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+	defer trans.Discard()
+
+	company := &quotes.Company{Code: companyCode}
+	previous, err := s.loadCompanyQuote(trans, exchange, date, company)
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	// key: {exchange}:{date}:{companyCode} value:{companyName}
+	batch.Delete([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), date.Format(constants.DatePattern), companyCode)))
+	if previous != nil {
+		s.deleteCompanyQuote(batch, exchange, date, previous)
+	}
+
+	err = trans.Write(batch, nil)
+	if err != nil {
+		zap.L().Error("batch delete company failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("date", date))
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		zap.L().Error("commit db transaction failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s LevelDB) Exchanges() ([]string, error) {
+	var codes []string
+	for _, exchange := range exchanges.All() {
+		dates, err := s.Dates(exchange, time.Unix(0, 0), time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		if len(dates) > 0 {
+			codes = append(codes, exchange.Code())
+		}
+	}
+
+	return codes, nil
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s LevelDB) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	// key: {exchange}:{date} value:1 / 0 (is trading day)
+	iter := s.db.NewIterator(&util.Range{
+		Start: []byte(fmt.Sprintf("%s:%s", exchange.Code(), start.Format(constants.DatePattern))),
+		Limit: []byte(fmt.Sprintf("%s:%s", exchange.Code(), end.AddDate(0, 0, 1).Format(constants.DatePattern))),
+	}, levelDBReadOption)
+
+	var dates []time.Time
+	for iter.Next() {
+		parts := strings.Split(string(iter.Key()), ":")
+		if len(parts) != 2 {
+			continue
+		}
+
+		date, ok := parseDate(exchange, parts[1], start, end)
+		if !ok {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+	iter.Release()
+
+	err := iter.Error()
+	if err != nil {
+		zap.L().Error("iterate exchange dates failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	return dates, nil
+}
+
+// Stat get stored exchange daily quote stats
+func (s LevelDB) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	companies, err := s.loadExchangeDailyCompanies(s.db, exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily companies failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	stat := &DailyStat{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: len(companies),
+	}
+
+	for companyCode := range companies {
+		// key: {exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
+		exists, err := s.db.Has([]byte(fmt.Sprintf("%s:%s:%s", exchange.Code(), companyCode, date.Format(constants.DatePattern))), levelDBReadOption)
+		if err != nil {
+			zap.L().Error("check company rollup exists failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", companyCode),
+				zap.Time("date", date))
+			return nil, err
+		}
+
+		if exists {
+			stat.Quotes++
+		}
+	}
+
+	return stat, nil
+}
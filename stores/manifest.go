@@ -0,0 +1,390 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"go.uber.org/zap"
+)
+
+// Manifest define stored exchange daily quotes of one exchange in one month
+// object store keeps it at manifests/{exchange}/{200601} and updates it on Save and Delete,
+// so Exists and Dates cost one request per month and ttl instead of one per day
+type Manifest struct {
+	Exchange string           `json:"exchange"`
+	Month    string           `json:"month"`
+	Entries  []*ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry define one stored exchange daily quote
+type ManifestEntry struct {
+	Date      string `json:"date"`
+	Size      int64  `json:"size"`
+	Checksum  string `json:"checksum"`
+	Companies int    `json:"companies"`
+	Quotes    int    `json:"quotes"`
+}
+
+// Find find entry by compact date
+func (m Manifest) Find(date string) (*ManifestEntry, bool) {
+	index := sort.Search(len(m.Entries), func(i int) bool { return m.Entries[i].Date >= date })
+	if index < len(m.Entries) && m.Entries[index].Date == date {
+		return m.Entries[index], true
+	}
+
+	return nil, false
+}
+
+// set add or replace entry, keep entries ordered by date
+func (m *Manifest) set(entry *ManifestEntry) {
+	index := sort.Search(len(m.Entries), func(i int) bool { return m.Entries[i].Date >= entry.Date })
+	if index < len(m.Entries) && m.Entries[index].Date == entry.Date {
+		m.Entries[index] = entry
+		return
+	}
+
+	m.Entries = append(m.Entries, nil)
+	copy(m.Entries[index+1:], m.Entries[index:])
+	m.Entries[index] = entry
+}
+
+// remove remove entry by compact date
+func (m *Manifest) remove(date string) {
+	index := sort.Search(len(m.Entries), func(i int) bool { return m.Entries[i].Date >= date })
+	if index < len(m.Entries) && m.Entries[index].Date == date {
+		m.Entries = append(m.Entries[:index], m.Entries[index+1:]...)
+	}
+}
+
+// manifestKey return manifest object key like manifests/Nasdaq/202303
+func manifestKey(exchange exchanges.Exchange, month time.Time) string {
+	return fmt.Sprintf("manifests/%s/%s", exchange.Code(), month.Format("200601"))
+}
+
+// newManifestEntry create manifest entry from stored object bytes
+func newManifestEntry(date time.Time, data []byte) (*ManifestEntry, error) {
+	stream, err := streamFormat(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return &ManifestEntry{
+		Date:      date.Format(constants.DatePattern),
+		Size:      int64(len(data)),
+		Checksum:  fmt.Sprintf("%08x", crc32.ChecksumIEEE(data)),
+		Companies: len(stream.Companies),
+		Quotes:    stream.Len(),
+	}, nil
+}
+
+// maxManifestUpdates max attempts to update manifest changed concurrently by other writers
+const maxManifestUpdates = 5
+
+// manifestTTL how long a loaded manifest is used without revalidating its etag,
+// writes of other processes are seen after it, writes of this process are seen at once
+const manifestTTL = time.Minute
+
+// manifestCache cache loaded manifests with their etags
+// the mutex only guards the map, loads and updates of one month are serialized by the month's own mutex
+type manifestCache struct {
+	mutex     *sync.Mutex
+	ttl       time.Duration
+	manifests map[string]*cachedManifest
+}
+
+// cachedManifest define cached manifest of one month, manifest is nil if not stored
+type cachedManifest struct {
+	mutex     *sync.Mutex
+	manifest  *Manifest
+	etag      string
+	validated time.Time
+}
+
+func newManifestCache() *manifestCache {
+	return &manifestCache{mutex: new(sync.Mutex), ttl: manifestTTL, manifests: make(map[string]*cachedManifest)}
+}
+
+// get get cached manifest of key, create an unvalidated one if not cached
+func (c *manifestCache) get(key string) *cachedManifest {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, found := c.manifests[key]
+	if !found {
+		cached = &cachedManifest{mutex: new(sync.Mutex)}
+		c.manifests[key] = cached
+	}
+
+	return cached
+}
+
+// fresh check cached manifest is validated within ttl, cached manifest mutex must be held
+func (c *manifestCache) fresh(cached *cachedManifest) bool {
+	return !cached.validated.IsZero() && time.Since(cached.validated) < c.ttl
+}
+
+// invalidate force cached manifest to be loaded again, cached manifest mutex must be held
+func (c *cachedManifest) invalidate() {
+	c.manifest = nil
+	c.etag = ""
+	c.validated = time.Time{}
+}
+
+// loadManifest load month manifest, return nil if manifest not exists
+func (s Object) loadManifest(ctx context.Context, exchange exchanges.Exchange, month time.Time) (*Manifest, error) {
+	key := manifestKey(exchange, month)
+	cached := s.manifests.get(key)
+
+	cached.mutex.Lock()
+	defer cached.mutex.Unlock()
+
+	err := s.loadManifestLocked(ctx, key, cached)
+	return cached.manifest, err
+}
+
+// loadManifestLocked load manifest and its etag by key into cached, cached manifest mutex must be held
+// cached manifest validated within ttl is used without request, otherwise it is reloaded only if its etag changed
+func (s Object) loadManifestLocked(ctx context.Context, key string, cached *cachedManifest) error {
+	if s.manifests.fresh(cached) {
+		return nil
+	}
+
+	validated := time.Now()
+	info, err := s.blob.Head(ctx, key)
+	if err == ErrBlobNotFound {
+		cached.invalidate()
+		cached.validated = validated
+		return nil
+	}
+
+	if err != nil {
+		zap.L().Error("head manifest failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	if cached.manifest != nil && info.ETag != "" && cached.etag == info.ETag {
+		cached.validated = validated
+		return nil
+	}
+
+	body, err := s.blob.Get(ctx, key)
+	if err == ErrBlobNotFound {
+		cached.invalidate()
+		cached.validated = validated
+		return nil
+	}
+
+	if err != nil {
+		zap.L().Error("get manifest failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		zap.L().Error("read manifest failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	manifest := new(Manifest)
+	err = json.Unmarshal(data, manifest)
+	if err != nil {
+		zap.L().Error("unmarshal manifest failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	// the object may be replaced between Head and Get, then the older etag only causes a reload or a conflict later
+	cached.manifest = manifest
+	cached.etag = info.ETag
+	cached.validated = validated
+
+	return nil
+}
+
+// saveManifestLocked put manifest only if stored manifest etag is still the cached one, cached manifest mutex must be held
+// return ErrBlobConflict if manifest was changed by others, the cached manifest is reloaded next time
+func (s Object) saveManifestLocked(ctx context.Context, key string, cached *cachedManifest, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		zap.L().Error("marshal manifest failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	etag, err := PutIf(ctx, s.blob, key, data, cached.etag)
+	if err == ErrBlobConflict {
+		cached.invalidate()
+		return err
+	}
+
+	if err != nil {
+		zap.L().Error("put manifest failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	if etag == "" {
+		// etag of the put manifest is unknown, load it again next time
+		cached.invalidate()
+		return nil
+	}
+
+	cached.manifest = manifest
+	cached.etag = etag
+	cached.validated = time.Now()
+
+	return nil
+}
+
+// updateManifest update month manifest by fn, build manifest from stored objects if not exists
+// the update is retried on fresh manifest if others changed it concurrently
+func (s Object) updateManifest(ctx context.Context, exchange exchanges.Exchange, date time.Time, fn func(*Manifest)) error {
+	key := manifestKey(exchange, date)
+	cached := s.manifests.get(key)
+
+	cached.mutex.Lock()
+	defer cached.mutex.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		err := s.loadManifestLocked(ctx, key, cached)
+		if err != nil {
+			return err
+		}
+
+		manifest := cached.manifest
+		if manifest == nil {
+			// objects saved before manifests were introduced
+			manifest, err = s.buildManifest(ctx, exchange, date)
+			if err != nil {
+				return err
+			}
+		}
+
+		// copy before modify, the cached one may be returned to readers already
+		updated := &Manifest{Exchange: manifest.Exchange, Month: manifest.Month}
+		updated.Entries = append(updated.Entries, manifest.Entries...)
+		fn(updated)
+
+		err = s.saveManifestLocked(ctx, key, cached, updated)
+		if err != ErrBlobConflict || attempt >= maxManifestUpdates {
+			return err
+		}
+
+		zap.L().Warn("manifest changed by others, update again",
+			zap.String("key", key),
+			zap.Int("attempt", attempt))
+	}
+}
+
+// buildManifest build month manifest from stored objects
+func (s Object) buildManifest(ctx context.Context, exchange exchanges.Exchange, month time.Time) (*Manifest, error) {
+	manifest := &Manifest{Exchange: exchange.Code(), Month: month.Format("200601")}
+
+	prefix := month.Format("2006/01/")
+	infos, err := s.blob.List(ctx, prefix)
+	if err != nil {
+		zap.L().Error("list objects failed", zap.Error(err), zap.String("prefix", prefix))
+		return nil, err
+	}
+
+	for _, info := range infos {
+		text, code, ok := parseObjectKey(info.Key)
+		if !ok || code != exchange.Code() {
+			continue
+		}
+
+		date, err := time.ParseInLocation(constants.DatePattern, text, exchange.Location())
+		if err != nil {
+			continue
+		}
+
+		entry, err := s.manifestEntry(ctx, info.Key, date)
+		if err != nil {
+			zap.L().Error("create manifest entry failed", zap.Error(err), zap.String("key", info.Key))
+			return nil, err
+		}
+		manifest.set(entry)
+	}
+
+	return manifest, nil
+}
+
+// RepairManifests rebuild month manifests of exchange between start and end from stored objects
+func (s Object) RepairManifests(exchange exchanges.Exchange, start, end time.Time) error {
+	ctx := s.requestContext()
+	start, end = exchangeDateRange(exchange, start, end)
+
+	for _, prefix := range objectMonthPrefixes(start, end) {
+		month, err := time.ParseInLocation("2006/01/", prefix, exchange.Location())
+		if err != nil {
+			return err
+		}
+
+		manifest, err := s.buildManifest(ctx, exchange, month)
+		if err != nil {
+			return err
+		}
+
+		key := manifestKey(exchange, month)
+		cached := s.manifests.get(key)
+		cached.mutex.Lock()
+		if len(manifest.Entries) == 0 {
+			err = s.blob.Delete(ctx, key)
+		} else {
+			err = s.putManifest(ctx, key, manifest)
+		}
+		cached.invalidate()
+		cached.mutex.Unlock()
+		if err != nil {
+			return err
+		}
+
+		zap.L().Info("repair manifest success",
+			zap.String("exchange", exchange.Code()),
+			zap.String("month", manifest.Month),
+			zap.Int("entries", len(manifest.Entries)))
+	}
+
+	return nil
+}
+
+// putManifest put manifest unconditionally
+func (s Object) putManifest(ctx context.Context, key string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		zap.L().Error("marshal manifest failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	err = s.blob.Put(ctx, key, data)
+	if err != nil {
+		zap.L().Error("put manifest failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	return nil
+}
+
+// manifestEntry create manifest entry from stored object
+func (s Object) manifestEntry(ctx context.Context, key string, date time.Time) (*ManifestEntry, error) {
+	body, err := s.blob.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return newManifestEntry(date, data)
+}
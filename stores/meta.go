@@ -0,0 +1,57 @@
+package stores
+
+import (
+	"encoding/json"
+
+	"github.com/nzai/qr/quotes"
+)
+
+// formatQuality format quality to json text of column stores, return empty text if quality is nil
+func formatQuality(quality *quotes.Quality) string {
+	if quality == nil {
+		return ""
+	}
+
+	buffer, _ := json.Marshal(quality)
+	return string(buffer)
+}
+
+// parseQuality parse quality from json text of column stores, return nil if text is empty
+func parseQuality(text string) (*quotes.Quality, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	quality := new(quotes.Quality)
+	err := json.Unmarshal([]byte(text), quality)
+	if err != nil {
+		return nil, err
+	}
+
+	return quality, nil
+}
+
+// formatIssues format company issues to json text of column stores, return empty text if there is no issue
+func formatIssues(issues quotes.Issues) string {
+	if len(issues) == 0 {
+		return ""
+	}
+
+	buffer, _ := json.Marshal(issues)
+	return string(buffer)
+}
+
+// parseIssues parse company issues from json text of column stores, return nil if text is empty
+func parseIssues(text string) (quotes.Issues, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	var issues quotes.Issues
+	err := json.Unmarshal([]byte(text), &issues)
+	if err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,90 @@
+package stores
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestMeta(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	start := time.Date(2023, 3, 7, 1, 2, 3, 0, time.UTC)
+	meta := quotes.NewMeta("v1.2.3", start, start.Add(time.Minute), 3, 1)
+	meta.Companies["AAPL"] = &quotes.CompanyMeta{Source: quotes.SourceYahoo, Currency: "USD", PreviousClose: 151.03, ExchangeName: "NMS", InstrumentType: "EQUITY", Timezone: "EST"}
+	meta.Companies["MSFT"] = &quotes.CompanyMeta{Source: quotes.SourceYahoo, Currency: "USD", PreviousClose: 255.29, Issues: quotes.Issues{quotes.IssueDuplicate: 2}}
+	meta.Quality = &quotes.Quality{Companies: 2, Bars: 4, Issues: quotes.Issues{quotes.IssueDuplicate: 2}, DroppedBars: 2}
+
+	leveldb := NewLevelDB(t.TempDir())
+	defer leveldb.Close()
+
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sqlite.Close()
+
+	_stores := map[string]Store{
+		"fs":      NewFileSystem(t.TempDir()),
+		"leveldb": leveldb,
+		"sqlite":  sqlite,
+		"memory":  NewObject(NewMemoryBlob(), DefaultFormat),
+	}
+
+	for name, store := range _stores {
+		t.Run(name, func(t *testing.T) {
+			edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+			edq.Meta = meta
+
+			err := store.Save(exchange, date, edq)
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			loaded, err := store.Load(exchange, date)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(loaded.Meta, meta) {
+				t.Errorf("Load() meta = %+v, want %+v", loaded.Meta, meta)
+			}
+
+			// day saved without meta has no meta
+			next := date.AddDate(0, 0, 1)
+			err = store.Save(exchange, next, testExchangeDailyQuote(exchange, next, "AAPL"))
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			loaded, err = store.Load(exchange, next)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if loaded.Meta != nil {
+				t.Errorf("Load() meta = %+v, want nil", loaded.Meta)
+			}
+
+			// day saved again without meta loses the previous meta
+			err = store.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL", "MSFT"))
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			loaded, err = store.Load(exchange, date)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if loaded.Meta != nil {
+				t.Errorf("Load() meta after save without meta = %+v, want nil", loaded.Meta)
+			}
+		})
+	}
+}
@@ -0,0 +1,475 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/mozillazg/go-cos"
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// Object define object storage backed store, exchange daily quote is saved as object 2006/01/02/{exchange}
+// and recorded in month manifest, see Manifest
+type Object struct {
+	blob      Blob
+	format    Format
+	manifests *manifestCache
+	ctx       context.Context
+}
+
+func init() {
+	Register("s3", openS3)
+	Register("cos", openCos)
+	Register("dir", openDir)
+	Register("memory", openMemory)
+}
+
+// objectFormatOptions define format options of object store data source name
+var objectFormatOptions = []string{"compression", "codec"}
+
+// openObject open object store on blob, format from dsn options
+func openObject(dsn *DSN, blob Blob) (Store, error) {
+	format, err := ParseFormat(dsn.Option("compression", ""), dsn.Option("codec", ""))
+	if err != nil {
+		zap.L().Error("parse object format failed", zap.Error(err))
+		return nil, err
+	}
+
+	return NewObject(blob, format), nil
+}
+
+// openS3 open aws s3 store by dsn like s3://bucket?region=us-east-1&endpoint=http://127.0.0.1:9000&path_style=true
+// access_key_id and secret_access_key are secrets, use aws default credential chain if undefined
+func openS3(dsn *DSN) (Store, error) {
+	err := dsn.Validate(append(objectFormatOptions, "region", "endpoint", "path_style", "storage_class", "access_key_id", "secret_access_key")...)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &S3Config{
+		Region:       dsn.Option("region", ""),
+		Bucket:       dsn.Host,
+		Endpoint:     dsn.Option("endpoint", ""),
+		StorageClass: dsn.Option("storage_class", s3.ObjectStorageClassReducedRedundancy),
+	}
+
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket undefined")
+	}
+
+	config.ForcePathStyle, err = dsn.Bool("path_style", false)
+	if err != nil {
+		return nil, err
+	}
+
+	config.AccessKeyID, err = dsn.Secret("access_key_id")
+	if err != nil {
+		return nil, err
+	}
+
+	config.SecretAccessKey, err = dsn.Secret("secret_access_key")
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := NewS3Blob(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return openObject(dsn, blob)
+}
+
+// openCos open tencent cos store by dsn like cos://bucket-appid.cos.ap-shanghai.myqcloud.com
+// endpoint replace the bucket url, like http://127.0.0.1:8080; secret_id and secret_key are secrets
+func openCos(dsn *DSN) (Store, error) {
+	err := dsn.Validate(append(objectFormatOptions, "endpoint", "secret_id", "secret_key")...)
+	if err != nil {
+		return nil, err
+	}
+
+	secretID, err := dsn.Secret("secret_id")
+	if err != nil {
+		return nil, err
+	}
+
+	secretKey, err := dsn.Secret("secret_key")
+	if err != nil {
+		return nil, err
+	}
+
+	bucketURL, err := url.Parse(dsn.Option("endpoint", "https://"+dsn.Host))
+	if err != nil {
+		return nil, fmt.Errorf("cos bucket url invalid: %s", dsn.Host)
+	}
+
+	client := cos.NewClient(&cos.BaseURL{BucketURL: bucketURL}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  secretID,
+			SecretKey: secretKey,
+		},
+	})
+
+	return openObject(dsn, NewCosBlob(client))
+}
+
+// openDir open local directory object store by dsn like dir:///data
+func openDir(dsn *DSN) (Store, error) {
+	err := dsn.Validate(objectFormatOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if dsn.Location() == "" {
+		return nil, fmt.Errorf("dir store root undefined")
+	}
+
+	return openObject(dsn, NewDirBlob(dsn.Location()))
+}
+
+// openMemory open in-memory object store by dsn like memory://
+func openMemory(dsn *DSN) (Store, error) {
+	err := dsn.Validate(objectFormatOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return openObject(dsn, NewMemoryBlob())
+}
+
+// NewObject create object store on blob
+func NewObject(blob Blob, format Format) *Object {
+	return &Object{blob: blob, format: format, manifests: newManifestCache()}
+}
+
+// NewS3 create aws s3 store
+func NewS3(config *S3Config) (*Object, error) {
+	blob, err := NewS3Blob(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewObject(blob, DefaultFormat), nil
+}
+
+// NewCos create tencent cos store
+func NewCos(client *cos.Client) *Object {
+	return NewObject(NewCosBlob(client), DefaultFormat)
+}
+
+// requestContext return context of requests, background if not bound
+func (s Object) requestContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+
+	return s.ctx
+}
+
+// objectKey return object key like 2006/01/02/{exchange}
+func (s Object) objectKey(exchange exchanges.Exchange, date time.Time) string {
+	return fmt.Sprintf("%s/%s", date.Format("2006/01/02"), exchange.Code())
+}
+
+// Exists check quote exists, use month manifest if exists
+func (s Object) Exists(exchange exchanges.Exchange, date time.Time) (bool, error) {
+	manifest, err := s.loadManifest(s.requestContext(), exchange, date)
+	if err != nil {
+		return false, err
+	}
+
+	if manifest != nil {
+		_, found := manifest.Find(date.Format(constants.DatePattern))
+		return found, nil
+	}
+
+	_, err = s.blob.Head(s.requestContext(), s.objectKey(exchange, date))
+	if err == ErrBlobNotFound {
+		return false, nil
+	}
+
+	if err != nil {
+		zap.L().Error("check exchange daily quote exists failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Save save exchange daily quote
+func (s Object) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	buffer := new(bytes.Buffer)
+	err := encodeFormat(buffer, edq, s.format)
+	if err != nil {
+		zap.L().Error("encode exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	err = s.blob.Put(s.requestContext(), s.objectKey(exchange, date), buffer.Bytes())
+	if err != nil {
+		zap.L().Error("put exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	entry, err := newManifestEntry(date, buffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = s.updateManifest(s.requestContext(), exchange, date, func(manifest *Manifest) {
+		manifest.set(entry)
+	})
+	if err != nil {
+		zap.L().Error("update manifest failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+// Load load exchange daily quote
+func (s Object) Load(exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	stream, err := s.Stream(exchange, date)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	edq, err := stream.ReadAll()
+	if err != nil {
+		zap.L().Error("decode exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	return edq, nil
+}
+
+// Stream open exchange daily quote stream, the object is decoded while downloading
+func (s Object) Stream(exchange exchanges.Exchange, date time.Time) (*QuoteStream, error) {
+	body, err := s.blob.Get(s.requestContext(), s.objectKey(exchange, date))
+	if err != nil {
+		zap.L().Error("get exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	stream, err := streamFormat(body)
+	if err != nil {
+		body.Close()
+		zap.L().Error("decode exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+	stream.closer = multiCloser{stream.closer, body}
+
+	return stream, nil
+}
+
+// Delete delete exchange daily quote
+func (s Object) Delete(exchange exchanges.Exchange, date time.Time) error {
+	key := s.objectKey(exchange, date)
+	err := s.blob.Delete(s.requestContext(), key)
+	if err != nil {
+		zap.L().Error("delete exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date),
+			zap.String("key", key))
+		return err
+	}
+
+	err = s.updateManifest(s.requestContext(), exchange, date, func(manifest *Manifest) {
+		manifest.remove(date.Format(constants.DatePattern))
+	})
+	if err != nil {
+		zap.L().Error("update manifest failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+// SaveCompany add or replace company daily quote, the whole object is rewritten
+func (s Object) SaveCompany(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	return saveCompanyByDay(s, exchange, date, cdq)
+}
+
+// DeleteCompany remove company daily quote, the whole object is rewritten
+func (s Object) DeleteCompany(exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	return deleteCompanyByDay(s, exchange, date, companyCode)
+}
+
+// Close close blob if it is closable
+func (s Object) Close() error {
+	closer, ok := s.blob.(io.Closer)
+	if !ok {
+		return nil
+	}
+
+	return closer.Close()
+}
+
+// withContext bind context to requests of reads
+func (s Object) withContext(ctx context.Context) Store {
+	s.ctx = ctx
+	return s
+}
+
+// ExistsContext check quote exists until context done
+func (s Object) ExistsContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (bool, error) {
+	err := ctx.Err()
+	if err != nil {
+		return false, err
+	}
+
+	s.ctx = ctx
+	return s.Exists(exchange, date)
+}
+
+// SaveContext save exchange daily quote unless context done, a started save is not cancelled
+func (s Object) SaveContext(ctx context.Context, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.Save(exchange, date, edq)
+}
+
+// LoadContext load exchange daily quote until context done
+func (s Object) LoadContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx = ctx
+	return s.Load(exchange, date)
+}
+
+// DeleteContext delete exchange daily quote unless context done, a started delete is not cancelled
+func (s Object) DeleteContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.Delete(exchange, date)
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s Object) Exchanges() ([]string, error) {
+	keys, err := s.listKeys("")
+	if err != nil {
+		return nil, err
+	}
+
+	return objectExchanges(keys), nil
+}
+
+// Dates list stored dates of exchange between start and end date
+// read month manifests, list objects of month only if its manifest not exists
+func (s Object) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	var dates []time.Time
+	for _, prefix := range objectMonthPrefixes(start, end) {
+		month, err := time.ParseInLocation("2006/01/", prefix, exchange.Location())
+		if err != nil {
+			return nil, err
+		}
+
+		manifest, err := s.loadManifest(s.requestContext(), exchange, month)
+		if err != nil {
+			return nil, err
+		}
+
+		if manifest != nil {
+			for _, entry := range manifest.Entries {
+				date, ok := parseDate(exchange, entry.Date, start, end)
+				if ok {
+					dates = append(dates, date)
+				}
+			}
+			continue
+		}
+
+		keys, err := s.listKeys(prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		dates = append(dates, objectDates(exchange, keys, start, end)...)
+	}
+
+	return dates, nil
+}
+
+// Stat get stored exchange daily quote stats, use month manifest if exists
+func (s Object) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	manifest, err := s.loadManifest(s.requestContext(), exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest != nil {
+		entry, found := manifest.Find(date.Format(constants.DatePattern))
+		if found {
+			return &DailyStat{
+				Exchange:  exchange.Code(),
+				Date:      date,
+				Companies: entry.Companies,
+				Quotes:    entry.Quotes,
+			}, nil
+		}
+	}
+
+	return statByLoad(s, exchange, date)
+}
+
+// listKeys list all object keys with prefix
+func (s Object) listKeys(prefix string) ([]string, error) {
+	infos, err := s.blob.List(s.requestContext(), prefix)
+	if err != nil {
+		zap.L().Error("list objects failed", zap.Error(err), zap.String("prefix", prefix))
+		return nil, err
+	}
+
+	keys := make([]string, len(infos))
+	for index, info := range infos {
+		keys[index] = info.Key
+	}
+
+	return keys, nil
+}
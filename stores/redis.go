@@ -1,6 +1,7 @@
 package stores
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -18,14 +19,38 @@ import (
 // exchange daily companies		key: ec:{exchange}:{date}:{companyCode}									value:{companyName}
 // company daily rollup quote	key: 1d:{exchange}:{companyCode}:{date} 								value:{open},{close},{high},{low},{volume}
 // company daily quote serial	key: 1m:{exchange}:{companyCode}:{date}:{Pre|Regular|Post}:{timestamp}	value:{open},{close},{high},{low},{volume}
-// company daily dividend		key: dividend:{exchange}:{companyCode}:{date}							value:{timestamp},{amount}
-// company daily split			key: split:{exchange}:{companyCode}:{date}								value:{timestamp},{numerator},{denominator}
+// company daily dividend		key: dividend:{exchange}:{companyCode}:{date}							value:{timestamp},{amount} (written before actions, only read)
+// company daily split			key: split:{exchange}:{companyCode}:{date}								value:{timestamp},{numerator},{denominator} (written before actions, only read)
+// company daily actions		key: actions:{exchange}:{companyCode}:{date}							value:{type},{timestamp},{amount},{numerator},{denominator};...
+// exchange daily meta			key: meta:{exchange}:{date}												value:{meta json}
 
 // Redis define redis store
 type Redis struct {
 	client *redis.Client
 }
 
+func init() {
+	Register("redis", openRedis)
+}
+
+// openRedis open redis store by dsn like redis://127.0.0.1:6379, password in dsn or secret
+func openRedis(dsn *DSN) (Store, error) {
+	err := dsn.Validate("password")
+	if err != nil {
+		return nil, err
+	}
+
+	password, found := dsn.User.Password()
+	if !found {
+		password, err = dsn.Secret("password")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewRedis(dsn.Host, password), nil
+}
+
 // NewRedis create redis store
 func NewRedis(address, password string) *Redis {
 	client := redis.NewClient(&redis.Options{
@@ -48,6 +73,44 @@ func (s Redis) Close() error {
 	return s.client.Close()
 }
 
+// withContext bind context to requests of reads
+func (s Redis) withContext(ctx context.Context) Store {
+	s.client = s.client.WithContext(ctx)
+	return s
+}
+
+// ExistsContext check quote exists until context done
+func (s Redis) ExistsContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (bool, error) {
+	s.client = s.client.WithContext(ctx)
+	return s.Exists(exchange, date)
+}
+
+// SaveContext save exchange daily quote unless context done, a started save is not cancelled
+func (s Redis) SaveContext(ctx context.Context, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.Save(exchange, date, edq)
+}
+
+// LoadContext load exchange daily quote until context done
+func (s Redis) LoadContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	s.client = s.client.WithContext(ctx)
+	return s.Load(exchange, date)
+}
+
+// DeleteContext delete exchange daily quote unless context done, a started delete is not cancelled
+func (s Redis) DeleteContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.Delete(exchange, date)
+}
+
 // Exists check quote exists
 func (s Redis) Exists(exchange exchanges.Exchange, date time.Time) (bool, error) {
 	// key: et:{exchange}:{date} value:1 / 0 (is trading day)
@@ -65,7 +128,12 @@ func (s Redis) Exists(exchange exchanges.Exchange, date time.Time) (bool, error)
 
 // Save save exchange daily quote
 func (s Redis) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	_, err := s.save(exchange, date, edq)
+	return err
+}
 
+// save save exchange daily quote, return key value pairs written
+func (s Redis) save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) ([]string, error) {
 	var pairs []string
 
 	// save exchange daily
@@ -77,45 +145,46 @@ func (s Redis) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.Exc
 	// key: et:{exchange}:{date} value:1 / 0 (is trading day)
 	pairs = append(pairs, fmt.Sprintf("et:%s:%s", exchange.Code(), date.Format(constants.DatePattern)), isTrading)
 
+	// save exchange daily meta
+	// key: meta:{exchange}:{date} value:{meta json}
+	meta, err := quotes.MarshalMeta(edq.Meta)
+	if err != nil {
+		zap.L().Error("encode exchange daily meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	if meta != nil {
+		pairs = append(pairs, fmt.Sprintf("meta:%s:%s", exchange.Code(), date.Format(constants.DatePattern)), string(meta))
+	}
+
 	// save exchange daily companies
 	pairs = append(pairs, s.saveExchangeDailyCompanies(exchange, date, edq.Companies)...)
 
 	// save exchange daily company quotes
+	var legacy []string
 	for _, cdq := range edq.Quotes {
-		// save company rollup
-		// key: 1d:{exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
-		rollup := cdq.Regular.Rollup()
-		key := fmt.Sprintf("1d:%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))
-		pairs = append(pairs, key, s.formatQuote(*rollup))
-
-		// save dividend
-		if cdq.Dividend != nil && cdq.Dividend.Enable {
-			pairs = append(pairs, s.saveCompanyDividend(exchange, cdq.Company, date, cdq.Dividend)...)
-		}
-
-		// save split
-		if cdq.Split != nil && cdq.Split.Enable {
-			pairs = append(pairs, s.saveCompanySplit(exchange, cdq.Company, date, cdq.Split)...)
-		}
-
-		// save pre
-		pairs = append(pairs, s.saveCompanyDailyQuoteSerial(exchange, cdq.Company, date, quotes.SerialTypePre, cdq.Pre)...)
-
-		// save regular
-		pairs = append(pairs, s.saveCompanyDailyQuoteSerial(exchange, cdq.Company, date, quotes.SerialTypeRegular, cdq.Regular)...)
-
-		// save post
-		pairs = append(pairs, s.saveCompanyDailyQuoteSerial(exchange, cdq.Company, date, quotes.SerialTypePost, cdq.Post)...)
+		pairs = append(pairs, s.saveCompanyQuote(exchange, date, cdq)...)
+		legacy = append(legacy, s.legacyActionKeys(exchange, date, cdq.Company)...)
 	}
 
-	err := s.client.MSet(pairs).Err()
+	// dividend and split written before actions are deleted with the save, or they are merged into the saved actions again on load
+	_, err = s.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		if len(legacy) > 0 {
+			pipe.Del(legacy...)
+		}
+		pipe.MSet(pairs)
+		return nil
+	})
 	if err != nil {
 		zap.L().Error("save exchange daily quote failed",
 			zap.Error(err),
 			zap.String("exchange", exchange.Code()),
 			zap.Time("date", date),
 			zap.Int("keys", len(pairs)/2))
-		return err
+		return nil, err
 	}
 
 	zap.L().Debug("save exchange daily quote success",
@@ -124,7 +193,34 @@ func (s Redis) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.Exc
 		zap.Time("date", date),
 		zap.Int("keys", len(pairs)/2))
 
-	return nil
+	return pairs, nil
+}
+
+// saveCompanyQuote create key value pairs of company daily quote
+func (s Redis) saveCompanyQuote(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) []string {
+	var pairs []string
+
+	// save company rollup
+	// key: 1d:{exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
+	rollup := cdq.Regular.Rollup()
+	key := fmt.Sprintf("1d:%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern))
+	pairs = append(pairs, key, s.formatQuote(*rollup))
+
+	// save actions
+	if len(cdq.Actions) > 0 {
+		pairs = append(pairs, s.saveCompanyActions(exchange, cdq.Company, date, cdq.Actions)...)
+	}
+
+	// save pre
+	pairs = append(pairs, s.saveCompanyDailyQuoteSerial(exchange, cdq.Company, date, quotes.SerialTypePre, cdq.Pre)...)
+
+	// save regular
+	pairs = append(pairs, s.saveCompanyDailyQuoteSerial(exchange, cdq.Company, date, quotes.SerialTypeRegular, cdq.Regular)...)
+
+	// save post
+	pairs = append(pairs, s.saveCompanyDailyQuoteSerial(exchange, cdq.Company, date, quotes.SerialTypePost, cdq.Post)...)
+
+	return pairs
 }
 
 func (s Redis) saveExchangeDailyCompanies(exchange exchanges.Exchange, date time.Time, companies map[string]*quotes.Company) []string {
@@ -144,18 +240,18 @@ func (s Redis) saveExchangeDailyCompanies(exchange exchanges.Exchange, date time
 	return pairs
 }
 
-func (s Redis) saveCompanyDividend(exchange exchanges.Exchange, company *quotes.Company, date time.Time, dividend *quotes.Dividend) []string {
-	// key: dividend:{exchange}:{companyCode}:{date} value:{timestamp},{amount}
-	key := fmt.Sprintf("dividend:%s:%s:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern))
-	value := fmt.Sprintf("%d,%f", dividend.Timestamp, dividend.Amount)
-	return []string{key, value}
+func (s Redis) saveCompanyActions(exchange exchanges.Exchange, company *quotes.Company, date time.Time, actions quotes.Actions) []string {
+	// key: actions:{exchange}:{companyCode}:{date} value:{type},{timestamp},{amount},{numerator},{denominator};...
+	key := fmt.Sprintf("actions:%s:%s:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern))
+	return []string{key, formatActions(actions)}
 }
 
-func (s Redis) saveCompanySplit(exchange exchanges.Exchange, company *quotes.Company, date time.Time, split *quotes.Split) []string {
-	// key: split:{exchange}:{companyCode}:{date} value:{timestamp},{numerator},{denominator}
-	key := fmt.Sprintf("split:%s:%s:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern))
-	value := fmt.Sprintf("%d,%f,%f", split.Timestamp, split.Numerator, split.Denominator)
-	return []string{key, value}
+// legacyActionKeys create dividend and split keys of company written before actions
+func (s Redis) legacyActionKeys(exchange exchanges.Exchange, date time.Time, company *quotes.Company) []string {
+	return []string{
+		fmt.Sprintf("dividend:%s:%s:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern)),
+		fmt.Sprintf("split:%s:%s:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern)),
+	}
 }
 
 func (s Redis) saveCompanyDailyQuoteSerial(exchange exchanges.Exchange, company *quotes.Company, date time.Time, serialType quotes.SerialType, serial *quotes.Serial) []string {
@@ -200,6 +296,16 @@ func (s Redis) Load(exchange exchanges.Exchange, date time.Time) (*quotes.Exchan
 		isTradingDay = "0"
 	}
 
+	// load exchange daily meta
+	meta, err := s.loadExchangeDailyMeta(exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
 	// is trading day
 	if isTradingDay != "1" {
 		return &quotes.ExchangeDailyQuote{
@@ -207,6 +313,7 @@ func (s Redis) Load(exchange exchanges.Exchange, date time.Time) (*quotes.Exchan
 			Date:      date,
 			Companies: map[string]*quotes.Company{},
 			Quotes:    map[string]*quotes.CompanyDailyQuote{},
+			Meta:      meta,
 		}, nil
 	}
 
@@ -235,11 +342,26 @@ func (s Redis) Load(exchange exchanges.Exchange, date time.Time) (*quotes.Exchan
 		Date:      date,
 		Companies: companies,
 		Quotes:    cdqs,
+		Meta:      meta,
 	}
 
 	return edq, nil
 }
 
+func (s Redis) loadExchangeDailyMeta(exchange exchanges.Exchange, date time.Time) (*quotes.Meta, error) {
+	// key: meta:{exchange}:{date} value:{meta json}
+	value, err := s.client.Get(fmt.Sprintf("meta:%s:%s", exchange.Code(), date.Format(constants.DatePattern))).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return quotes.UnmarshalMeta([]byte(value))
+}
+
 func (s Redis) loadExchangeDailyCompanies(exchange exchanges.Exchange, date time.Time) (map[string]*quotes.Company, error) {
 	// key: ec:{exchange}:{date}:{companyCode} value:{companyName}
 	prefix := fmt.Sprintf("ec:%s:%s:", exchange.Code(), date.Format(constants.DatePattern))
@@ -265,62 +387,117 @@ func (s Redis) loadExchangeDailyCompanies(exchange exchanges.Exchange, date time
 func (s Redis) loadCompanyQuotes(exchange exchanges.Exchange, date time.Time, companies map[string]*quotes.Company) (map[string]*quotes.CompanyDailyQuote, error) {
 	cdqs := make(map[string]*quotes.CompanyDailyQuote, len(companies))
 	for companyCode, company := range companies {
-		// load company rollup
-		// key: 1d:{exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
-		key := fmt.Sprintf("1d:%s:%s:%s", exchange.Code(), companyCode, date.Format(constants.DatePattern))
-		_, err := s.client.Get(key).Result()
+		cdq, err := s.loadCompanyQuote(exchange, date, company)
 		if err != nil {
-			if err == redis.Nil {
-				continue
-			}
-
-			zap.L().Error("load company rollup failed",
-				zap.Error(err),
-				zap.String("exchange", exchange.Code()),
-				zap.Any("company", company),
-				zap.Time("date", date),
-				zap.String("key", key))
 			return nil, err
 		}
 
-		// load dividend
-		dividend, err := s.loadCompanyDividend(exchange, date, company)
-		if err != nil {
-			return nil, err
+		if cdq == nil {
+			continue
 		}
 
-		// load split
-		split, err := s.loadCompanySplit(exchange, date, company)
-		if err != nil {
-			return nil, err
-		}
+		cdqs[companyCode] = cdq
+	}
 
-		// load pre
-		pre, err := s.loadCompanyQuoteSerial(exchange, date, company, quotes.SerialTypePre)
-		if err != nil {
-			return nil, err
+	return cdqs, nil
+}
+
+// loadCompanyQuote load company daily quote, return nil if company has no quote in the day
+func (s Redis) loadCompanyQuote(exchange exchanges.Exchange, date time.Time, company *quotes.Company) (*quotes.CompanyDailyQuote, error) {
+	// load company rollup
+	// key: 1d:{exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
+	key := fmt.Sprintf("1d:%s:%s:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern))
+	_, err := s.client.Get(key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
 		}
 
-		// load regular
-		regular, err := s.loadCompanyQuoteSerial(exchange, date, company, quotes.SerialTypeRegular)
+		zap.L().Error("load company rollup failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Any("company", company),
+			zap.Time("date", date),
+			zap.String("key", key))
+		return nil, err
+	}
+
+	// load dividend
+	dividend, err := s.loadCompanyDividend(exchange, date, company)
+	if err != nil {
+		return nil, err
+	}
+
+	// load split
+	split, err := s.loadCompanySplit(exchange, date, company)
+	if err != nil {
+		return nil, err
+	}
+
+	// load actions
+	actions, err := s.loadCompanyActions(exchange, date, company)
+	if err != nil {
+		return nil, err
+	}
+
+	// load pre
+	pre, err := s.loadCompanyQuoteSerial(exchange, date, company, quotes.SerialTypePre)
+	if err != nil {
+		return nil, err
+	}
+
+	// load regular
+	regular, err := s.loadCompanyQuoteSerial(exchange, date, company, quotes.SerialTypeRegular)
+	if err != nil {
+		return nil, err
+	}
+
+	// load post
+	post, err := s.loadCompanyQuoteSerial(exchange, date, company, quotes.SerialTypePost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quotes.CompanyDailyQuote{
+		Company: company,
+		Actions: mergeActions(actions, dividend, split),
+		Pre:     pre,
+		Regular: regular,
+		Post:    post,
+	}, nil
+}
+
+// LoadCompany load company daily quotes between start and end date
+func (s Redis) LoadCompany(exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	var cdqs []*quotes.CompanyDailyQuote
+	for _, date := range exchangeDates(exchange, start, end) {
+		// key: ec:{exchange}:{date}:{companyCode} value:{companyName}
+		key := fmt.Sprintf("ec:%s:%s:%s", exchange.Code(), date.Format(constants.DatePattern), companyCode)
+		name, err := s.client.Get(key).Result()
 		if err != nil {
+			if err == redis.Nil {
+				continue
+			}
+
+			zap.L().Error("load exchange daily company failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", companyCode),
+				zap.Time("date", date),
+				zap.String("key", key))
 			return nil, err
 		}
 
-		// load post
-		post, err := s.loadCompanyQuoteSerial(exchange, date, company, quotes.SerialTypePost)
+		cdq, err := s.loadCompanyQuote(exchange, date, &quotes.Company{Code: companyCode, Name: name})
 		if err != nil {
 			return nil, err
 		}
 
-		cdqs[companyCode] = &quotes.CompanyDailyQuote{
-			Company:  company,
-			Dividend: dividend,
-			Split:    split,
-			Pre:      pre,
-			Regular:  regular,
-			Post:     post,
+		if cdq == nil {
+			continue
 		}
+
+		cdqs = append(cdqs, cdq)
 	}
 
 	return cdqs, nil
@@ -396,6 +573,39 @@ func (s Redis) loadCompanySplit(exchange exchanges.Exchange, date time.Time, com
 	return split, nil
 }
 
+func (s Redis) loadCompanyActions(exchange exchanges.Exchange, date time.Time, company *quotes.Company) (quotes.Actions, error) {
+	// key: actions:{exchange}:{companyCode}:{date} value:{type},{timestamp},{amount},{numerator},{denominator};...
+	key := fmt.Sprintf("actions:%s:%s:%s", exchange.Code(), company.Code, date.Format(constants.DatePattern))
+	value, err := s.client.Get(key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+
+		zap.L().Error("load company actions failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Any("company", company),
+			zap.Time("date", date),
+			zap.String("key", key))
+		return nil, err
+	}
+
+	actions, err := parseActions(value)
+	if err != nil {
+		zap.L().Error("parse company actions failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Any("company", company),
+			zap.Time("date", date),
+			zap.String("key", key),
+			zap.String("value", value))
+		return nil, err
+	}
+
+	return actions, nil
+}
+
 func (s Redis) loadCompanyQuoteSerial(exchange exchanges.Exchange, date time.Time, company *quotes.Company, serialType quotes.SerialType) (*quotes.Serial, error) {
 	// key: 1m:{exchange}:{companyCode}:{date}:{Pre|Regular|Post}:{timestamp} value:{open},{close},{high},{low},{volume}
 	prefix := fmt.Sprintf("1m:%s:%s:%s:%s:", exchange.Code(), company.Code, date.Format(constants.DatePattern), serialType.String())
@@ -456,12 +666,18 @@ func (s Redis) scanQuote(key, value string) (*quotes.Quote, error) {
 // prefixScan perform redis scan with prefix
 func (s Redis) prefixScan(prefix string) ([]KV, error) {
 	start, end := s.getScanRange(prefix)
+	return s.rangeScan(start, end)
+}
+
+// rangeScan perform redis scan in key range
+func (s Redis) rangeScan(start, end string) ([]KV, error) {
 	// scan {start} {end} {limit}
 	result, err := s.client.Do("scan", start, end, -1).Result()
 	if err != nil {
 		zap.L().Error("redis scan failed",
 			zap.Error(err),
-			zap.String("prefix", prefix))
+			zap.String("start", start),
+			zap.String("end", end))
 		return nil, err
 	}
 
@@ -504,11 +720,90 @@ func (s Redis) Delete(exchange exchanges.Exchange, date time.Time) error {
 		return err
 	}
 
+	keys := s.deleteKeys(exchange, date, edq)
+	err = s.client.Del(keys...).Err()
+	if err != nil {
+		zap.L().Error("delete exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date),
+			zap.Int("keys", len(keys)))
+		return err
+	}
+
+	zap.L().Debug("delete exchange daily quote success",
+		zap.Error(err),
+		zap.String("exchange", exchange.Code()),
+		zap.Time("date", date),
+		zap.Int("keys", len(keys)))
+
+	return nil
+}
+
+// Replace replace exchange daily quote, the new day is written before keys of the previous day not in it are deleted
+func (s Redis) Replace(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	exists, err := s.Exists(exchange, date)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return s.Save(exchange, date, edq)
+	}
+
+	previous, err := s.Load(exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily quotes failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	pairs, err := s.save(exchange, date, edq)
+	if err != nil {
+		return err
+	}
+
+	written := make(map[string]bool, len(pairs)/2)
+	for index := 0; index < len(pairs); index += 2 {
+		written[pairs[index]] = true
+	}
+
+	var stale []string
+	for _, key := range s.deleteKeys(exchange, date, previous) {
+		if !written[key] {
+			stale = append(stale, key)
+		}
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	err = s.client.Del(stale...).Err()
+	if err != nil {
+		zap.L().Error("delete stale exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date),
+			zap.Int("keys", len(stale)))
+		return err
+	}
+
+	return nil
+}
+
+// deleteKeys create keys of exchange daily quote
+func (s Redis) deleteKeys(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) []string {
 	var keys []string
 
 	// key: et:{exchange}:{date} value:1 / 0 (is trading day)
 	keys = append(keys, fmt.Sprintf("et:%s:%s", exchange.Code(), date.Format(constants.DatePattern)))
 
+	// key: meta:{exchange}:{date} value:{meta json}
+	keys = append(keys, fmt.Sprintf("meta:%s:%s", exchange.Code(), date.Format(constants.DatePattern)))
+
 	// delete exchange daily companies
 	// key: ec:{exchange}:{date}:{companyCode} value:{companyName}
 	for _, company := range edq.Companies {
@@ -517,49 +812,35 @@ func (s Redis) Delete(exchange exchanges.Exchange, date time.Time) error {
 
 	// delete exchange daily company quotes
 	for _, cdq := range edq.Quotes {
-		// delete company rollup
-		// key: 1d:{exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
-		keys = append(keys, fmt.Sprintf("1d:%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern)))
-
-		// delete dividend
-		if cdq.Dividend != nil && cdq.Dividend.Enable {
-			// key: dividend:{exchange}:{companyCode}:{date} value:{timestamp},{amount}
-			keys = append(keys, fmt.Sprintf("dividend:%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern)))
-		}
+		keys = append(keys, s.deleteCompanyQuoteKeys(exchange, date, cdq)...)
+	}
 
-		// delete split
-		if cdq.Split != nil && cdq.Split.Enable {
-			// key: split:{exchange}:{companyCode}:{date} value:{timestamp},{numerator},{denominator}
-			keys = append(keys, fmt.Sprintf("split:%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern)))
-		}
+	return keys
+}
 
-		// delete pre
-		keys = append(keys, s.createDeleteCompanyDailyQuoteSerialKeys(exchange, cdq.Company, date, quotes.SerialTypePre, cdq.Pre)...)
+// deleteCompanyQuoteKeys create keys of company daily quote
+func (s Redis) deleteCompanyQuoteKeys(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) []string {
+	var keys []string
 
-		// delete regular
-		keys = append(keys, s.createDeleteCompanyDailyQuoteSerialKeys(exchange, cdq.Company, date, quotes.SerialTypeRegular, cdq.Regular)...)
+	// delete company rollup
+	// key: 1d:{exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
+	keys = append(keys, fmt.Sprintf("1d:%s:%s:%s", exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern)))
 
-		// delete post
-		keys = append(keys, s.createDeleteCompanyDailyQuoteSerialKeys(exchange, cdq.Company, date, quotes.SerialTypePost, cdq.Post)...)
+	// delete dividend, split and actions, deleting a missing key is not an error
+	for _, name := range []string{"dividend", "split", "actions"} {
+		keys = append(keys, fmt.Sprintf("%s:%s:%s:%s", name, exchange.Code(), cdq.Company.Code, date.Format(constants.DatePattern)))
 	}
 
-	err = s.client.Del(keys...).Err()
-	if err != nil {
-		zap.L().Error("delete exchange daily quote failed",
-			zap.Error(err),
-			zap.String("exchange", exchange.Code()),
-			zap.Time("date", date),
-			zap.Int("keys", len(keys)))
-		return err
-	}
+	// delete pre
+	keys = append(keys, s.createDeleteCompanyDailyQuoteSerialKeys(exchange, cdq.Company, date, quotes.SerialTypePre, cdq.Pre)...)
 
-	zap.L().Debug("delete exchange daily quote success",
-		zap.Error(err),
-		zap.String("exchange", exchange.Code()),
-		zap.Time("date", date),
-		zap.Int("keys", len(keys)))
+	// delete regular
+	keys = append(keys, s.createDeleteCompanyDailyQuoteSerialKeys(exchange, cdq.Company, date, quotes.SerialTypeRegular, cdq.Regular)...)
 
-	return nil
+	// delete post
+	keys = append(keys, s.createDeleteCompanyDailyQuoteSerialKeys(exchange, cdq.Company, date, quotes.SerialTypePost, cdq.Post)...)
+
+	return keys
 }
 
 func (s Redis) createDeleteCompanyDailyQuoteSerialKeys(exchange exchanges.Exchange, company *quotes.Company, date time.Time, serialType quotes.SerialType, serial *quotes.Serial) []string {
@@ -582,6 +863,187 @@ func (s Redis) createDeleteCompanyDailyQuoteSerialKeys(exchange exchanges.Exchan
 	return keys
 }
 
+// SaveCompany add or replace company daily quote, the exchange day is marked as trading day
+// new keys are written before stale keys of previous quote are removed, readers never see the company missing
+func (s Redis) SaveCompany(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	previous, err := s.loadCompanyQuote(exchange, date, cdq.Company)
+	if err != nil {
+		return err
+	}
+
+	pairs := []string{
+		// key: et:{exchange}:{date} value:1 / 0 (is trading day)
+		fmt.Sprintf("et:%s:%s", exchange.Code(), date.Format(constants.DatePattern)), "1",
+		// key: ec:{exchange}:{date}:{companyCode} value:{companyName}
+		fmt.Sprintf("ec:%s:%s:%s", exchange.Code(), date.Format(constants.DatePattern), cdq.Company.Code), cdq.Company.Name,
+	}
+	pairs = append(pairs, s.saveCompanyQuote(exchange, date, cdq)...)
+
+	err = s.client.MSet(pairs).Err()
+	if err != nil {
+		zap.L().Error("save company daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", cdq.Company.Code),
+			zap.Time("date", date),
+			zap.Int("keys", len(pairs)/2))
+		return err
+	}
+
+	if previous == nil {
+		return nil
+	}
+
+	written := make(map[string]bool, len(pairs)/2)
+	for index := 0; index < len(pairs); index += 2 {
+		written[pairs[index]] = true
+	}
+
+	var stale []string
+	for _, key := range s.deleteCompanyQuoteKeys(exchange, date, previous) {
+		if !written[key] {
+			stale = append(stale, key)
+		}
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	err = s.client.Del(stale...).Err()
+	if err != nil {
+		zap.L().Error("delete stale company daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", cdq.Company.Code),
+			zap.Time("date", date),
+			zap.Int("keys", len(stale)))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCompany remove company and its daily quote from exchange day
+func (s Redis) DeleteCompany(exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	previous, err := s.loadCompanyQuote(exchange, date, &quotes.Company{Code: companyCode})
+	if err != nil {
+		return err
+	}
+
+	// key: ec:{exchange}:{date}:{companyCode} value:{companyName}
+	keys := []string{fmt.Sprintf("ec:%s:%s:%s", exchange.Code(), date.Format(constants.DatePattern), companyCode)}
+	if previous != nil {
+		keys = append(keys, s.deleteCompanyQuoteKeys(exchange, date, previous)...)
+	}
+
+	err = s.client.Del(keys...).Err()
+	if err != nil {
+		zap.L().Error("delete company daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("date", date),
+			zap.Int("keys", len(keys)))
+		return err
+	}
+
+	return nil
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s Redis) Exchanges() ([]string, error) {
+	var codes []string
+	for _, exchange := range exchanges.All() {
+		// key: et:{exchange}:{date} value:1 / 0 (is trading day)
+		kvs, err := s.prefixScan(fmt.Sprintf("et:%s:", exchange.Code()))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(kvs) > 0 {
+			codes = append(codes, exchange.Code())
+		}
+	}
+
+	return codes, nil
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s Redis) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	// key: et:{exchange}:{date} value:1 / 0 (is trading day)
+	kvs, err := s.rangeScan(
+		fmt.Sprintf("et:%s:%s", exchange.Code(), start.Format(constants.DatePattern)),
+		fmt.Sprintf("et:%s:%s", exchange.Code(), end.AddDate(0, 0, 1).Format(constants.DatePattern)))
+	if err != nil {
+		zap.L().Error("scan exchange dates failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	dates := make([]time.Time, 0, len(kvs))
+	for _, kv := range kvs {
+		parts := strings.Split(kv.Key, ":")
+		if len(parts) != 3 {
+			continue
+		}
+
+		date, ok := parseDate(exchange, parts[2], start, end)
+		if !ok {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+
+	return dates, nil
+}
+
+// Stat get stored exchange daily quote stats
+func (s Redis) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	companies, err := s.loadExchangeDailyCompanies(exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily companies failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	stat := &DailyStat{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: len(companies),
+	}
+
+	if len(companies) == 0 {
+		return stat, nil
+	}
+
+	// key: 1d:{exchange}:{companyCode}:{date} value:{open},{close},{high},{low},{volume}
+	keys := make([]string, 0, len(companies))
+	for companyCode := range companies {
+		keys = append(keys, fmt.Sprintf("1d:%s:%s:%s", exchange.Code(), companyCode, date.Format(constants.DatePattern)))
+	}
+
+	count, err := s.client.Exists(keys...).Result()
+	if err != nil {
+		zap.L().Error("count company rollups failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+	stat.Quotes = int(count)
+
+	return stat, nil
+}
+
 // KV key value pair
 type KV struct {
 	Key   string
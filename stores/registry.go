@@ -0,0 +1,278 @@
+package stores
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Factory create store from data source name
+type Factory func(*DSN) (Store, error)
+
+var _factories = map[string]Factory{}
+
+// Register register store factory by driver name, the driver name is the scheme of data source name
+// it panics if factory is nil or the driver name is registered twice
+func Register(name string, factory Factory) {
+	if factory == nil {
+		panic("stores: Register factory is nil")
+	}
+
+	if _, found := _factories[name]; found {
+		panic("stores: Register called twice for driver " + name)
+	}
+
+	_factories[name] = factory
+}
+
+// Drivers get registered driver names
+func Drivers() []string {
+	names := make([]string, 0, len(_factories))
+	for name := range _factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Open open store by data source name like s3://bucket?region=us-east-1
+func Open(dsn *DSN) (Store, error) {
+	factory, found := _factories[dsn.Scheme]
+	if !found {
+		zap.L().Error("store driver not registered", zap.String("driver", dsn.Scheme))
+		return nil, fmt.Errorf("store driver not registered: %s", dsn.Scheme)
+	}
+
+	return factory(dsn)
+}
+
+// DSN define store data source name
+//
+//	fs:///data?compression=zstd&codec=columnar
+//	s3://bucket?region=us-east-1&endpoint=http://127.0.0.1:9000&path_style=true
+//	redis://127.0.0.1:6379
+//
+// secrets are pulled from, in order
+//
+//	{name}_file=/path/to/file	content of file
+//	{name}_env=NAME				environment variable NAME
+//	{name}=value				inline value, not recommended
+//	QR_{DRIVER}_{NAME}			environment variable, like QR_S3_SECRET_ACCESS_KEY
+type DSN struct {
+	*url.URL
+}
+
+// ParseDSN parse data source name
+func ParseDSN(text string) (*DSN, error) {
+	u, err := url.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("store driver undefined: %s", text)
+	}
+
+	return &DSN{URL: u}, nil
+}
+
+// Location get host and path, like /data of fs:///data, bucket of s3://bucket
+func (d DSN) Location() string {
+	return d.Host + d.Path
+}
+
+// Option get query option or default value
+func (d DSN) Option(name, defaultValue string) string {
+	values := d.Query()
+	if !values.Has(name) {
+		return defaultValue
+	}
+
+	return values.Get(name)
+}
+
+// Bool get bool query option or default value
+func (d DSN) Bool(name string, defaultValue bool) (bool, error) {
+	value := d.Option(name, "")
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("store option %s invalid: %s", name, value)
+	}
+
+	return result, nil
+}
+
+// Int get int query option or default value
+func (d DSN) Int(name string, defaultValue int) (int, error) {
+	value := d.Option(name, "")
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("store option %s invalid: %s", name, value)
+	}
+
+	return result, nil
+}
+
+// Secret get secret from file, environment variable or inline option, return empty if not found
+func (d DSN) Secret(name string) (string, error) {
+	values := d.Query()
+
+	path := values.Get(name + "_file")
+	if path != "" {
+		buffer, err := os.ReadFile(path)
+		if err != nil {
+			zap.L().Error("read secret file failed", zap.Error(err), zap.String("name", name), zap.String("path", path))
+			return "", err
+		}
+
+		return strings.TrimSpace(string(buffer)), nil
+	}
+
+	env := values.Get(name + "_env")
+	if env != "" {
+		value, found := os.LookupEnv(env)
+		if !found {
+			return "", fmt.Errorf("store secret %s environment variable %s undefined", name, env)
+		}
+
+		return value, nil
+	}
+
+	if values.Has(name) {
+		return values.Get(name), nil
+	}
+
+	return os.Getenv(strings.ToUpper(fmt.Sprintf("QR_%s_%s", d.Scheme, name))), nil
+}
+
+// Validate check every query option is known, secrets can also be {name}_file and {name}_env
+func (d DSN) Validate(options ...string) error {
+	known := make(map[string]bool, len(options)*3)
+	for _, option := range options {
+		known[option] = true
+		known[option+"_file"] = true
+		known[option+"_env"] = true
+	}
+
+	for name := range d.Query() {
+		if !known[name] {
+			zap.L().Error("store option unknown", zap.String("driver", d.Scheme), zap.String("option", name))
+			return fmt.Errorf("store option unknown: %s", name)
+		}
+	}
+
+	return nil
+}
+
+// parseLegacyDSN convert legacy pipe-split argument like s3|id|secret|region|bucket to data source name
+func parseLegacyDSN(arg string) (*DSN, error) {
+	parts := strings.Split(arg, "|")
+	if len(parts) < 2 {
+		zap.L().Error("store arg invalid", zap.String("arg", arg))
+		return nil, fmt.Errorf("store arg invalid: %s", arg)
+	}
+
+	zap.L().Warn("pipe-split store argument is deprecated, use data source name instead", zap.String("driver", parts[0]))
+
+	u := &url.URL{Scheme: parts[0]}
+	values := url.Values{}
+	switch parts[0] {
+	case "fs":
+		u.Path = parts[1]
+		if len(parts) > 2 && parts[2] != "" {
+			values.Set("compression", parts[2])
+		}
+		if len(parts) > 3 && parts[3] != "" {
+			values.Set("codec", parts[3])
+		}
+	case "leveldb", "sqlite":
+		u.Path = parts[1]
+	case "redis":
+		if len(parts) < 3 {
+			zap.L().Error("store arg invalid", zap.String("arg", arg))
+			return nil, fmt.Errorf("store arg invalid: %s", arg)
+		}
+
+		u.Host = parts[1]
+		values.Set("password", parts[2])
+	case "cos":
+		if len(parts) < 4 {
+			zap.L().Error("store arg invalid", zap.String("arg", arg))
+			return nil, fmt.Errorf("store arg invalid: %s", arg)
+		}
+
+		bucketURL, err := url.Parse(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("bucket url arg invalid: %s", arg)
+		}
+
+		// keep the bucket url as endpoint, its scheme may be http
+		u.Host = bucketURL.Host
+		values.Set("endpoint", parts[1])
+		values.Set("secret_id", parts[2])
+		values.Set("secret_key", parts[3])
+	case "influxdb":
+		if len(parts) < 3 {
+			zap.L().Error("store arg invalid", zap.String("arg", arg))
+			return nil, fmt.Errorf("store arg invalid: %s", arg)
+		}
+
+		address, err := url.Parse(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("influxdb address arg invalid: %s", arg)
+		}
+
+		u.Host = address.Host
+		u.Path = "/" + parts[2]
+		if address.Scheme == "https" {
+			values.Set("tls", "true")
+		}
+	case "s3":
+		if len(parts) < 5 {
+			zap.L().Error("store arg invalid", zap.String("arg", arg))
+			return nil, fmt.Errorf("store arg invalid: %s", arg)
+		}
+
+		u.Host = parts[4]
+		values.Set("access_key_id", parts[1])
+		values.Set("secret_access_key", parts[2])
+		values.Set("region", parts[3])
+	case "tdengine":
+		// user:password@protocol(address)/db
+		at := strings.LastIndex(parts[1], "@")
+		left, right := strings.Index(parts[1], "("), strings.LastIndex(parts[1], ")")
+		if at < 0 || left < at || right < left {
+			zap.L().Error("store arg invalid", zap.String("arg", arg))
+			return nil, fmt.Errorf("store arg invalid: %s", arg)
+		}
+
+		user, password, _ := strings.Cut(parts[1][:at], ":")
+		u.User = url.UserPassword(user, password)
+		protocol := strings.TrimPrefix(parts[1][at+1:left], "/")
+		if protocol != "" {
+			values.Set("protocol", protocol)
+		}
+		u.Host = parts[1][left+1 : right]
+		u.Path = parts[1][right+1:]
+	default:
+		zap.L().Error("store type invalid", zap.String("type", parts[0]))
+		return nil, fmt.Errorf("store type invalid: %s", parts[0])
+	}
+	u.RawQuery = values.Encode()
+
+	return &DSN{URL: u}, nil
+}
@@ -0,0 +1,167 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// Replacer define store whose Save keeps keys of the previous exchange daily quote which are not in the new one,
+// Replace write the new exchange daily quote before removing those stale keys, so the day is never missing
+type Replacer interface {
+	Replace(exchanges.Exchange, time.Time, *quotes.ExchangeDailyQuote) error
+}
+
+// Replace replace stored exchange daily quote
+// use store native implement if it is a Replacer, otherwise Save, which overwrites the whole day
+func Replace(store Store, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	replacer, ok := store.(Replacer)
+	if ok {
+		return replacer.Replace(exchange, date, edq)
+	}
+
+	return store.Save(exchange, date, edq)
+}
+
+// ReplaceContext replace stored exchange daily quote unless context done, a started replace is not cancelled
+func ReplaceContext(ctx context.Context, store Store, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return Replace(store, exchange, date, edq)
+}
+
+// staleRange define inclusive timestamp range of stale bars, next is index of the first kept timestamp after it
+type staleRange struct {
+	Start uint64
+	End   uint64
+	next  int
+}
+
+// staleRanges return ranges of previous bar timestamps not in current bars,
+// bars of all serials are merged and ranges never contain a timestamp of current bars
+func staleRanges(previous, current *quotes.CompanyDailyQuote) []staleRange {
+	kept := barTimestamps(current)
+
+	var ranges []staleRange
+	for _, timestamp := range barTimestamps(previous) {
+		index := sort.Search(len(kept), func(i int) bool { return kept[i] >= timestamp })
+		if index < len(kept) && kept[index] == timestamp {
+			continue
+		}
+
+		if last := len(ranges) - 1; last >= 0 && ranges[last].next == index {
+			ranges[last].End = timestamp
+			continue
+		}
+
+		ranges = append(ranges, staleRange{Start: timestamp, End: timestamp, next: index})
+	}
+
+	return ranges
+}
+
+// barTimestamps return sorted timestamps of bars in all serials
+func barTimestamps(cdq *quotes.CompanyDailyQuote) []uint64 {
+	var timestamps []uint64
+	for _, serial := range []*quotes.Serial{cdq.Pre, cdq.Regular, cdq.Post} {
+		if serial == nil {
+			continue
+		}
+
+		for _, quote := range *serial {
+			timestamps = append(timestamps, quote.Timestamp)
+		}
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	return timestamps
+}
+
+// Downsample rewrite stored exchange day from minute bars to interval bars
+// pre, regular and post serials are resampled separately, dividends and splits are kept
+// the day resolution is recorded in meta, return false if the day is already at interval or coarser
+func Downsample(store Store, exchange exchanges.Exchange, date time.Time, interval time.Duration) (bool, error) {
+	bar, ok := quotes.IntervalOf(interval)
+	if !ok {
+		return false, fmt.Errorf("downsample interval invalid: %s", interval)
+	}
+
+	edq, err := store.Load(exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return false, err
+	}
+
+	resolution := time.Minute
+	if edq.Meta != nil && edq.Meta.Resolution > 0 {
+		resolution = edq.Meta.Resolution
+	}
+
+	if interval <= resolution {
+		return false, nil
+	}
+
+	if interval%resolution != 0 {
+		return false, fmt.Errorf("downsample interval %s is not a multiple of resolution %s", interval, resolution)
+	}
+
+	downsampled := &quotes.ExchangeDailyQuote{
+		Exchange:  edq.Exchange,
+		Date:      edq.Date,
+		Companies: edq.Companies,
+		Quotes:    make(map[string]*quotes.CompanyDailyQuote, len(edq.Quotes)),
+		Meta:      &quotes.Meta{Version: quotes.MetaVersion},
+	}
+
+	if edq.Meta != nil {
+		meta := *edq.Meta
+		meta.Version = quotes.MetaVersion
+		downsampled.Meta = &meta
+	}
+	downsampled.Meta.Resolution = interval
+
+	for code, cdq := range edq.Quotes {
+		downsampled.Quotes[code] = &quotes.CompanyDailyQuote{
+			Company: cdq.Company,
+			Actions: cdq.Actions,
+			Pre:     resampleSerial(cdq.Pre, bar, exchange.Location()),
+			Regular: resampleSerial(cdq.Regular, bar, exchange.Location()),
+			Post:    resampleSerial(cdq.Post, bar, exchange.Location()),
+		}
+	}
+
+	// the day is written in place, it is never missing if downsample fails half way
+	err = Replace(store, exchange, date, downsampled)
+	if err != nil {
+		zap.L().Error("replace downsampled exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date),
+			zap.Duration("interval", interval))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// resampleSerial resample serial into interval bars, nil serial stays nil
+func resampleSerial(serial *quotes.Serial, interval quotes.Interval, location *time.Location) *quotes.Serial {
+	if serial == nil {
+		return nil
+	}
+
+	resampled := serial.Resample(interval, location)
+	return &resampled
+}
@@ -0,0 +1,109 @@
+package stores
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestDownsample(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	leveldb := NewLevelDB(t.TempDir())
+	defer leveldb.Close()
+
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sqlite.Close()
+
+	_stores := map[string]Store{
+		"fs":      NewFileSystem(t.TempDir()),
+		"leveldb": leveldb,
+		"sqlite":  sqlite,
+		"memory":  NewObject(NewMemoryBlob(), DefaultFormat),
+	}
+
+	for name, store := range _stores {
+		t.Run(name, func(t *testing.T) {
+			edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+			edq.Quotes["AAPL"].Actions = quotes.Actions{{Type: quotes.ActionCashDividend, Timestamp: (*edq.Quotes["AAPL"].Regular)[0].Timestamp, Amount: 0.23}}
+
+			err := store.Save(exchange, date, edq)
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			ok, err := Downsample(store, exchange, date, time.Minute*5)
+			if err != nil || !ok {
+				t.Fatalf("Downsample() = %v, %v, want true", ok, err)
+			}
+
+			saved, err := store.Load(exchange, date)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if saved.Meta == nil || saved.Meta.Resolution != time.Minute*5 {
+				t.Errorf("Load() meta = %+v, want resolution 5m", saved.Meta)
+			}
+
+			for code, cdq := range edq.Quotes {
+				downsampled := saved.Quotes[code]
+				if len(*downsampled.Regular) != 1 {
+					t.Errorf("%s regular bars = %d, want 1", code, len(*downsampled.Regular))
+				}
+
+				err = cdq.Regular.Rollup().Equal(*downsampled.Regular.Rollup())
+				if err != nil {
+					t.Errorf("%s rollup changed: %v", code, err)
+				}
+
+				err = cdq.Actions.Equal(downsampled.Actions)
+				if err != nil {
+					t.Errorf("%s actions changed: %v", code, err)
+				}
+			}
+
+			// already downsampled
+			ok, err = Downsample(store, exchange, date, time.Minute*5)
+			if err != nil || ok {
+				t.Errorf("Downsample() again = %v, %v, want false", ok, err)
+			}
+		})
+	}
+}
+
+func TestStaleRanges(t *testing.T) {
+	serial := func(timestamps ...uint64) *quotes.Serial {
+		s := make(quotes.Serial, len(timestamps))
+		for index, timestamp := range timestamps {
+			s[index] = quotes.Quote{Timestamp: timestamp}
+		}
+		return &s
+	}
+
+	previous := &quotes.CompanyDailyQuote{Pre: serial(0, 60), Regular: serial(120, 180, 240, 300, 360), Post: serial(420)}
+	current := &quotes.CompanyDailyQuote{Regular: serial(120, 300)}
+
+	ranges := staleRanges(previous, current)
+	want := [][2]uint64{{0, 60}, {180, 240}, {360, 420}}
+	if len(ranges) != len(want) {
+		t.Fatalf("staleRanges() got %d ranges, want %d", len(ranges), len(want))
+	}
+
+	for index, r := range ranges {
+		if r.Start != want[index][0] || r.End != want[index][1] {
+			t.Errorf("staleRanges()[%d] = %d-%d, want %d-%d", index, r.Start, r.End, want[index][0], want[index][1])
+		}
+	}
+
+	if ranges := staleRanges(current, current); len(ranges) != 0 {
+		t.Errorf("staleRanges() same quote got %d ranges, want 0", len(ranges))
+	}
+}
@@ -0,0 +1,448 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// ErrRevisionNotFound revision not found
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision define one saved revision of exchange daily quote
+// crawled is zero if the revision was stored before revisions were kept
+type Revision struct {
+	Number    int             `json:"number"`
+	Crawled   time.Time       `json:"crawled"`
+	Source    string          `json:"source,omitempty"`
+	Checksum  string          `json:"checksum"`
+	Companies int             `json:"companies"`
+	Quotes    int             `json:"quotes"`
+	Change    *RevisionChange `json:"change,omitempty"`
+}
+
+// RevisionChange summarize what changed from previous revision
+type RevisionChange struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+	Bars    int      `json:"bars"`
+}
+
+// newRevisionChange summarize changes between previous and current exchange daily quote
+func newRevisionChange(previous, current *quotes.ExchangeDailyQuote) *RevisionChange {
+	diff := quotes.Diff(*previous, *current)
+
+	change := &RevisionChange{Added: diff.RightOnly, Removed: diff.LeftOnly}
+	for _, cd := range diff.Companies {
+		change.Changed = append(change.Changed, cd.Company)
+		change.Bars += len(cd.Bars)
+	}
+
+	return change
+}
+
+// IsEmpty check nothing changed
+func (c RevisionChange) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// RevisionStore define store keeps prior revisions of exchange daily quote
+type RevisionStore interface {
+	// SaveRevision save exchange daily quote crawled at time from source until context done, return the latest revision
+	SaveRevision(context.Context, exchanges.Exchange, time.Time, *quotes.ExchangeDailyQuote, time.Time, string) (*Revision, error)
+	// Revisions list revisions of exchange daily quote, ordered by number
+	Revisions(exchanges.Exchange, time.Time) ([]*Revision, error)
+	// LoadRevision load exchange daily quote of revision number
+	LoadRevision(exchanges.Exchange, time.Time, int) (*quotes.ExchangeDailyQuote, error)
+}
+
+// Revisioned store wrapper, keep every revision of saved exchange daily quotes in a blob
+//
+//	revisions/{exchange}/2006/01/02/index	revision list in json
+//	revisions/{exchange}/2006/01/02/{n}		exchange daily quote of revision n
+//
+// a save equal to the latest revision does not create new revision, delete keeps the revisions
+type Revisioned struct {
+	store  Store
+	blob   Blob
+	format Format
+	mutex  *sync.Mutex
+	// history object store keeps the blob, nil if blob is given by caller
+	history *Object
+}
+
+// NewRevisioned create revisioned store keep revisions in blob
+func NewRevisioned(store Store, blob Blob) *Revisioned {
+	return &Revisioned{store: store, blob: blob, format: DefaultFormat, mutex: new(sync.Mutex)}
+}
+
+// parseRevisioned parse revisioned store argument like <object store>|<store>, revisions are kept in the object store blob
+func parseRevisioned(arg string) (*Revisioned, error) {
+	parts := strings.SplitN(arg, "|", 2)
+	if len(parts) < 2 {
+		zap.L().Error("revisioned store arg invalid", zap.String("arg", arg))
+		return nil, fmt.Errorf("revisioned store arg invalid: %s", arg)
+	}
+
+	history, err := Parse(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	object, ok := history.(*Object)
+	if !ok {
+		history.Close()
+		return nil, fmt.Errorf("revisions must be kept in object store: %s", parts[0])
+	}
+
+	store, err := Parse(parts[1])
+	if err != nil {
+		object.Close()
+		return nil, err
+	}
+
+	return &Revisioned{store: store, blob: object.blob, format: object.format, mutex: new(sync.Mutex), history: object}, nil
+}
+
+// revisionPrefix return revision key prefix like revisions/{exchange}/2006/01/02/
+func (s Revisioned) revisionPrefix(exchange exchanges.Exchange, date time.Time) string {
+	return fmt.Sprintf("revisions/%s/%s/", exchange.Code(), date.Format("2006/01/02"))
+}
+
+// Exists check quote exists
+func (s Revisioned) Exists(exchange exchanges.Exchange, date time.Time) (bool, error) {
+	return s.store.Exists(exchange, date)
+}
+
+// Save save exchange daily quote as revision crawled now
+func (s Revisioned) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	_, err := s.SaveRevision(context.Background(), exchange, date, edq, time.Now(), "")
+	return err
+}
+
+// Load load exchange daily quote
+func (s Revisioned) Load(exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	return s.store.Load(exchange, date)
+}
+
+// Delete delete exchange daily quote, revisions are kept
+func (s Revisioned) Delete(exchange exchanges.Exchange, date time.Time) error {
+	return s.store.Delete(exchange, date)
+}
+
+// Close close underlying store and the history object store
+func (s Revisioned) Close() error {
+	err := s.store.Close()
+	if s.history == nil {
+		return err
+	}
+
+	historyErr := s.history.Close()
+	if err != nil {
+		return err
+	}
+
+	return historyErr
+}
+
+// SaveRevision save exchange daily quote crawled at time from source until context done
+// the currently stored quote becomes the first revision if revisions were not kept before,
+// once the quote is saved into the store its revision is always recorded
+func (s Revisioned) SaveRevision(ctx context.Context, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote, crawled time.Time, source string) (*Revision, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	store := WithContext(s.store)
+	revisions, err := s.loadRevisions(ctx, exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *quotes.ExchangeDailyQuote
+	if len(revisions) == 0 {
+		revisions, previous, err = s.baseline(ctx, store, exchange, date)
+	} else {
+		previous, err = s.loadRevision(ctx, exchange, date, revisions[len(revisions)-1].Number)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var change *RevisionChange
+	if previous != nil {
+		change = newRevisionChange(previous, edq)
+	}
+
+	if change != nil && change.IsEmpty() {
+		// nothing revised, keep the store and a newly recorded baseline in sync anyway
+		err = store.SaveContext(ctx, exchange, date, edq)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.saveRevisions(context.Background(), exchange, date, revisions)
+		if err != nil {
+			return nil, err
+		}
+
+		return revisions[len(revisions)-1], nil
+	}
+
+	revision, err := s.putRevision(ctx, exchange, date, len(revisions)+1, edq)
+	if err != nil {
+		return nil, err
+	}
+	revision.Crawled = crawled
+	revision.Source = source
+	revision.Change = change
+
+	err = store.SaveContext(ctx, exchange, date, edq)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.saveRevisions(context.Background(), exchange, date, append(revisions, revision))
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// baseline record currently stored exchange daily quote as the first revision, return nil quote if not stored
+func (s Revisioned) baseline(ctx context.Context, store ContextStore, exchange exchanges.Exchange, date time.Time) ([]*Revision, *quotes.ExchangeDailyQuote, error) {
+	exists, err := store.ExistsContext(ctx, exchange, date)
+	if err != nil || !exists {
+		return nil, nil, err
+	}
+
+	edq, err := store.LoadContext(ctx, exchange, date)
+	if err != nil {
+		zap.L().Error("load exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, nil, err
+	}
+
+	revision, err := s.putRevision(ctx, exchange, date, 1, edq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []*Revision{revision}, edq, nil
+}
+
+// putRevision encode exchange daily quote to revision object
+func (s Revisioned) putRevision(ctx context.Context, exchange exchanges.Exchange, date time.Time, number int, edq *quotes.ExchangeDailyQuote) (*Revision, error) {
+	buffer := new(bytes.Buffer)
+	err := encodeFormat(buffer, edq, s.format)
+	if err != nil {
+		zap.L().Error("encode exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	key := s.revisionPrefix(exchange, date) + strconv.Itoa(number)
+	err = s.blob.Put(ctx, key, buffer.Bytes())
+	if err != nil {
+		zap.L().Error("put revision failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date),
+			zap.String("key", key))
+		return nil, err
+	}
+
+	return &Revision{
+		Number:    number,
+		Checksum:  fmt.Sprintf("%08x", crc32.ChecksumIEEE(buffer.Bytes())),
+		Companies: len(edq.Companies),
+		Quotes:    len(edq.Quotes),
+	}, nil
+}
+
+// loadRevisions load revision list, return empty if not exists
+func (s Revisioned) loadRevisions(ctx context.Context, exchange exchanges.Exchange, date time.Time) ([]*Revision, error) {
+	key := s.revisionPrefix(exchange, date) + "index"
+	body, err := s.blob.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrBlobNotFound) {
+			return nil, nil
+		}
+
+		zap.L().Error("get revision index failed", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+	defer body.Close()
+
+	var revisions []*Revision
+	err = json.NewDecoder(body).Decode(&revisions)
+	if err != nil {
+		zap.L().Error("decode revision index failed", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// saveRevisions save revision list
+func (s Revisioned) saveRevisions(ctx context.Context, exchange exchanges.Exchange, date time.Time, revisions []*Revision) error {
+	buffer, err := json.Marshal(revisions)
+	if err != nil {
+		return err
+	}
+
+	key := s.revisionPrefix(exchange, date) + "index"
+	err = s.blob.Put(ctx, key, buffer)
+	if err != nil {
+		zap.L().Error("put revision index failed", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	return nil
+}
+
+// loadRevision decode exchange daily quote of revision object
+func (s Revisioned) loadRevision(ctx context.Context, exchange exchanges.Exchange, date time.Time, number int) (*quotes.ExchangeDailyQuote, error) {
+	key := s.revisionPrefix(exchange, date) + strconv.Itoa(number)
+	body, err := s.blob.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrBlobNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+
+		zap.L().Error("get revision failed", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+	defer body.Close()
+
+	edq, err := decodeFormat(body)
+	if err != nil {
+		zap.L().Error("decode revision failed", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	return edq, nil
+}
+
+// Revisions list revisions of exchange daily quote, ordered by number
+func (s Revisioned) Revisions(exchange exchanges.Exchange, date time.Time) ([]*Revision, error) {
+	return s.loadRevisions(context.Background(), exchange, date)
+}
+
+// LoadRevision load exchange daily quote of revision number
+func (s Revisioned) LoadRevision(exchange exchanges.Exchange, date time.Time, number int) (*quotes.ExchangeDailyQuote, error) {
+	return s.loadRevision(context.Background(), exchange, date, number)
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s Revisioned) Exchanges() ([]string, error) {
+	catalog, ok := s.store.(Catalog)
+	if !ok {
+		return nil, fmt.Errorf("store %T is not a catalog", s.store)
+	}
+
+	return catalog.Exchanges()
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s Revisioned) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	return Dates(s.store, exchange, start, end)
+}
+
+// Stat get stored exchange daily quote stats
+func (s Revisioned) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	catalog, ok := s.store.(Catalog)
+	if ok {
+		return catalog.Stat(exchange, date)
+	}
+
+	return statByLoad(s, exchange, date)
+}
+
+// AsOf read-only store pinned to a point in time, load the latest revision crawled at or before it
+// revisions stored before revisions were kept count as crawled at the beginning of time
+type AsOf struct {
+	store RevisionStore
+	asOf  time.Time
+}
+
+// NewAsOf create store pinned to revisions crawled at or before asOf
+func NewAsOf(store RevisionStore, asOf time.Time) *AsOf {
+	return &AsOf{store: store, asOf: asOf}
+}
+
+// find find the latest revision crawled at or before as of time
+func (s AsOf) find(exchange exchanges.Exchange, date time.Time) (*Revision, error) {
+	revisions, err := s.store.Revisions(exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *Revision
+	for _, revision := range revisions {
+		if revision.Crawled.After(s.asOf) {
+			break
+		}
+
+		found = revision
+	}
+
+	return found, nil
+}
+
+// Exists check quote revision exists as of time
+func (s AsOf) Exists(exchange exchanges.Exchange, date time.Time) (bool, error) {
+	revision, err := s.find(exchange, date)
+	return revision != nil, err
+}
+
+// Save always fail, as of store is read only
+func (s AsOf) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return fmt.Errorf("as of store is read only")
+}
+
+// Load load exchange daily quote revision as of time
+func (s AsOf) Load(exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	revision, err := s.find(exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision == nil {
+		return nil, ErrRevisionNotFound
+	}
+
+	return s.store.LoadRevision(exchange, date, revision.Number)
+}
+
+// Delete always fail, as of store is read only
+func (s AsOf) Delete(exchange exchanges.Exchange, date time.Time) error {
+	return fmt.Errorf("as of store is read only")
+}
+
+// Close close underlying store if it is closable
+func (s AsOf) Close() error {
+	closer, ok := s.store.(io.Closer)
+	if !ok {
+		return nil
+	}
+
+	return closer.Close()
+}
@@ -0,0 +1,118 @@
+package stores
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+)
+
+func TestRevisioned(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	crawled := date.AddDate(0, 0, 1)
+
+	// stored before revisions are kept
+	underlying := NewObject(NewMemoryBlob(), DefaultFormat)
+	original := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+	err := underlying.Save(exchange, date, original)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	store := NewRevisioned(underlying, NewMemoryBlob())
+
+	// same quote does not create revision
+	revision, err := store.SaveRevision(context.Background(), exchange, date, testExchangeDailyQuote(exchange, date, "AAPL", "MSFT"), crawled, "daily")
+	if err != nil {
+		t.Fatalf("SaveRevision() error = %v", err)
+	}
+
+	if revision.Number != 1 || !revision.Crawled.IsZero() {
+		t.Errorf("SaveRevision() = %+v, want baseline revision 1", revision)
+	}
+
+	revised := testExchangeDailyQuote(exchange, date, "AAPL", "GOOG")
+	(*revised.Quotes["AAPL"].Regular)[0].Close += 0.5
+	revision, err = store.SaveRevision(context.Background(), exchange, date, revised, crawled.AddDate(0, 0, 1), "history")
+	if err != nil {
+		t.Fatalf("SaveRevision() error = %v", err)
+	}
+
+	if revision.Number != 2 || revision.Source != "history" || revision.Change == nil {
+		t.Fatalf("SaveRevision() = %+v, want revision 2 with change", revision)
+	}
+
+	change := revision.Change
+	if len(change.Added) != 1 || change.Added[0] != "GOOG" ||
+		len(change.Removed) != 1 || change.Removed[0] != "MSFT" ||
+		len(change.Changed) != 1 || change.Changed[0] != "AAPL" || change.Bars != 1 {
+		t.Errorf("SaveRevision() change = %+v", change)
+	}
+
+	revisions, err := store.Revisions(exchange, date)
+	if err != nil {
+		t.Fatalf("Revisions() error = %v", err)
+	}
+
+	if len(revisions) != 2 {
+		t.Fatalf("Revisions() = %d revisions, want 2", len(revisions))
+	}
+
+	edq, err := store.LoadRevision(exchange, date, 1)
+	if err != nil {
+		t.Fatalf("LoadRevision() error = %v", err)
+	}
+
+	err = original.Equal(*edq)
+	if err != nil {
+		t.Errorf("LoadRevision(1) not equal: %v", err)
+	}
+
+	edq, err = store.Load(exchange, date)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	err = revised.Equal(*edq)
+	if err != nil {
+		t.Errorf("Load() not equal to latest revision: %v", err)
+	}
+
+	// pinned before the revision is crawled
+	edq, err = NewAsOf(store, crawled).Load(exchange, date)
+	if err != nil {
+		t.Fatalf("AsOf.Load() error = %v", err)
+	}
+
+	err = original.Equal(*edq)
+	if err != nil {
+		t.Errorf("AsOf.Load() not equal to revision 1: %v", err)
+	}
+
+	exists, err := NewAsOf(store, crawled).Exists(exchange, date.AddDate(0, 0, 1))
+	if err != nil || exists {
+		t.Errorf("AsOf.Exists() = %v, %v, want false", exists, err)
+	}
+}
+
+func TestRevisioned_SaveRevisionCancelled(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	store := NewRevisioned(NewObject(NewMemoryBlob(), DefaultFormat), NewMemoryBlob())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := store.SaveRevision(ctx, exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"), date, "daily")
+	if err != context.Canceled {
+		t.Errorf("SaveRevision() cancelled error = %v, want %v", err, context.Canceled)
+	}
+
+	exists, err := store.Exists(exchange, date)
+	if err != nil || exists {
+		t.Errorf("Exists() after cancelled save = %v, %v, want false", exists, err)
+	}
+}
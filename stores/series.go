@@ -0,0 +1,104 @@
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"github.com/nzai/qr/utils"
+	"go.uber.org/zap"
+)
+
+// SeriesReader define store can load company daily quotes in date range without loading whole exchange
+type SeriesReader interface {
+	// LoadCompany load company daily quotes between start and end date (inclusive), ordered by date
+	LoadCompany(exchanges.Exchange, string, time.Time, time.Time) ([]*quotes.CompanyDailyQuote, error)
+}
+
+// LoadCompany load company daily quotes between start and end date (inclusive)
+// use store native implement if it is a SeriesReader, otherwise load exchange daily quotes day by day
+func LoadCompany(store Store, exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	return LoadCompanyContext(context.Background(), store, exchange, companyCode, start, end)
+}
+
+// LoadCompanyContext load company daily quotes between start and end date (inclusive) until context done
+func LoadCompanyContext(ctx context.Context, store Store, exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	store, bound := bind(ctx, store)
+	reader, ok := store.(SeriesReader)
+	if ok && bound {
+		return reader.LoadCompany(exchange, companyCode, start, end)
+	}
+
+	if ok {
+		var cdqs []*quotes.CompanyDailyQuote
+		err := waitContext(ctx, func() error {
+			var err error
+			cdqs, err = reader.LoadCompany(exchange, companyCode, start, end)
+			return err
+		})
+
+		return cdqs, err
+	}
+
+	return loadCompanyByDay(ctx, WithContext(store), exchange, companyCode, start, end)
+}
+
+// loadCompanyByDay load company daily quotes from exchange daily quotes day by day
+func loadCompanyByDay(ctx context.Context, store ContextStore, exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	var cdqs []*quotes.CompanyDailyQuote
+	for _, date := range exchangeDates(exchange, start, end) {
+		exists, err := store.ExistsContext(ctx, exchange, date)
+		if err != nil {
+			zap.L().Error("check exchange daily quote exists failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return nil, err
+		}
+
+		if !exists {
+			continue
+		}
+
+		edq, err := store.LoadContext(ctx, exchange, date)
+		if err != nil {
+			zap.L().Error("load exchange daily quote failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return nil, err
+		}
+
+		cdq, found := edq.Quotes[companyCode]
+		if !found {
+			continue
+		}
+
+		cdqs = append(cdqs, cdq)
+	}
+
+	return cdqs, nil
+}
+
+// exchangeDateRange truncate start and end to zero clock in exchange location
+func exchangeDateRange(exchange exchanges.Exchange, start, end time.Time) (time.Time, time.Time) {
+	return utils.TodayZero(start.In(exchange.Location())), utils.TodayZero(end.In(exchange.Location()))
+}
+
+// exchangeDates return every zero clock dates in exchange location between start and end (inclusive)
+func exchangeDates(exchange exchanges.Exchange, start, end time.Time) []time.Time {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	var dates []time.Time
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+
+	return dates
+}
@@ -0,0 +1,98 @@
+package stores
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func testExchangeDailyQuote(exchange exchanges.Exchange, date time.Time, codes ...string) *quotes.ExchangeDailyQuote {
+	edq := &quotes.ExchangeDailyQuote{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: make(map[string]*quotes.Company, len(codes)),
+		Quotes:    make(map[string]*quotes.CompanyDailyQuote, len(codes)),
+	}
+
+	open := uint64(date.Add(time.Hour * 9).Add(time.Minute * 30).Unix())
+	for index, code := range codes {
+		company := &quotes.Company{Code: code, Name: code + " Inc."}
+		price := float32(10 * (index + 1))
+
+		edq.Companies[code] = company
+		edq.Quotes[code] = &quotes.CompanyDailyQuote{
+			Company: company,
+			Pre:     &quotes.Serial{{Timestamp: open - 3600, Open: price, Close: price, High: price, Low: price, Volume: 10}},
+			Regular: &quotes.Serial{
+				{Timestamp: open, Open: price, Close: price + 1, High: price + 2, Low: price - 1, Volume: 100},
+				{Timestamp: open + 60, Open: price + 1, Close: price + 2, High: price + 3, Low: price, Volume: 200},
+			},
+			Post: &quotes.Serial{},
+		}
+	}
+
+	return edq
+}
+
+func TestLoadCompany(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	start := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+
+	leveldb := NewLevelDB(t.TempDir())
+	defer leveldb.Close()
+
+	sqlite, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sqlite.Close()
+
+	_stores := map[string]Store{
+		"fs":      NewFileSystem(t.TempDir()),
+		"leveldb": leveldb,
+		"sqlite":  sqlite,
+	}
+
+	for name, store := range _stores {
+		t.Run(name, func(t *testing.T) {
+			for offset := 0; offset < 5; offset++ {
+				date := start.AddDate(0, 0, offset)
+				codes := []string{"AAPL", "MSFT"}
+				if offset == 2 {
+					codes = []string{"MSFT"}
+				}
+
+				err := store.Save(exchange, date, testExchangeDailyQuote(exchange, date, codes...))
+				if err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+
+			cdqs, err := LoadCompany(store, exchange, "AAPL", start.AddDate(0, 0, -1), start.AddDate(0, 0, 3))
+			if err != nil {
+				t.Fatalf("LoadCompany() error = %v", err)
+			}
+
+			if len(cdqs) != 3 {
+				t.Fatalf("LoadCompany() got %d days, want %d", len(cdqs), 3)
+			}
+
+			for index, cdq := range cdqs {
+				if cdq.Company.Code != "AAPL" {
+					t.Errorf("cdqs[%d] company = %s, want AAPL", index, cdq.Company.Code)
+				}
+
+				if len(*cdq.Regular) != 2 {
+					t.Errorf("cdqs[%d] regular length = %d, want 2", index, len(*cdq.Regular))
+				}
+
+				if index > 0 && (*cdq.Regular)[0].Timestamp <= (*cdqs[index-1].Regular)[0].Timestamp {
+					t.Errorf("cdqs[%d] is not ordered by date", index)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,726 @@
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nzai/qr/constants"
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // pure go sqlite driver
+)
+
+const (
+	sqliteDriverName = "sqlite"
+)
+
+// SQLite sqlite store
+// tables:
+// exchange_dates	exchange daily flag, trading = 1 / 0 (is trading day)
+// companies		exchange daily companies
+// quotes_1m		company daily quote serial (Pre|Regular|Post)
+// quotes_1d		company daily regular rollup
+// actions			company daily corporate actions ordered by seq
+// metas			exchange daily crawl meta in json
+type SQLite struct {
+	db  *sql.DB
+	ctx context.Context
+}
+
+func init() {
+	Register("sqlite", openSQLite)
+}
+
+// openSQLite open sqlite store by dsn like sqlite:///data/quotes.db
+func openSQLite(dsn *DSN) (Store, error) {
+	err := dsn.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if dsn.Location() == "" {
+		return nil, fmt.Errorf("sqlite store path undefined")
+	}
+
+	return NewSQLite(dsn.Location())
+}
+
+// sqliteQueryer define query methods shared by sql.DB and sql.Tx
+type sqliteQueryer interface {
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+// NewSQLite create new sqlite store
+func NewSQLite(path string) (*SQLite, error) {
+	db, err := sql.Open(sqliteDriverName, path)
+	if err != nil {
+		zap.L().Error("open sqlite failed", zap.Error(err), zap.String("path", path))
+		return nil, err
+	}
+
+	// sqlite allow only one writer at the same time
+	db.SetMaxOpenConns(1)
+
+	store := &SQLite{db: db}
+
+	err = store.ensureTables()
+	if err != nil {
+		zap.L().Error("ensure tables failed", zap.Error(err), zap.String("path", path))
+		db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Close close store
+func (s SQLite) Close() error {
+	if s.db == nil {
+		return nil
+	}
+
+	return s.db.Close()
+}
+
+func (s SQLite) ensureTables() error {
+	commands := []string{
+		"pragma journal_mode = wal",
+		"pragma busy_timeout = 10000",
+		"create table if not exists exchange_dates (exchange text not null, date text not null, trading integer not null, primary key (exchange, date))",
+		"create table if not exists companies (exchange text not null, date text not null, code text not null, name text not null, primary key (exchange, date, code))",
+		"create table if not exists quotes_1m (exchange text not null, code text not null, date text not null, serial text not null, ts integer not null, open real not null, close real not null, high real not null, low real not null, volume integer not null, primary key (exchange, code, date, serial, ts)) without rowid",
+		"create table if not exists quotes_1d (exchange text not null, code text not null, date text not null, ts integer not null, open real not null, close real not null, high real not null, low real not null, volume integer not null, primary key (exchange, code, date))",
+		"create table if not exists actions (exchange text not null, code text not null, date text not null, seq integer not null, type text not null, ts integer not null, amount real not null, numerator real not null, denominator real not null, primary key (exchange, code, date, seq))",
+		"create table if not exists metas (exchange text not null, date text not null, meta text not null, primary key (exchange, date))",
+		"create index if not exists quotes_1m_exchange_date on quotes_1m (exchange, date)",
+	}
+
+	for _, command := range commands {
+		_, err := s.db.Exec(command)
+		if err != nil {
+			zap.L().Error("ensure table failed", zap.Error(err), zap.String("command", command))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requestContext return context of queries, background if not bound
+func (s SQLite) requestContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+
+	return s.ctx
+}
+
+// withContext bind context to queries of reads
+func (s SQLite) withContext(ctx context.Context) Store {
+	s.ctx = ctx
+	return s
+}
+
+// ExistsContext check quote exists until context done
+func (s SQLite) ExistsContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (bool, error) {
+	s.ctx = ctx
+	return s.Exists(exchange, date)
+}
+
+// SaveContext save exchange daily quote unless context done, a started save is not cancelled
+func (s SQLite) SaveContext(ctx context.Context, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.Save(exchange, date, edq)
+}
+
+// LoadContext load exchange daily quote until context done
+func (s SQLite) LoadContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	s.ctx = ctx
+	return s.Load(exchange, date)
+}
+
+// DeleteContext delete exchange daily quote unless context done, a started delete is not cancelled
+func (s SQLite) DeleteContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.Delete(exchange, date)
+}
+
+// Exists check quote exists
+func (s SQLite) Exists(exchange exchanges.Exchange, date time.Time) (bool, error) {
+	var trading int
+	err := s.db.QueryRowContext(s.requestContext(), "select trading from exchange_dates where exchange=? and date=?",
+		exchange.Code(),
+		date.Format(constants.DatePattern)).Scan(&trading)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	if err != nil {
+		zap.L().Error("query exists failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Save save exchange daily quote
+func (s SQLite) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		zap.L().Error("begin transaction failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+	defer tx.Rollback()
+
+	// overwrite previous saved
+	err = s.delete(tx, exchange, date)
+	if err != nil {
+		return err
+	}
+
+	err = s.save(tx, exchange, date, edq)
+	if err != nil {
+		zap.L().Error("save exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	// validate
+	saved, err := s.load(context.Background(), tx, exchange, date)
+	if err != nil {
+		zap.L().Error("load saved exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	err = edq.Equal(*saved)
+	if err != nil {
+		zap.L().Error("validate saved exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		zap.L().Error("commit transaction failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+func (s SQLite) save(tx *sql.Tx, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	dateText := date.Format(constants.DatePattern)
+
+	trading := 1
+	if edq.IsEmpty() {
+		trading = 0
+	}
+
+	_, err := tx.Exec("insert into exchange_dates (exchange, date, trading) values (?, ?, ?)", exchange.Code(), dateText, trading)
+	if err != nil {
+		return err
+	}
+
+	meta, err := quotes.MarshalMeta(edq.Meta)
+	if err != nil {
+		return err
+	}
+
+	if meta != nil {
+		_, err = tx.Exec("insert into metas (exchange, date, meta) values (?, ?, ?)", exchange.Code(), dateText, string(meta))
+		if err != nil {
+			return err
+		}
+	}
+
+	if trading == 0 {
+		return nil
+	}
+
+	companyStmt, err := tx.Prepare("insert into companies (exchange, date, code, name) values (?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer companyStmt.Close()
+
+	for _, company := range edq.Companies {
+		_, err = companyStmt.Exec(exchange.Code(), dateText, company.Code, company.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, cdq := range edq.Quotes {
+		err = s.saveCompanyQuote(tx, exchange, dateText, cdq)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s SQLite) saveCompanyQuote(tx *sql.Tx, exchange exchanges.Exchange, dateText string, cdq *quotes.CompanyDailyQuote) error {
+	rollup := cdq.Regular.Rollup()
+	_, err := tx.Exec("insert into quotes_1d (exchange, code, date, ts, open, close, high, low, volume) values (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		exchange.Code(), cdq.Company.Code, dateText, rollup.Timestamp, rollup.Open, rollup.Close, rollup.High, rollup.Low, rollup.Volume)
+	if err != nil {
+		return err
+	}
+
+	for seq, action := range cdq.Actions {
+		_, err = tx.Exec("insert into actions (exchange, code, date, seq, type, ts, amount, numerator, denominator) values (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			exchange.Code(), cdq.Company.Code, dateText, seq, action.Type.String(), action.Timestamp, action.Amount, action.Numerator, action.Denominator)
+		if err != nil {
+			return err
+		}
+	}
+
+	stmt, err := tx.Prepare("insert into quotes_1m (exchange, code, date, serial, ts, open, close, high, low, volume) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	serials := map[quotes.SerialType]*quotes.Serial{
+		quotes.SerialTypePre:     cdq.Pre,
+		quotes.SerialTypeRegular: cdq.Regular,
+		quotes.SerialTypePost:    cdq.Post,
+	}
+
+	for serialType, serial := range serials {
+		if serial == nil {
+			continue
+		}
+
+		for _, quote := range *serial {
+			_, err = stmt.Exec(exchange.Code(), cdq.Company.Code, dateText, serialType.String(),
+				quote.Timestamp, quote.Open, quote.Close, quote.High, quote.Low, quote.Volume)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Load load exchange daily quote
+func (s SQLite) Load(exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	return s.load(s.requestContext(), s.db, exchange, date)
+}
+
+func (s SQLite) load(ctx context.Context, queryer sqliteQueryer, exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	dateText := date.Format(constants.DatePattern)
+
+	edq := &quotes.ExchangeDailyQuote{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: map[string]*quotes.Company{},
+		Quotes:    map[string]*quotes.CompanyDailyQuote{},
+	}
+
+	var trading int
+	err := queryer.QueryRowContext(ctx, "select trading from exchange_dates where exchange=? and date=?", exchange.Code(), dateText).Scan(&trading)
+	if err != nil {
+		zap.L().Error("load exchange daily failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	var meta string
+	err = queryer.QueryRowContext(ctx, "select meta from metas where exchange=? and date=?", exchange.Code(), dateText).Scan(&meta)
+	if err != nil && err != sql.ErrNoRows {
+		zap.L().Error("load exchange daily meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	edq.Meta, err = quotes.UnmarshalMeta([]byte(meta))
+	if err != nil {
+		zap.L().Error("decode exchange daily meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	// is trading day
+	if trading == 0 {
+		return edq, nil
+	}
+
+	rows, err := queryer.QueryContext(ctx, "select code, name from companies where exchange=? and date=?", exchange.Code(), dateText)
+	if err != nil {
+		zap.L().Error("load exchange daily companies failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	for rows.Next() {
+		company := new(quotes.Company)
+		err = rows.Scan(&company.Code, &company.Name)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		edq.Companies[company.Code] = company
+	}
+	rows.Close()
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	cdqs, err := s.loadCompanyQuotes(ctx, queryer, exchange, "code in (select code from quotes_1d where exchange=? and date=?) and exchange=? and date=?",
+		exchange.Code(), dateText, exchange.Code(), dateText)
+	if err != nil {
+		zap.L().Error("load exchange daily company quotes failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	for _, cdq := range cdqs[dateText] {
+		company, found := edq.Companies[cdq.Company.Code]
+		if !found {
+			continue
+		}
+
+		cdq.Company = company
+		edq.Quotes[company.Code] = cdq
+	}
+
+	return edq, nil
+}
+
+// loadCompanyQuotes load company daily quotes match condition, group by date and company code
+func (s SQLite) loadCompanyQuotes(ctx context.Context, queryer sqliteQueryer, exchange exchanges.Exchange, condition string, args ...interface{}) (map[string]map[string]*quotes.CompanyDailyQuote, error) {
+	cdqs := make(map[string]map[string]*quotes.CompanyDailyQuote)
+	get := func(dateText, code string) *quotes.CompanyDailyQuote {
+		daily, found := cdqs[dateText]
+		if !found {
+			daily = make(map[string]*quotes.CompanyDailyQuote)
+			cdqs[dateText] = daily
+		}
+
+		cdq, found := daily[code]
+		if !found {
+			cdq = &quotes.CompanyDailyQuote{
+				Company: &quotes.Company{Code: code},
+				Pre:     &quotes.Serial{},
+				Regular: &quotes.Serial{},
+				Post:    &quotes.Serial{},
+			}
+			daily[code] = cdq
+		}
+
+		return cdq
+	}
+
+	// company has quote only if rollup exists
+	rows, err := queryer.QueryContext(ctx, "select date, code from quotes_1d where "+condition, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var dateText, code string
+	for rows.Next() {
+		err = rows.Scan(&dateText, &code)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		get(dateText, code)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = queryer.QueryContext(ctx, "select date, code, type, ts, amount, numerator, denominator from actions where "+condition+" order by date, code, seq", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var actionType string
+	for rows.Next() {
+		var action quotes.Action
+		err = rows.Scan(&dateText, &code, &actionType, &action.Timestamp, &action.Amount, &action.Numerator, &action.Denominator)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		action.Type, err = quotes.ParseActionType(actionType)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if cdq, found := cdqs[dateText][code]; found {
+			cdq.Actions = append(cdq.Actions, action)
+		}
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = queryer.QueryContext(ctx, "select date, code, serial, ts, open, close, high, low, volume from quotes_1m where "+condition+" order by date, code, serial, ts", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var serialType string
+	for rows.Next() {
+		var quote quotes.Quote
+		err = rows.Scan(&dateText, &code, &serialType, &quote.Timestamp, &quote.Open, &quote.Close, &quote.High, &quote.Low, &quote.Volume)
+		if err != nil {
+			return nil, err
+		}
+
+		cdq, found := cdqs[dateText][code]
+		if !found {
+			continue
+		}
+
+		switch serialType {
+		case quotes.SerialTypePre.String():
+			*cdq.Pre = append(*cdq.Pre, quote)
+		case quotes.SerialTypeRegular.String():
+			*cdq.Regular = append(*cdq.Regular, quote)
+		case quotes.SerialTypePost.String():
+			*cdq.Post = append(*cdq.Post, quote)
+		}
+	}
+
+	return cdqs, rows.Err()
+}
+
+// Delete delete exchange daily quote
+func (s SQLite) Delete(exchange exchanges.Exchange, date time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		zap.L().Error("begin transaction failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+	defer tx.Rollback()
+
+	err = s.delete(tx, exchange, date)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		zap.L().Error("commit transaction failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+func (s SQLite) delete(tx *sql.Tx, exchange exchanges.Exchange, date time.Time) error {
+	tables := []string{"exchange_dates", "companies", "quotes_1m", "quotes_1d", "actions", "metas"}
+	for _, table := range tables {
+		_, err := tx.Exec("delete from "+table+" where exchange=? and date=?", exchange.Code(), date.Format(constants.DatePattern))
+		if err != nil {
+			zap.L().Error("delete exchange daily quote failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date),
+				zap.String("table", table))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadCompany load company daily quotes between start and end date
+func (s SQLite) LoadCompany(exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+	startText, endText := start.Format(constants.DatePattern), end.Format(constants.DatePattern)
+
+	rows, err := s.db.QueryContext(s.requestContext(), "select date, name from companies where exchange=? and code=? and date>=? and date<=? order by date",
+		exchange.Code(), companyCode, startText, endText)
+	if err != nil {
+		zap.L().Error("load company failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	var dateTexts []string
+	companies := make(map[string]*quotes.Company)
+	for rows.Next() {
+		var dateText string
+		company := &quotes.Company{Code: companyCode}
+		err = rows.Scan(&dateText, &company.Name)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		dateTexts = append(dateTexts, dateText)
+		companies[dateText] = company
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cdqs, err := s.loadCompanyQuotes(s.requestContext(), s.db, exchange, "exchange=? and code=? and date>=? and date<=?",
+		exchange.Code(), companyCode, startText, endText)
+	if err != nil {
+		zap.L().Error("load company quotes failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	result := make([]*quotes.CompanyDailyQuote, 0, len(dateTexts))
+	for _, dateText := range dateTexts {
+		cdq, found := cdqs[dateText][companyCode]
+		if !found {
+			continue
+		}
+
+		cdq.Company = companies[dateText]
+		result = append(result, cdq)
+	}
+
+	return result, nil
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s SQLite) Exchanges() ([]string, error) {
+	rows, err := s.db.QueryContext(s.requestContext(), "select distinct exchange from exchange_dates")
+	if err != nil {
+		zap.L().Error("query exchanges failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []string
+	var code string
+	for rows.Next() {
+		err = rows.Scan(&code)
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes, rows.Err()
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s SQLite) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	rows, err := s.db.QueryContext(s.requestContext(), "select date from exchange_dates where exchange=? and date>=? and date<=? order by date",
+		exchange.Code(),
+		start.Format(constants.DatePattern),
+		end.Format(constants.DatePattern))
+	if err != nil {
+		zap.L().Error("query exchange dates failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	var text string
+	for rows.Next() {
+		err = rows.Scan(&text)
+		if err != nil {
+			return nil, err
+		}
+
+		date, ok := parseDate(exchange, text, start, end)
+		if ok {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates, rows.Err()
+}
+
+// Stat get stored exchange daily quote stats
+func (s SQLite) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	stat := &DailyStat{Exchange: exchange.Code(), Date: date}
+	err := s.db.QueryRowContext(s.requestContext(), "select (select count(*) from companies where exchange=?1 and date=?2), (select count(*) from quotes_1d where exchange=?1 and date=?2)",
+		exchange.Code(),
+		date.Format(constants.DatePattern)).Scan(&stat.Companies, &stat.Quotes)
+	if err != nil {
+		zap.L().Error("query exchange daily stat failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	return stat, nil
+}
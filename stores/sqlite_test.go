@@ -0,0 +1,79 @@
+package stores
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+func TestSQLite(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	holiday := date.AddDate(0, 0, -1)
+
+	store, err := NewSQLite(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer store.Close()
+
+	edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+	edq.Quotes["AAPL"].Actions = quotes.Actions{{Type: quotes.ActionCashDividend, Timestamp: uint64(date.Unix()), Amount: 0.23}}
+	edq.Quotes["MSFT"].Actions = quotes.Actions{
+		{Type: quotes.ActionCashDividend, Timestamp: uint64(date.Unix()), Amount: 0.1},
+		{Type: quotes.ActionCashDividend, Timestamp: uint64(date.Unix()), Amount: 0.2},
+		{Type: quotes.ActionSplit, Timestamp: uint64(date.Unix()), Numerator: 4, Denominator: 1},
+	}
+
+	// save twice to overwrite
+	for index := 0; index < 2; index++ {
+		err = store.Save(exchange, date, edq)
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	err = store.Save(exchange, holiday, &quotes.ExchangeDailyQuote{Exchange: exchange.Code(), Date: holiday})
+	if err != nil {
+		t.Fatalf("Save() holiday error = %v", err)
+	}
+
+	for _, day := range []time.Time{date, holiday} {
+		exists, err := store.Exists(exchange, day)
+		if err != nil || !exists {
+			t.Fatalf("Exists(%v) = %v, %v, want true", day, exists, err)
+		}
+	}
+
+	loaded, err := store.Load(exchange, date)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	err = edq.Equal(*loaded)
+	if err != nil {
+		t.Errorf("Load() not equal: %v", err)
+	}
+
+	loaded, err = store.Load(exchange, holiday)
+	if err != nil {
+		t.Fatalf("Load() holiday error = %v", err)
+	}
+
+	if !loaded.IsEmpty() {
+		t.Errorf("Load() holiday is not empty")
+	}
+
+	err = store.Delete(exchange, date)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	exists, err := store.Exists(exchange, date)
+	if err != nil || exists {
+		t.Errorf("Exists() after delete = %v, %v, want false", exists, err)
+	}
+}
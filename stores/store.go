@@ -2,12 +2,9 @@ package stores
 
 import (
 	"fmt"
-	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
-	"github.com/mozillazg/go-cos"
 	"github.com/nzai/qr/exchanges"
 	"github.com/nzai/qr/quotes"
 	"go.uber.org/zap"
@@ -29,70 +26,63 @@ type Store interface {
 
 // Parse parse command argument
 func Parse(arg string) (Store, error) {
-	parts := strings.Split(arg, "|")
-	if len(parts) < 2 {
-		zap.L().Error("store arg invalid", zap.String("arg", arg))
-		return nil, fmt.Errorf("store arg invalid: %s", arg)
+	// tee|<store1>;<store2>[;quorum=N]
+	if strings.HasPrefix(arg, "tee|") {
+		tee, err := parseTee(strings.TrimPrefix(arg, "tee|"))
+		if err != nil {
+			// untyped nil, a nil pointer in Store is not nil
+			return nil, err
+		}
+
+		return tee, nil
 	}
 
-	switch parts[0] {
-	case "fs":
-		return NewFileSystem(parts[1]), nil
-	case "leveldb":
-		return NewLevelDB(parts[1]), nil
-	case "redis":
-		if len(parts) < 3 {
-			zap.L().Error("store arg invalid", zap.String("arg", arg))
-			return nil, fmt.Errorf("store arg invalid: %s", arg)
-		}
-		return NewRedis(parts[1], parts[2]), nil
-	case "cos":
-		if len(parts) < 4 {
-			zap.L().Error("store arg invalid", zap.String("arg", arg))
-			return nil, fmt.Errorf("store arg invalid: %s", arg)
+	// cached|<size>|<store>
+	if strings.HasPrefix(arg, "cached|") {
+		cached, err := parseCached(strings.TrimPrefix(arg, "cached|"))
+		if err != nil {
+			return nil, err
 		}
 
-		bucketURL, err := url.Parse(parts[1])
+		return cached, nil
+	}
+
+	// revisioned|<object store>|<store>
+	if strings.HasPrefix(arg, "revisioned|") {
+		revisioned, err := parseRevisioned(strings.TrimPrefix(arg, "revisioned|"))
 		if err != nil {
-			return nil, fmt.Errorf("bucket url arg invalid: %s", arg)
+			return nil, err
 		}
 
-		client := cos.NewClient(&cos.BaseURL{BucketURL: bucketURL}, &http.Client{
-			Transport: &cos.AuthorizationTransport{
-				SecretID:  parts[2],
-				SecretKey: parts[3],
-			},
-		})
+		return revisioned, nil
+	}
 
-		return NewCos(client), nil
-	case "influxdb":
-		if len(parts) < 3 {
-			zap.L().Error("store arg invalid", zap.String("arg", arg))
-			return nil, fmt.Errorf("store arg invalid: %s", arg)
+	// tiered|<age days>|<hot store>;<cold store>
+	if strings.HasPrefix(arg, "tiered|") {
+		tiered, err := parseTiered(strings.TrimPrefix(arg, "tiered|"))
+		if err != nil {
+			return nil, err
 		}
 
-		return NewInfluxDB(parts[1], parts[2]), nil
-	case "s3":
-		if len(parts) < 5 {
-			zap.L().Error("store arg invalid", zap.String("arg", arg))
-			return nil, fmt.Errorf("store arg invalid: %s", arg)
-		}
+		return tiered, nil
+	}
 
-		return NewS3(&S3Config{
-			AccessKeyID:     parts[1],
-			SecretAccessKey: parts[2],
-			Region:          parts[3],
-			Bucket:          parts[4],
-		}), nil
-	case "tdengine":
-		if len(parts) < 2 {
-			zap.L().Error("store arg invalid", zap.String("arg", arg))
-			return nil, fmt.Errorf("store arg invalid: %s", arg)
+	// legacy pipe-split argument like fs|/data
+	driver, _, _ := strings.Cut(arg, "|")
+	if driver != arg && !strings.Contains(driver, "://") {
+		dsn, err := parseLegacyDSN(arg)
+		if err != nil {
+			return nil, err
 		}
 
-		return NewTDEngine(parts[1])
-	default:
-		zap.L().Error("store type invalid", zap.String("type", parts[0]))
-		return nil, fmt.Errorf("store type invalid: %s", parts[0])
+		return Open(dsn)
 	}
+
+	dsn, err := ParseDSN(arg)
+	if err != nil {
+		zap.L().Error("store arg invalid", zap.Error(err))
+		return nil, fmt.Errorf("store arg invalid: %w", err)
+	}
+
+	return Open(dsn)
 }
@@ -0,0 +1,133 @@
+package stores
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+// QuoteStream define streaming exchange daily quote, company daily quotes are decoded one by one
+// close it after read
+type QuoteStream struct {
+	Exchange  string
+	Date      time.Time
+	Companies map[string]*quotes.Company
+	Meta      *quotes.Meta
+	count     int
+	iterator  quotes.CompanyQuoteIterator
+	closer    io.Closer
+}
+
+// newQuoteStream create quote stream from exchange daily quote reader
+func newQuoteStream(er *quotes.ExchangeDailyQuoteReader, iterator quotes.CompanyQuoteIterator, closer io.Closer) *QuoteStream {
+	return &QuoteStream{
+		Exchange:  er.Exchange,
+		Date:      er.Date,
+		Companies: er.Companies,
+		count:     er.Len(),
+		iterator:  iterator,
+		closer:    closer,
+	}
+}
+
+// Len get company daily quote count
+func (s QuoteStream) Len() int {
+	return s.count
+}
+
+// NextCompanyQuote get next company daily quote, return io.EOF after the last one
+func (s *QuoteStream) NextCompanyQuote() (*quotes.CompanyDailyQuote, error) {
+	return s.iterator.NextCompanyQuote()
+}
+
+// ReadAll read remaining company daily quotes into exchange daily quote
+func (s *QuoteStream) ReadAll() (*quotes.ExchangeDailyQuote, error) {
+	cdqs := make(map[string]*quotes.CompanyDailyQuote)
+	for {
+		cdq, err := s.NextCompanyQuote()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		cdqs[cdq.Company.Code] = cdq
+	}
+
+	return &quotes.ExchangeDailyQuote{
+		Exchange:  s.Exchange,
+		Date:      s.Date,
+		Companies: s.Companies,
+		Quotes:    cdqs,
+		Meta:      s.Meta,
+	}, nil
+}
+
+// Close release underlying reader
+func (s *QuoteStream) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
+
+// Streamer define store can stream exchange daily quote
+type Streamer interface {
+	// Stream open exchange daily quote stream
+	Stream(exchanges.Exchange, time.Time) (*QuoteStream, error)
+}
+
+// Stream open exchange daily quote stream, load whole exchange daily quote if store is not a Streamer
+func Stream(store Store, exchange exchanges.Exchange, date time.Time) (*QuoteStream, error) {
+	return StreamContext(context.Background(), store, exchange, date)
+}
+
+// StreamContext open exchange daily quote stream until context done
+// requests of stream opened from store can bind context are cancelled when context done
+func StreamContext(ctx context.Context, store Store, exchange exchanges.Exchange, date time.Time) (*QuoteStream, error) {
+	store, _ = bind(ctx, store)
+	streamer, ok := store.(Streamer)
+	if ok {
+		err := ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+
+		return streamer.Stream(exchange, date)
+	}
+
+	edq, err := WithContext(store).LoadContext(ctx, exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuoteStream{
+		Exchange:  edq.Exchange,
+		Date:      edq.Date,
+		Companies: edq.Companies,
+		Meta:      edq.Meta,
+		count:     len(edq.Quotes),
+		iterator:  edq.Iterator(),
+	}, nil
+}
+
+// multiCloser close closers in order, return the first error
+type multiCloser []io.Closer
+
+func (c multiCloser) Close() error {
+	var result error
+	for _, closer := range c {
+		err := closer.Close()
+		if err != nil && result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
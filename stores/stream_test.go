@@ -0,0 +1,68 @@
+package stores
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+)
+
+func TestStream(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT", "NVDA")
+
+	fs := NewFileSystem(t.TempDir())
+	err := fs.Save(exchange, date, edq)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	leveldb := NewLevelDB(t.TempDir())
+	defer leveldb.Close()
+
+	err = leveldb.Save(exchange, date, edq)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	for _, store := range []Store{fs, leveldb} {
+		stream, err := Stream(store, exchange, date)
+		if err != nil {
+			t.Fatalf("%T Stream() error = %v", store, err)
+		}
+
+		if stream.Exchange != edq.Exchange || !stream.Date.Equal(edq.Date) || len(stream.Companies) != len(edq.Companies) {
+			t.Errorf("%T Stream() header = %s %s %d, want %s %s %d", store,
+				stream.Exchange, stream.Date, len(stream.Companies), edq.Exchange, edq.Date, len(edq.Companies))
+		}
+
+		count := 0
+		for {
+			cdq, err := stream.NextCompanyQuote()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				t.Fatalf("%T NextCompanyQuote() error = %v", store, err)
+			}
+
+			err = edq.Quotes[cdq.Company.Code].Equal(*cdq)
+			if err != nil {
+				t.Errorf("%T NextCompanyQuote() %s not equal: %v", store, cdq.Company.Code, err)
+			}
+			count++
+		}
+
+		if count != len(edq.Quotes) {
+			t.Errorf("%T NextCompanyQuote() got %d quotes, want %d", store, count, len(edq.Quotes))
+		}
+
+		err = stream.Close()
+		if err != nil {
+			t.Errorf("%T Close() error = %v", store, err)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package stores
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/nzai/qr/exchanges"
 	"github.com/nzai/qr/quotes"
+	"github.com/nzai/qr/utils"
 	_ "github.com/taosdata/driver-go/v3/taosSql"
 	"go.uber.org/zap"
 )
@@ -25,10 +28,43 @@ const (
 // nasdaq_aapl_pre_raw_1m		pre
 // nasdaq_aapl_regular_raw_1m	regular
 // nasdaq_aapl_post_raw_1m		post
-// nasdaq_aapl_dividend			dividend
-// nasdaq_aapl_split			split
+// nasdaq_aapl_dividend			dividend, written before actions, only read
+// nasdaq_aapl_split			split, written before actions, only read
+// nasdaq_aapl_actions			corporate actions, row ts is date plus sequence milliseconds
+// nasdaq_meta					exchange daily crawl meta
+// nasdaq_aapl_meta				company daily crawl meta
 type TDEngine struct {
-	db *sql.DB
+	db  *sql.DB
+	ctx context.Context
+}
+
+func init() {
+	Register("tdengine", openTDEngine)
+}
+
+// openTDEngine open tdengine store by dsn like tdengine://root@127.0.0.1:6030/qr?protocol=tcp, password in dsn or secret
+func openTDEngine(dsn *DSN) (Store, error) {
+	err := dsn.Validate("protocol", "password")
+	if err != nil {
+		return nil, err
+	}
+
+	password, found := dsn.User.Password()
+	if !found {
+		password, err = dsn.Secret("password")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	address := fmt.Sprintf("%s:%s@%s(%s)%s",
+		dsn.User.Username(),
+		password,
+		dsn.Option("protocol", "tcp"),
+		dsn.Host,
+		dsn.Path)
+
+	return NewTDEngine(address)
 }
 
 // NewTDEngine create new tdengine store
@@ -72,6 +108,9 @@ func (s TDEngine) ensureTables() error {
 		"create stable if not exists symbols (ts timestamp, symbol nchar(50), name nchar(200)) tags (exchange nchar(50), type nchar(100))",
 		"create stable if not exists dividends (ts timestamp, amount float) tags (exchange nchar(50), symbol nchar(100))",
 		"create stable if not exists splits (ts timestamp, numerator float, denominator float) tags (exchange nchar(50), symbol nchar(100))",
+		"create stable if not exists actions (ts timestamp, type nchar(20), event_ts bigint, amount float, numerator float, denominator float) tags (exchange nchar(50), symbol nchar(100))",
+		"create stable if not exists metas (ts timestamp, version int, software nchar(50), crawl_start timestamp, crawl_end timestamp, attempted int, failed int, resolution bigint, quality nchar(1024)) tags (exchange nchar(50))",
+		"create stable if not exists company_metas (ts timestamp, source nchar(20), event_source nchar(20), currency nchar(10), previous_close float, exchange_name nchar(50), instrument_type nchar(50), timezone nchar(50), issues nchar(256)) tags (exchange nchar(50), symbol nchar(100))",
 	}
 
 	for _, command := range commands {
@@ -93,6 +132,10 @@ func (s TDEngine) exchangeCompaniesTableName(exchange exchanges.Exchange) string
 	return fmt.Sprintf("%s_companies", strings.ToLower(exchange.Code()))
 }
 
+func (s TDEngine) companyActionTableName(exchange exchanges.Exchange, company *quotes.Company) string {
+	return fmt.Sprintf("%s_%s_actions", strings.ToLower(exchange.Code()), strings.ToLower(company.Code))
+}
+
 func (s TDEngine) companySerialTableName(exchange exchanges.Exchange, company *quotes.Company, serialType quotes.SerialType) string {
 	return fmt.Sprintf("%s_%s_%s_raw_1m",
 		strings.ToLower(exchange.Code()),
@@ -100,12 +143,59 @@ func (s TDEngine) companySerialTableName(exchange exchanges.Exchange, company *q
 		strings.ToLower(serialType.String()))
 }
 
-func (s TDEngine) companyDividendTableName(exchange exchanges.Exchange, company *quotes.Company) string {
-	return fmt.Sprintf("%s_%s_dividend", strings.ToLower(exchange.Code()), strings.ToLower(company.Code))
+func (s TDEngine) exchangeMetaTableName(exchange exchanges.Exchange) string {
+	return fmt.Sprintf("%s_meta", strings.ToLower(exchange.Code()))
+}
+
+func (s TDEngine) companyMetaTableName(exchange exchanges.Exchange, companyCode string) string {
+	return fmt.Sprintf("%s_%s_meta", strings.ToLower(exchange.Code()), strings.ToLower(companyCode))
+}
+
+// requestContext return context of queries, background if not bound
+func (s TDEngine) requestContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+
+	return s.ctx
+}
+
+// withContext bind context to queries of reads
+func (s TDEngine) withContext(ctx context.Context) Store {
+	s.ctx = ctx
+	return s
+}
+
+// ExistsContext check quote exists until context done
+func (s TDEngine) ExistsContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (bool, error) {
+	s.ctx = ctx
+	return s.Exists(exchange, date)
+}
+
+// SaveContext save exchange daily quote unless context done, a started save is not cancelled
+func (s TDEngine) SaveContext(ctx context.Context, exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.Save(exchange, date, edq)
 }
 
-func (s TDEngine) companySplitTableName(exchange exchanges.Exchange, company *quotes.Company) string {
-	return fmt.Sprintf("%s_%s_split", strings.ToLower(exchange.Code()), strings.ToLower(company.Code))
+// LoadContext load exchange daily quote until context done
+func (s TDEngine) LoadContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	s.ctx = ctx
+	return s.Load(exchange, date)
+}
+
+// DeleteContext delete exchange daily quote unless context done, a started delete is not cancelled
+func (s TDEngine) DeleteContext(ctx context.Context, exchange exchanges.Exchange, date time.Time) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	return s.Delete(exchange, date)
 }
 
 // Exists check quote exists
@@ -115,7 +205,7 @@ func (s TDEngine) Exists(exchange exchanges.Exchange, date time.Time) (bool, err
 		date.Unix()*1000)
 
 	var done bool
-	row := s.db.QueryRow(command)
+	row := s.db.QueryRowContext(s.requestContext(), command)
 	err := row.Scan(&done)
 	if err == sql.ErrNoRows {
 		return false, nil
@@ -145,9 +235,135 @@ func (s TDEngine) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.
 		return err
 	}
 
+	err = s.saveMeta(exchange, date, edq.Meta)
+	if err != nil {
+		return err
+	}
+
 	return s.saveExchangeDone(exchange, date)
 }
 
+// Replace replace exchange daily quote, the new day is written before previous rows not in it are deleted,
+// rows of companies not in the new day are deleted too
+func (s TDEngine) Replace(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	exists, err := s.Exists(exchange, date)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return s.Save(exchange, date, edq)
+	}
+
+	previous, err := s.Load(exchange, date)
+	if err != nil {
+		return err
+	}
+
+	err = s.Save(exchange, date, edq)
+	if err != nil {
+		return err
+	}
+
+	for code, cdq := range previous.Quotes {
+		current, found := edq.Quotes[code]
+		if !found {
+			err = s.deleteCompanyRows(exchange, code, date)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, stale := range staleRanges(cdq, current) {
+			command := fmt.Sprintf("delete from quotes where ts>=%d and ts<=%d and exchange='%s' and symbol='%s'",
+				stale.Start*1000,
+				stale.End*1000,
+				exchange.Code(),
+				code)
+			_, err = s.db.Exec(command)
+			if err != nil {
+				zap.L().Error("delete stale quote rows failed",
+					zap.Error(err),
+					zap.String("command", command),
+					zap.String("exchange", exchange.Code()),
+					zap.String("company", code),
+					zap.Time("date", date))
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s TDEngine) saveMeta(exchange exchanges.Exchange, date time.Time, meta *quotes.Meta) error {
+	if meta == nil {
+		return nil
+	}
+
+	command := fmt.Sprintf("insert into %s using metas tags('%s') values(%d, %d, \"%s\", %d, %d, %d, %d, %d, '%s')",
+		s.exchangeMetaTableName(exchange),
+		exchange.Code(),
+		date.Unix()*1000,
+		meta.Version,
+		meta.Software,
+		meta.CrawlStart.UnixMilli(),
+		meta.CrawlEnd.UnixMilli(),
+		meta.Attempted,
+		meta.Failed,
+		int64(meta.Resolution),
+		formatQuality(meta.Quality))
+	_, err := s.db.Exec(command)
+	if err != nil {
+		zap.L().Error("save exchange meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	sb := new(strings.Builder)
+	ts := date.Unix() * 1000
+	index := 0
+	for code, cm := range meta.Companies {
+		if index%100 == 0 {
+			sb.Reset()
+			sb.WriteString("insert into ")
+		}
+
+		fmt.Fprintf(sb, "%s using company_metas tags('%s', '%s') values(%d, '%s', '%s', '%s', %f, \"%s\", '%s', '%s', '%s') ",
+			s.companyMetaTableName(exchange, code),
+			exchange.Code(),
+			code,
+			ts,
+			cm.Source,
+			cm.EventSource,
+			cm.Currency,
+			cm.PreviousClose,
+			cm.ExchangeName,
+			cm.InstrumentType,
+			cm.Timezone,
+			formatIssues(cm.Issues))
+
+		index++
+
+		if index%100 == 0 || index == len(meta.Companies) {
+			_, err = s.db.Exec(sb.String())
+			if err != nil {
+				zap.L().Error("save company metas failed",
+					zap.Error(err),
+					zap.String("exchange", exchange.Code()),
+					zap.Time("date", date),
+					zap.String("sql", sb.String()))
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (s TDEngine) saveCompanies(exchange exchanges.Exchange, date time.Time, companies map[string]*quotes.Company) error {
 	if len(companies) == 0 {
 		return nil
@@ -238,12 +454,7 @@ func (s TDEngine) saveCompanyQuotes(exchange exchanges.Exchange, company *quotes
 		return err
 	}
 
-	err = s.saveCompanyDividend(exchange, company, date, cdq.Dividend)
-	if err != nil {
-		return err
-	}
-
-	err = s.saveCompanySplit(exchange, company, date, cdq.Split)
+	err = s.saveCompanyActions(exchange, company, date, cdq.Actions)
 	if err != nil {
 		return err
 	}
@@ -280,51 +491,35 @@ func (s TDEngine) saveCompanySerial(exchange exchanges.Exchange, company *quotes
 	return nil
 }
 
-func (s TDEngine) saveCompanyDividend(exchange exchanges.Exchange, company *quotes.Company, date time.Time, dividend *quotes.Dividend) error {
-	if dividend == nil || !dividend.Enable {
+func (s TDEngine) saveCompanyActions(exchange exchanges.Exchange, company *quotes.Company, date time.Time, actions quotes.Actions) error {
+	if len(actions) == 0 {
 		return nil
 	}
 
-	command := fmt.Sprintf("insert into %s using dividends tags('%s', '%s') values(%d, %f)",
-		s.companyDividendTableName(exchange, company),
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "insert into %s using actions tags('%s', '%s') values ",
+		s.companyActionTableName(exchange, company),
 		exchange.Code(),
-		company.Code,
-		dividend.Timestamp*1000,
-		dividend.Amount)
-	_, err := s.db.Exec(command)
-	if err != nil {
-		zap.L().Error("save company dividend failed",
-			zap.Error(err),
-			zap.String("exchange", exchange.Code()),
-			zap.String("company", company.Code),
-			zap.Time("date", date),
-			zap.Any("dividend", dividend))
-		return err
+		company.Code)
+
+	for seq, action := range actions {
+		fmt.Fprintf(sb, "(%d, '%s', %d, %f, %f, %f) ",
+			date.Unix()*1000+int64(seq),
+			action.Type.String(),
+			action.Timestamp,
+			action.Amount,
+			action.Numerator,
+			action.Denominator)
 	}
 
-	return nil
-}
-
-func (s TDEngine) saveCompanySplit(exchange exchanges.Exchange, company *quotes.Company, date time.Time, split *quotes.Split) error {
-	if split == nil || !split.Enable {
-		return nil
-	}
-
-	command := fmt.Sprintf("insert into %s using splits tags('%s', '%s') values(%d, %f, %f)",
-		s.companySplitTableName(exchange, company),
-		exchange.Code(),
-		company.Code,
-		split.Timestamp*1000,
-		split.Numerator,
-		split.Denominator)
-	_, err := s.db.Exec(command)
+	_, err := s.db.Exec(sb.String())
 	if err != nil {
-		zap.L().Error("save company split failed",
+		zap.L().Error("save company actions failed",
 			zap.Error(err),
 			zap.String("exchange", exchange.Code()),
 			zap.String("company", company.Code),
 			zap.Time("date", date),
-			zap.Any("split", split))
+			zap.Any("actions", actions))
 		return err
 	}
 
@@ -349,6 +544,93 @@ func (s TDEngine) saveExchangeDone(exchange exchanges.Exchange, date time.Time)
 	return nil
 }
 
+// SaveCompany add or replace company daily quote, previous rows of the company in the day are deleted first
+func (s TDEngine) SaveCompany(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	err := s.deleteCompanyRows(exchange, cdq.Company.Code, date)
+	if err != nil {
+		return err
+	}
+
+	err = s.saveCompanies(exchange, date, map[string]*quotes.Company{cdq.Company.Code: cdq.Company})
+	if err != nil {
+		return err
+	}
+
+	err = s.saveCompanyQuotes(exchange, cdq.Company, date, cdq)
+	if err != nil {
+		return err
+	}
+
+	return s.saveExchangeDone(exchange, date)
+}
+
+// DeleteCompany delete company quote, dividend, split, meta and symbol rows in the day
+// tdengine can only delete rows by timestamp and tags, so symbol rows of the day are rewritten without the company
+func (s TDEngine) DeleteCompany(exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	companies, err := s.loadCompanies(exchange, date)
+	if err != nil {
+		return err
+	}
+
+	err = s.deleteCompanyRows(exchange, companyCode, date)
+	if err != nil {
+		return err
+	}
+
+	commands := []string{
+		fmt.Sprintf("delete from company_metas where ts=%d and exchange='%s' and symbol='%s'", date.Unix()*1000, exchange.Code(), companyCode),
+	}
+
+	_, found := companies[companyCode]
+	if found {
+		delete(companies, companyCode)
+		commands = append(commands, fmt.Sprintf("delete from symbols where ts=%d and exchange='%s' and type='company'", date.Unix()*1000, exchange.Code()))
+	}
+
+	for _, command := range commands {
+		_, err = s.db.Exec(command)
+		if err != nil {
+			zap.L().Error("delete company rows failed",
+				zap.Error(err),
+				zap.String("command", command),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", companyCode),
+				zap.Time("date", date))
+			return err
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	return s.saveCompanies(exchange, date, companies)
+}
+
+// deleteCompanyRows delete company quote, dividend and split rows in [date, date + 1)
+func (s TDEngine) deleteCompanyRows(exchange exchanges.Exchange, companyCode string, date time.Time) error {
+	for _, stable := range []string{"quotes", "dividends", "splits", "actions"} {
+		command := fmt.Sprintf("delete from %s where ts>=%d and ts<%d and exchange='%s' and symbol='%s'",
+			stable,
+			date.Unix()*1000,
+			date.AddDate(0, 0, 1).Unix()*1000,
+			exchange.Code(),
+			companyCode)
+		_, err := s.db.Exec(command)
+		if err != nil {
+			zap.L().Error("delete company rows failed",
+				zap.Error(err),
+				zap.String("command", command),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", companyCode),
+				zap.Time("date", date))
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Load load exchange daily quote
 func (s TDEngine) Load(exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
 	companies, err := s.loadCompanies(exchange, date)
@@ -361,19 +643,106 @@ func (s TDEngine) Load(exchange exchanges.Exchange, date time.Time) (*quotes.Exc
 		return nil, err
 	}
 
+	meta, err := s.loadMeta(exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
 	return &quotes.ExchangeDailyQuote{
 		Exchange:  exchange.Code(),
 		Date:      date,
 		Companies: companies,
 		Quotes:    companyQuotes,
+		Meta:      meta,
 	}, nil
 }
 
+// loadMeta load exchange daily meta, return nil if not exists
+func (s TDEngine) loadMeta(exchange exchanges.Exchange, date time.Time) (*quotes.Meta, error) {
+	command := fmt.Sprintf("select version, software, crawl_start, crawl_end, attempted, failed, resolution, quality from metas where exchange='%s' and ts=%d",
+		exchange.Code(),
+		date.Unix()*1000)
+
+	meta := &quotes.Meta{Companies: make(map[string]*quotes.CompanyMeta)}
+	row := s.db.QueryRowContext(s.requestContext(), command)
+	var quality string
+	err := row.Scan(&meta.Version, &meta.Software, &meta.CrawlStart, &meta.CrawlEnd, &meta.Attempted, &meta.Failed, &meta.Resolution, &quality)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		zap.L().Error("scan exchange meta failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	meta.Quality, err = parseQuality(quality)
+	if err != nil {
+		zap.L().Error("parse exchange daily quality failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	command = fmt.Sprintf("select symbol, source, event_source, currency, previous_close, exchange_name, instrument_type, timezone, issues from company_metas where exchange='%s' and ts=%d",
+		exchange.Code(),
+		date.Unix()*1000)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
+	if err != nil {
+		zap.L().Error("load company metas failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code, issues string
+		cm := new(quotes.CompanyMeta)
+		err = rows.Scan(&code, &cm.Source, &cm.EventSource, &cm.Currency, &cm.PreviousClose, &cm.ExchangeName, &cm.InstrumentType, &cm.Timezone, &issues)
+		if err != nil {
+			zap.L().Error("scan company meta failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return nil, err
+		}
+
+		cm.Issues, err = parseIssues(issues)
+		if err != nil {
+			zap.L().Error("parse company issues failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", code),
+				zap.Time("date", date))
+			return nil, err
+		}
+
+		meta.Companies[code] = cm
+	}
+
+	err = rows.Err()
+	if err != nil {
+		zap.L().Error("scan rows failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	return meta, nil
+}
+
 func (s TDEngine) loadCompanies(exchange exchanges.Exchange, date time.Time) (map[string]*quotes.Company, error) {
 	command := fmt.Sprintf("select symbol, name from symbols where exchange='%s' and type='company' and ts=%d",
 		exchange.Code(),
 		date.Unix()*1000)
-	rows, err := s.db.Query(command)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
 	if err != nil {
 		zap.L().Error("load exchange companies failed",
 			zap.Error(err),
@@ -450,30 +819,40 @@ func (s TDEngine) loadCompanyQuotes(exchange exchanges.Exchange, date time.Time,
 		return nil, err
 	}
 
+	actions, err := s.loadCompanyActionRange(exchange, date, date.AddDate(0, 0, 1), company)
+	if err != nil {
+		return nil, err
+	}
+
 	return &quotes.CompanyDailyQuote{
-		Company:  company,
-		Dividend: dividend,
-		Split:    split,
-		Pre:      pre,
-		Regular:  regular,
-		Post:     post,
+		Company: company,
+		Actions: mergeActions(actions[date], dividend, split),
+		Pre:     pre,
+		Regular: regular,
+		Post:    post,
 	}, nil
 }
 
 func (s TDEngine) loadCompanySerial(exchange exchanges.Exchange, date time.Time, company *quotes.Company, serialType quotes.SerialType) (*quotes.Serial, error) {
+	return s.loadCompanySerialRange(exchange, date, date.AddDate(0, 0, 1), company, serialType)
+}
+
+// loadCompanySerialRange load company quote serial in [start, end)
+func (s TDEngine) loadCompanySerialRange(exchange exchanges.Exchange, start, end time.Time, company *quotes.Company, serialType quotes.SerialType) (*quotes.Serial, error) {
 	command := fmt.Sprintf("select ts, open, close, high, low, volume from quotes where exchange='%s' and symbol='%s' and type='%s' and ts>=%d and ts<%d order by ts",
 		exchange.Code(),
 		company.Code,
 		serialType.String(),
-		date.Unix()*1000,
-		date.Unix()*1000+86400000)
-	rows, err := s.db.Query(command)
+		start.Unix()*1000,
+		end.Unix()*1000)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
 	if err != nil {
 		zap.L().Error("load company serial failed",
 			zap.Error(err),
 			zap.String("exchange", exchange.Code()),
 			zap.String("company", company.Code),
-			zap.Time("date", date),
+			zap.Time("start", start),
+			zap.Time("end", end),
 			zap.String("serialType", serialType.String()))
 		return nil, err
 	}
@@ -490,7 +869,8 @@ func (s TDEngine) loadCompanySerial(exchange exchanges.Exchange, date time.Time,
 				zap.Error(err),
 				zap.String("exchange", exchange.Code()),
 				zap.String("company", company.Code),
-				zap.Time("date", date),
+				zap.Time("start", start),
+				zap.Time("end", end),
 				zap.String("serialType", serialType.String()))
 			return nil, err
 		}
@@ -511,7 +891,8 @@ func (s TDEngine) loadCompanySerial(exchange exchanges.Exchange, date time.Time,
 			zap.Error(err),
 			zap.String("exchange", exchange.Code()),
 			zap.String("company", company.Code),
-			zap.Time("date", date),
+			zap.Time("start", start),
+			zap.Time("end", end),
 			zap.String("serialType", serialType.String()))
 		return nil, err
 	}
@@ -529,7 +910,7 @@ func (s TDEngine) loadCompanyDividend(exchange exchanges.Exchange, date time.Tim
 
 	var t time.Time
 	var amount float32
-	row := s.db.QueryRow(command)
+	row := s.db.QueryRowContext(s.requestContext(), command)
 	err := row.Scan(&t, &amount)
 	if err == sql.ErrNoRows {
 		return dividend, nil
@@ -561,7 +942,7 @@ func (s TDEngine) loadCompanySplit(exchange exchanges.Exchange, date time.Time,
 
 	var t time.Time
 	var numerator, denominator float32
-	row := s.db.QueryRow(command)
+	row := s.db.QueryRowContext(s.requestContext(), command)
 	err := row.Scan(&t, &numerator, &denominator)
 	if err == sql.ErrNoRows {
 		return split, nil
@@ -584,8 +965,431 @@ func (s TDEngine) loadCompanySplit(exchange exchanges.Exchange, date time.Time,
 	return split, nil
 }
 
+// LoadCompany load company daily quotes between start and end date
+func (s TDEngine) LoadCompany(exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	dates := exchangeDates(exchange, start, end)
+	if len(dates) == 0 {
+		return nil, nil
+	}
+	start, end = dates[0], dates[len(dates)-1].AddDate(0, 0, 1)
+
+	companies, err := s.loadCompanyRange(exchange, companyCode, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(companies) == 0 {
+		return nil, nil
+	}
+
+	company := &quotes.Company{Code: companyCode}
+	serials := make(map[quotes.SerialType]map[time.Time]*quotes.Serial, 3)
+	for _, serialType := range []quotes.SerialType{quotes.SerialTypePre, quotes.SerialTypeRegular, quotes.SerialTypePost} {
+		serial, err := s.loadCompanySerialRange(exchange, start, end, company, serialType)
+		if err != nil {
+			return nil, err
+		}
+
+		serials[serialType] = s.splitSerialByDate(exchange, serial)
+	}
+
+	dividends, err := s.loadCompanyDividendRange(exchange, start, end, company)
+	if err != nil {
+		return nil, err
+	}
+
+	splits, err := s.loadCompanySplitRange(exchange, start, end, company)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := s.loadCompanyActionRange(exchange, start, end, company)
+	if err != nil {
+		return nil, err
+	}
+
+	cdqs := make([]*quotes.CompanyDailyQuote, 0, len(companies))
+	for _, date := range dates {
+		company, found := companies[date]
+		if !found {
+			continue
+		}
+
+		cdq := &quotes.CompanyDailyQuote{
+			Company: company,
+			Actions: mergeActions(actions[date], dividends[date], splits[date]),
+			Pre:     &quotes.Serial{},
+			Regular: &quotes.Serial{},
+			Post:    &quotes.Serial{},
+		}
+
+		if serial, found := serials[quotes.SerialTypePre][date]; found {
+			cdq.Pre = serial
+		}
+
+		if serial, found := serials[quotes.SerialTypeRegular][date]; found {
+			cdq.Regular = serial
+		}
+
+		if serial, found := serials[quotes.SerialTypePost][date]; found {
+			cdq.Post = serial
+		}
+
+		cdqs = append(cdqs, cdq)
+	}
+
+	return cdqs, nil
+}
+
+// loadCompanyRange load company listed dates in [start, end)
+func (s TDEngine) loadCompanyRange(exchange exchanges.Exchange, companyCode string, start, end time.Time) (map[time.Time]*quotes.Company, error) {
+	command := fmt.Sprintf("select ts, name from symbols where exchange='%s' and type='company' and symbol='%s' and ts>=%d and ts<%d",
+		exchange.Code(),
+		companyCode,
+		start.Unix()*1000,
+		end.Unix()*1000)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
+	if err != nil {
+		zap.L().Error("load company failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+	defer rows.Close()
+
+	companies := make(map[time.Time]*quotes.Company)
+	var t time.Time
+	var name string
+	for rows.Next() {
+		err = rows.Scan(&t, &name)
+		if err != nil {
+			zap.L().Error("scan company failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", companyCode),
+				zap.Time("start", start),
+				zap.Time("end", end))
+			return nil, err
+		}
+
+		companies[utils.TodayZero(t.In(exchange.Location()))] = &quotes.Company{Code: companyCode, Name: name}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		zap.L().Error("scan rows failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", companyCode),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+
+	return companies, nil
+}
+
+// splitSerialByDate group quote serial by exchange date
+func (s TDEngine) splitSerialByDate(exchange exchanges.Exchange, serial *quotes.Serial) map[time.Time]*quotes.Serial {
+	serials := make(map[time.Time]*quotes.Serial)
+	for _, quote := range *serial {
+		date := utils.TodayZero(time.Unix(int64(quote.Timestamp), 0).In(exchange.Location()))
+		daily, found := serials[date]
+		if !found {
+			daily = new(quotes.Serial)
+			serials[date] = daily
+		}
+
+		*daily = append(*daily, quote)
+	}
+
+	return serials
+}
+
+// loadCompanyDividendRange load company dividends in [start, end)
+func (s TDEngine) loadCompanyDividendRange(exchange exchanges.Exchange, start, end time.Time, company *quotes.Company) (map[time.Time]*quotes.Dividend, error) {
+	command := fmt.Sprintf("select ts, amount from dividends where exchange='%s' and symbol='%s' and ts>=%d and ts<%d",
+		exchange.Code(),
+		company.Code,
+		start.Unix()*1000,
+		end.Unix()*1000)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
+	if err != nil {
+		zap.L().Error("load company dividends failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", company.Code),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+	defer rows.Close()
+
+	dividends := make(map[time.Time]*quotes.Dividend)
+	var t time.Time
+	var amount float32
+	for rows.Next() {
+		err = rows.Scan(&t, &amount)
+		if err != nil {
+			zap.L().Error("scan dividend failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", company.Code),
+				zap.Time("start", start),
+				zap.Time("end", end))
+			return nil, err
+		}
+
+		dividends[utils.TodayZero(t.In(exchange.Location()))] = &quotes.Dividend{
+			Enable:    true,
+			Timestamp: uint64(t.Unix()),
+			Amount:    amount,
+		}
+	}
+
+	return dividends, rows.Err()
+}
+
+// loadCompanySplitRange load company splits in [start, end)
+func (s TDEngine) loadCompanySplitRange(exchange exchanges.Exchange, start, end time.Time, company *quotes.Company) (map[time.Time]*quotes.Split, error) {
+	command := fmt.Sprintf("select ts, numerator, denominator from splits where exchange='%s' and symbol='%s' and ts>=%d and ts<%d",
+		exchange.Code(),
+		company.Code,
+		start.Unix()*1000,
+		end.Unix()*1000)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
+	if err != nil {
+		zap.L().Error("load company splits failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", company.Code),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+	defer rows.Close()
+
+	splits := make(map[time.Time]*quotes.Split)
+	var t time.Time
+	var numerator, denominator float32
+	for rows.Next() {
+		err = rows.Scan(&t, &numerator, &denominator)
+		if err != nil {
+			zap.L().Error("scan split failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", company.Code),
+				zap.Time("start", start),
+				zap.Time("end", end))
+			return nil, err
+		}
+
+		splits[utils.TodayZero(t.In(exchange.Location()))] = &quotes.Split{
+			Enable:      true,
+			Timestamp:   uint64(t.Unix()),
+			Numerator:   numerator,
+			Denominator: denominator,
+		}
+	}
+
+	return splits, rows.Err()
+}
+
+// loadCompanyActionRange load company actions in [start, end) group by exchange date
+func (s TDEngine) loadCompanyActionRange(exchange exchanges.Exchange, start, end time.Time, company *quotes.Company) (map[time.Time]quotes.Actions, error) {
+	command := fmt.Sprintf("select ts, type, event_ts, amount, numerator, denominator from actions where exchange='%s' and symbol='%s' and ts>=%d and ts<%d order by ts",
+		exchange.Code(),
+		company.Code,
+		start.Unix()*1000,
+		end.Unix()*1000)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
+	if err != nil {
+		zap.L().Error("load company actions failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.String("company", company.Code),
+			zap.Time("start", start),
+			zap.Time("end", end))
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := make(map[time.Time]quotes.Actions)
+	var t time.Time
+	var actionType string
+	var eventTimestamp int64
+	var amount, numerator, denominator float32
+	for rows.Next() {
+		err = rows.Scan(&t, &actionType, &eventTimestamp, &amount, &numerator, &denominator)
+		if err != nil {
+			zap.L().Error("scan action failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", company.Code),
+				zap.Time("start", start),
+				zap.Time("end", end))
+			return nil, err
+		}
+
+		_type, err := quotes.ParseActionType(actionType)
+		if err != nil {
+			zap.L().Error("invalid action type",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.String("company", company.Code),
+				zap.Time("start", start),
+				zap.Time("end", end))
+			return nil, err
+		}
+
+		date := utils.TodayZero(t.In(exchange.Location()))
+		actions[date] = append(actions[date], quotes.Action{
+			Type:        _type,
+			Timestamp:   uint64(eventTimestamp),
+			Amount:      amount,
+			Numerator:   numerator,
+			Denominator: denominator,
+		})
+	}
+
+	return actions, rows.Err()
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s TDEngine) Exchanges() ([]string, error) {
+	command := "select distinct exchange from tasks where type='raw_1m'"
+	rows, err := s.db.QueryContext(s.requestContext(), command)
+	if err != nil {
+		zap.L().Error("query exchanges failed", zap.Error(err), zap.String("command", command))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []string
+	var code string
+	for rows.Next() {
+		err = rows.Scan(&code)
+		if err != nil {
+			zap.L().Error("scan exchange failed", zap.Error(err), zap.String("command", command))
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes, rows.Err()
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s TDEngine) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	start, end = exchangeDateRange(exchange, start, end)
+
+	command := fmt.Sprintf("select ts from tasks where exchange='%s' and type='raw_1m' and done=true and ts>=%d and ts<%d order by ts",
+		exchange.Code(),
+		start.Unix()*1000,
+		end.AddDate(0, 0, 1).Unix()*1000)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
+	if err != nil {
+		zap.L().Error("query exchange dates failed",
+			zap.Error(err),
+			zap.String("command", command),
+			zap.String("exchange", exchange.Code()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	var t time.Time
+	for rows.Next() {
+		err = rows.Scan(&t)
+		if err != nil {
+			zap.L().Error("scan exchange date failed",
+				zap.Error(err),
+				zap.String("command", command),
+				zap.String("exchange", exchange.Code()))
+			return nil, err
+		}
+
+		dates = append(dates, t.In(exchange.Location()))
+	}
+
+	return dates, rows.Err()
+}
+
+// Stat get stored exchange daily quote stats
+func (s TDEngine) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	command := fmt.Sprintf("select count(*) from symbols where exchange='%s' and type='company' and ts=%d",
+		exchange.Code(),
+		date.Unix()*1000)
+
+	var companies int64
+	err := s.db.QueryRowContext(s.requestContext(), command).Scan(&companies)
+	if err != nil && err != sql.ErrNoRows {
+		zap.L().Error("count exchange companies failed",
+			zap.Error(err),
+			zap.String("command", command),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	command = fmt.Sprintf("select symbol, count(*) from quotes where exchange='%s' and ts>=%d and ts<%d partition by symbol",
+		exchange.Code(),
+		date.Unix()*1000,
+		date.AddDate(0, 0, 1).Unix()*1000)
+	rows, err := s.db.QueryContext(s.requestContext(), command)
+	if err != nil {
+		zap.L().Error("count exchange quotes failed",
+			zap.Error(err),
+			zap.String("command", command),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		count++
+	}
+
+	return &DailyStat{
+		Exchange:  exchange.Code(),
+		Date:      date,
+		Companies: int(companies),
+		Quotes:    count,
+	}, rows.Err()
+}
+
 // Delete delete exchange daily quote
 func (s TDEngine) Delete(exchange exchanges.Exchange, date time.Time) error {
-	// tdengine not allow delete
+	start, end := date.Unix()*1000, date.AddDate(0, 0, 1).Unix()*1000
+	commands := []string{
+		fmt.Sprintf("delete from quotes where ts>=%d and ts<%d and exchange='%s'", start, end, exchange.Code()),
+		fmt.Sprintf("delete from dividends where ts>=%d and ts<%d and exchange='%s'", start, end, exchange.Code()),
+		fmt.Sprintf("delete from splits where ts>=%d and ts<%d and exchange='%s'", start, end, exchange.Code()),
+		fmt.Sprintf("delete from actions where ts>=%d and ts<%d and exchange='%s'", start, end, exchange.Code()),
+		fmt.Sprintf("delete from company_metas where ts=%d and exchange='%s'", start, exchange.Code()),
+		fmt.Sprintf("delete from metas where ts=%d and exchange='%s'", start, exchange.Code()),
+		fmt.Sprintf("delete from symbols where ts=%d and exchange='%s' and type='company'", start, exchange.Code()),
+		fmt.Sprintf("delete from tasks where ts=%d and exchange='%s' and type='raw_1m'", start, exchange.Code()),
+	}
+
+	for _, command := range commands {
+		_, err := s.db.Exec(command)
+		if err != nil {
+			zap.L().Error("delete exchange daily rows failed",
+				zap.Error(err),
+				zap.String("command", command),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,322 @@
+package stores
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"go.uber.org/zap"
+)
+
+// Tee replicated store, write to every store and read from the first healthy one
+type Tee struct {
+	names    []string
+	stores   []Store
+	quorum   int
+	diverged DivergeHandler
+}
+
+// DivergeHandler handle write which reached quorum but failed on some stores, the failed stores need re-sync
+type DivergeHandler func(exchange exchanges.Exchange, date time.Time, err TeeError)
+
+// NewTee create tee store, write succeed only if at least quorum stores succeed, quorum <= 0 means all stores
+func NewTee(quorum int, _stores ...Store) *Tee {
+	names := make([]string, len(_stores))
+	for index, store := range _stores {
+		names[index] = fmt.Sprintf("%d:%T", index, store)
+	}
+
+	return newTee(names, quorum, _stores...)
+}
+
+func newTee(names []string, quorum int, _stores ...Store) *Tee {
+	if quorum <= 0 || quorum > len(_stores) {
+		quorum = len(_stores)
+	}
+
+	return &Tee{names: names, stores: _stores, quorum: quorum}
+}
+
+// SetDivergeHandler set handler called when write succeed with quorum but failed on some stores
+func (s *Tee) SetDivergeHandler(handler DivergeHandler) {
+	s.diverged = handler
+}
+
+// parseTee parse tee store argument like <store1>;<store2>[;quorum=N]
+func parseTee(arg string) (*Tee, error) {
+	var names []string
+	var _stores []Store
+	var quorum int
+	for _, part := range strings.Split(arg, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if strings.HasPrefix(part, "quorum=") {
+			value, err := strconv.Atoi(strings.TrimPrefix(part, "quorum="))
+			if err != nil {
+				zap.L().Error("tee quorum invalid", zap.Error(err), zap.String("arg", part))
+				return nil, fmt.Errorf("tee quorum invalid: %s", part)
+			}
+
+			quorum = value
+			continue
+		}
+
+		store, err := Parse(part)
+		if err != nil {
+			zap.L().Error("parse tee store failed", zap.Error(err), zap.Int("index", len(_stores)))
+			for _, parsed := range _stores {
+				parsed.Close()
+			}
+			return nil, err
+		}
+
+		// driver name only, argument and data source name may contain secret
+		names = append(names, fmt.Sprintf("%d:%s", len(_stores), driverName(part)))
+		_stores = append(_stores, store)
+	}
+
+	if len(_stores) == 0 {
+		zap.L().Error("tee store arg invalid", zap.String("arg", arg))
+		return nil, fmt.Errorf("tee store arg invalid: %s", arg)
+	}
+
+	return newTee(names, quorum, _stores...), nil
+}
+
+// driverName get driver name of store argument, like fs of fs|/data, s3 of s3://key:secret@bucket
+func driverName(arg string) string {
+	index := strings.IndexAny(arg, "|:")
+	if index < 0 {
+		return arg
+	}
+
+	return arg[:index]
+}
+
+// TeeFailure define failure of one store in tee store
+type TeeFailure struct {
+	Store string
+	Err   error
+}
+
+// TeeError define failures of tee store operation
+type TeeError struct {
+	Operation string
+	Succeed   int
+	Quorum    int
+	Failures  []TeeFailure
+}
+
+func (e TeeError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for index, failure := range e.Failures {
+		failures[index] = fmt.Sprintf("%s: %v", failure.Store, failure.Err)
+	}
+
+	return fmt.Sprintf("tee %s succeed %d, quorum %d, failures: %s",
+		e.Operation, e.Succeed, e.Quorum, strings.Join(failures, "; "))
+}
+
+// writeAll run write operation on every store concurrently, return TeeError if quorum not reached,
+// failures of write reached quorum are reported to diverge handler
+func (s Tee) writeAll(operation string, exchange exchanges.Exchange, date time.Time, fn func(Store) error) error {
+	errs := make([]error, len(s.stores))
+
+	wg := new(sync.WaitGroup)
+	wg.Add(len(s.stores))
+	for index, store := range s.stores {
+		go func(index int, store Store) {
+			defer wg.Done()
+			errs[index] = fn(store)
+		}(index, store)
+	}
+	wg.Wait()
+
+	teeErr := TeeError{Operation: operation, Quorum: s.quorum}
+	for index, err := range errs {
+		if err == nil {
+			teeErr.Succeed++
+			continue
+		}
+
+		zap.L().Warn("tee store operation failed",
+			zap.Error(err),
+			zap.String("operation", operation),
+			zap.String("store", s.names[index]))
+		teeErr.Failures = append(teeErr.Failures, TeeFailure{Store: s.names[index], Err: err})
+	}
+
+	if teeErr.Succeed < s.quorum {
+		return teeErr
+	}
+
+	if len(teeErr.Failures) > 0 && s.diverged != nil {
+		s.diverged(exchange, date, teeErr)
+	}
+
+	return nil
+}
+
+// readFirst run read operation on stores in order until one succeed
+func (s Tee) readFirst(operation string, fn func(Store) error) error {
+	teeErr := TeeError{Operation: operation, Quorum: 1}
+	for index, store := range s.stores {
+		err := fn(store)
+		if err == nil {
+			return nil
+		}
+
+		zap.L().Warn("tee store operation failed, try next",
+			zap.Error(err),
+			zap.String("operation", operation),
+			zap.String("store", s.names[index]))
+		teeErr.Failures = append(teeErr.Failures, TeeFailure{Store: s.names[index], Err: err})
+	}
+
+	return teeErr
+}
+
+// Exists check quote exists
+func (s Tee) Exists(exchange exchanges.Exchange, date time.Time) (bool, error) {
+	var exists bool
+	err := s.readFirst("exists", func(store Store) error {
+		var err error
+		exists, err = store.Exists(exchange, date)
+		return err
+	})
+
+	return exists, err
+}
+
+// Save save exchange daily quote
+func (s Tee) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return s.writeAll("save", exchange, date, func(store Store) error {
+		return store.Save(exchange, date, edq)
+	})
+}
+
+// Replace replace exchange daily quote in every store
+func (s Tee) Replace(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	return s.writeAll("replace", exchange, date, func(store Store) error {
+		return Replace(store, exchange, date, edq)
+	})
+}
+
+// Load load exchange daily quote
+func (s Tee) Load(exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	var edq *quotes.ExchangeDailyQuote
+	err := s.readFirst("load", func(store Store) error {
+		var err error
+		edq, err = store.Load(exchange, date)
+		return err
+	})
+
+	return edq, err
+}
+
+// Delete delete exchange daily quote
+func (s Tee) Delete(exchange exchanges.Exchange, date time.Time) error {
+	return s.writeAll("delete", exchange, date, func(store Store) error {
+		return store.Delete(exchange, date)
+	})
+}
+
+// SaveCompany add or replace company daily quote in every store
+func (s Tee) SaveCompany(exchange exchanges.Exchange, date time.Time, cdq *quotes.CompanyDailyQuote) error {
+	return s.writeAll("save company", exchange, date, func(store Store) error {
+		return SaveCompany(store, exchange, date, cdq)
+	})
+}
+
+// DeleteCompany remove company daily quote from every store
+func (s Tee) DeleteCompany(exchange exchanges.Exchange, date time.Time, companyCode string) error {
+	return s.writeAll("delete company", exchange, date, func(store Store) error {
+		return DeleteCompany(store, exchange, date, companyCode)
+	})
+}
+
+// Close close every store
+func (s Tee) Close() error {
+	teeErr := TeeError{Operation: "close", Quorum: len(s.stores)}
+	for index, store := range s.stores {
+		err := store.Close()
+		if err != nil {
+			zap.L().Error("close tee store failed", zap.Error(err), zap.String("store", s.names[index]))
+			teeErr.Failures = append(teeErr.Failures, TeeFailure{Store: s.names[index], Err: err})
+			continue
+		}
+
+		teeErr.Succeed++
+	}
+
+	if len(teeErr.Failures) > 0 {
+		return teeErr
+	}
+
+	return nil
+}
+
+// LoadCompany load company daily quotes between start and end date
+func (s Tee) LoadCompany(exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	var cdqs []*quotes.CompanyDailyQuote
+	err := s.readFirst("load company", func(store Store) error {
+		var err error
+		cdqs, err = LoadCompany(store, exchange, companyCode, start, end)
+		return err
+	})
+
+	return cdqs, err
+}
+
+// Exchanges list exchange codes which has stored daily quote
+func (s Tee) Exchanges() ([]string, error) {
+	var codes []string
+	err := s.readFirst("exchanges", func(store Store) error {
+		catalog, ok := store.(Catalog)
+		if !ok {
+			return fmt.Errorf("store %T is not a catalog", store)
+		}
+
+		var err error
+		codes, err = catalog.Exchanges()
+		return err
+	})
+
+	return codes, err
+}
+
+// Dates list stored dates of exchange between start and end date
+func (s Tee) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	var dates []time.Time
+	err := s.readFirst("dates", func(store Store) error {
+		var err error
+		dates, err = Dates(store, exchange, start, end)
+		return err
+	})
+
+	return dates, err
+}
+
+// Stat get stored exchange daily quote stats
+func (s Tee) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	var stat *DailyStat
+	err := s.readFirst("stat", func(store Store) error {
+		var err error
+		catalog, ok := store.(Catalog)
+		if ok {
+			stat, err = catalog.Stat(exchange, date)
+		} else {
+			stat, err = statByLoad(store, exchange, date)
+		}
+		return err
+	})
+
+	return stat, err
+}
@@ -0,0 +1,136 @@
+package stores
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+)
+
+// brokenStore store always failed
+type brokenStore struct{}
+
+var errBrokenStore = errors.New("broken store")
+
+func (brokenStore) Exists(exchanges.Exchange, time.Time) (bool, error) { return false, errBrokenStore }
+func (brokenStore) Save(exchanges.Exchange, time.Time, *quotes.ExchangeDailyQuote) error {
+	return errBrokenStore
+}
+func (brokenStore) Load(exchanges.Exchange, time.Time) (*quotes.ExchangeDailyQuote, error) {
+	return nil, errBrokenStore
+}
+func (brokenStore) Delete(exchanges.Exchange, time.Time) error { return errBrokenStore }
+func (brokenStore) Close() error                               { return nil }
+
+func TestTee(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	edq := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+
+	store, err := Parse("tee|fs|" + t.TempDir() + ";leveldb|" + t.TempDir())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	defer store.Close()
+
+	err = store.Save(exchange, date, edq)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	for _, backend := range store.(*Tee).stores {
+		saved, err := backend.Load(exchange, date)
+		if err != nil {
+			t.Fatalf("%T Load() error = %v", backend, err)
+		}
+
+		err = edq.Equal(*saved)
+		if err != nil {
+			t.Errorf("%T Load() not equal: %v", backend, err)
+		}
+	}
+
+	// read from the first healthy store
+	fs := NewFileSystem(t.TempDir())
+	tee := NewTee(1, brokenStore{}, fs)
+
+	err = tee.Save(exchange, date, edq)
+	if err != nil {
+		t.Fatalf("Save() with quorum 1 error = %v", err)
+	}
+
+	exists, err := tee.Exists(exchange, date)
+	if err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+
+	_, err = tee.Load(exchange, date)
+	if err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+
+	// quorum not reached
+	tee = NewTee(0, brokenStore{}, fs)
+	err = tee.Save(exchange, date, edq)
+
+	var teeErr TeeError
+	if !errors.As(err, &teeErr) {
+		t.Fatalf("Save() error = %v, want TeeError", err)
+	}
+
+	if teeErr.Succeed != 1 || teeErr.Quorum != 2 || len(teeErr.Failures) != 1 || teeErr.Failures[0].Err != errBrokenStore {
+		t.Errorf("Save() error = %+v, want 1 succeed and 1 broken store failure", teeErr)
+	}
+
+	// quorum reached, failures reported to diverge handler
+	tee = NewTee(1, brokenStore{}, fs)
+	var diverged []TeeError
+	tee.SetDivergeHandler(func(_exchange exchanges.Exchange, _date time.Time, err TeeError) {
+		if _exchange.Code() != exchange.Code() || !_date.Equal(date) {
+			t.Errorf("diverge handler got %s %s, want %s %s", _exchange.Code(), _date, exchange.Code(), date)
+		}
+		diverged = append(diverged, err)
+	})
+
+	err = tee.Save(exchange, date, edq)
+	if err != nil {
+		t.Fatalf("Save() with quorum 1 error = %v", err)
+	}
+
+	if len(diverged) != 1 || len(diverged[0].Failures) != 1 || diverged[0].Failures[0].Err != errBrokenStore {
+		t.Errorf("diverge handler got %+v, want 1 broken store failure", diverged)
+	}
+}
+
+func TestTeeErrorHidesSecret(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	edq := testExchangeDailyQuote(exchange, date, "AAPL")
+
+	// root under a regular file, every write failed
+	file := filepath.Join(t.TempDir(), "file")
+	err := os.WriteFile(file, nil, 0644)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	store, err := Parse("tee|fs://user:topsecret@" + file + ";fs://" + t.TempDir())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	defer store.Close()
+
+	err = store.Save(exchange, date, edq)
+	if err == nil {
+		t.Fatalf("Save() error = nil, want TeeError")
+	}
+
+	if strings.Contains(err.Error(), "topsecret") || strings.Contains(err.Error(), "user:") {
+		t.Errorf("Save() error = %v, contains data source name userinfo", err)
+	}
+}
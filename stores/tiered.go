@@ -0,0 +1,429 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+	"github.com/nzai/qr/quotes"
+	"github.com/nzai/qr/utils"
+	"go.uber.org/zap"
+)
+
+// Tiered hot/cold store, days younger than age live in hot store, older days live in cold store
+// reads fall back to the other tier, so days not migrated yet are still readable
+type Tiered struct {
+	hot  Store
+	cold Store
+	age  int
+	now  func() time.Time
+}
+
+// NewTiered create tiered store, age is the number of days kept in hot store
+func NewTiered(hot, cold Store, age int) *Tiered {
+	return &Tiered{hot: hot, cold: cold, age: age, now: time.Now}
+}
+
+// parseTiered parse tiered store argument like <age days>|<hot store>;<cold store>
+func parseTiered(arg string) (*Tiered, error) {
+	parts := strings.SplitN(arg, "|", 2)
+	if len(parts) < 2 {
+		zap.L().Error("tiered store arg invalid", zap.String("arg", arg))
+		return nil, fmt.Errorf("tiered store arg invalid: %s", arg)
+	}
+
+	age, err := strconv.Atoi(parts[0])
+	if err != nil || age < 0 {
+		zap.L().Error("tiered store age invalid", zap.Error(err), zap.String("age", parts[0]))
+		return nil, fmt.Errorf("tiered store age invalid: %s", parts[0])
+	}
+
+	tiers := strings.Split(parts[1], ";")
+	if len(tiers) != 2 {
+		zap.L().Error("tiered store arg invalid", zap.String("arg", arg))
+		return nil, fmt.Errorf("tiered store requires hot and cold store: %s", arg)
+	}
+
+	hot, err := Parse(strings.TrimSpace(tiers[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	cold, err := Parse(strings.TrimSpace(tiers[1]))
+	if err != nil {
+		hot.Close()
+		return nil, err
+	}
+
+	return NewTiered(hot, cold, age), nil
+}
+
+// Cutoff return the first date kept in hot store, days before it belong to cold store
+func (s Tiered) Cutoff(exchange exchanges.Exchange) time.Time {
+	return utils.TodayZero(s.now().In(exchange.Location())).AddDate(0, 0, -s.age)
+}
+
+// tiers return the tier owns the date first, then the other tier
+func (s Tiered) tiers(exchange exchanges.Exchange, date time.Time) (Store, Store) {
+	if date.Before(s.Cutoff(exchange)) {
+		return s.cold, s.hot
+	}
+
+	return s.hot, s.cold
+}
+
+// Exists check quote exists in either tier
+func (s Tiered) Exists(exchange exchanges.Exchange, date time.Time) (bool, error) {
+	primary, secondary := s.tiers(exchange, date)
+	exists, err := primary.Exists(exchange, date)
+	if err != nil || exists {
+		return exists, err
+	}
+
+	return secondary.Exists(exchange, date)
+}
+
+// Save save exchange daily quote into the tier owns the date
+func (s Tiered) Save(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	primary, _ := s.tiers(exchange, date)
+	return primary.Save(exchange, date, edq)
+}
+
+// Replace replace exchange daily quote in the tier owns the date
+func (s Tiered) Replace(exchange exchanges.Exchange, date time.Time, edq *quotes.ExchangeDailyQuote) error {
+	primary, _ := s.tiers(exchange, date)
+	return Replace(primary, exchange, date, edq)
+}
+
+// Load load exchange daily quote from the tier owns the date, fall back to the other tier
+func (s Tiered) Load(exchange exchanges.Exchange, date time.Time) (*quotes.ExchangeDailyQuote, error) {
+	store, err := s.locate(exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Load(exchange, date)
+}
+
+// locate return the tier holds the date, prefer the tier owns the date
+func (s Tiered) locate(exchange exchanges.Exchange, date time.Time) (Store, error) {
+	primary, secondary := s.tiers(exchange, date)
+	exists, err := primary.Exists(exchange, date)
+	if err != nil {
+		zap.L().Error("check exchange daily quote exists failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	if exists {
+		return primary, nil
+	}
+
+	exists, err = secondary.Exists(exchange, date)
+	if err != nil {
+		zap.L().Error("check exchange daily quote exists failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return nil, err
+	}
+
+	if exists {
+		return secondary, nil
+	}
+
+	return primary, nil
+}
+
+// Delete delete exchange daily quote from both tiers
+func (s Tiered) Delete(exchange exchanges.Exchange, date time.Time) error {
+	for _, store := range []Store{s.hot, s.cold} {
+		exists, err := store.Exists(exchange, date)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			continue
+		}
+
+		err = store.Delete(exchange, date)
+		if err != nil {
+			zap.L().Error("delete exchange daily quote failed",
+				zap.Error(err),
+				zap.String("exchange", exchange.Code()),
+				zap.Time("date", date))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close close both tiers
+func (s Tiered) Close() error {
+	err := s.hot.Close()
+	if err != nil {
+		zap.L().Error("close hot store failed", zap.Error(err))
+	}
+
+	coldErr := s.cold.Close()
+	if coldErr != nil {
+		zap.L().Error("close cold store failed", zap.Error(coldErr))
+		return coldErr
+	}
+
+	return err
+}
+
+// LoadCompany load company daily quotes between start and end date
+// consecutive days held by the same tier are loaded from that tier in one range
+func (s Tiered) LoadCompany(exchange exchanges.Exchange, companyCode string, start, end time.Time) ([]*quotes.CompanyDailyQuote, error) {
+	hot, err := Dates(s.hot, exchange, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	cold, err := Dates(s.cold, exchange, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	inHot := make(map[int64]bool, len(hot))
+	for _, date := range hot {
+		inHot[date.Unix()] = true
+	}
+
+	inCold := make(map[int64]bool, len(cold))
+	for _, date := range cold {
+		inCold[date.Unix()] = true
+	}
+
+	cutoff := s.Cutoff(exchange)
+
+	var cdqs []*quotes.CompanyDailyQuote
+	var run []time.Time
+	var runCold bool
+	flush := func() error {
+		if len(run) == 0 {
+			return nil
+		}
+
+		store := s.hot
+		if runCold {
+			store = s.cold
+		}
+
+		series, err := LoadCompany(store, exchange, companyCode, run[0], run[len(run)-1])
+		if err != nil {
+			return err
+		}
+
+		cdqs = append(cdqs, series...)
+		run = run[:0]
+		return nil
+	}
+
+	for _, date := range mergeDates(hot, cold) {
+		// prefer the tier owns the date, fall back to the tier holds it
+		isCold := !inHot[date.Unix()] || date.Before(cutoff) && inCold[date.Unix()]
+		if len(run) > 0 && isCold != runCold {
+			err = flush()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		run = append(run, date)
+		runCold = isCold
+	}
+
+	err = flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return cdqs, nil
+}
+
+// mergeDates merge two ordered date lists into one ordered list without duplicates
+func mergeDates(a, b []time.Time) []time.Time {
+	merged := make([]time.Time, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j >= len(b) || i < len(a) && a[i].Before(b[j]):
+			merged = append(merged, a[i])
+			i++
+		case i >= len(a) || b[j].Before(a[i]):
+			merged = append(merged, b[j])
+			j++
+		default:
+			merged = append(merged, a[i])
+			i++
+			j++
+		}
+	}
+
+	return merged
+}
+
+// Exchanges list exchange codes which has stored daily quote in either tier
+func (s Tiered) Exchanges() ([]string, error) {
+	var codes []string
+	for _, exchange := range exchanges.All() {
+		dates, err := s.Dates(exchange, time.Unix(0, 0), s.now())
+		if err != nil {
+			return nil, err
+		}
+
+		if len(dates) > 0 {
+			codes = append(codes, exchange.Code())
+		}
+	}
+
+	return codes, nil
+}
+
+// Dates list stored dates of exchange in either tier between start and end date
+func (s Tiered) Dates(exchange exchanges.Exchange, start, end time.Time) ([]time.Time, error) {
+	hot, err := Dates(s.hot, exchange, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	cold, err := Dates(s.cold, exchange, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeDates(hot, cold), nil
+}
+
+// Stat get stored exchange daily quote stats from the tier holds the date
+func (s Tiered) Stat(exchange exchanges.Exchange, date time.Time) (*DailyStat, error) {
+	store, err := s.locate(exchange, date)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, ok := store.(Catalog)
+	if ok {
+		return catalog.Stat(exchange, date)
+	}
+
+	return statByLoad(store, exchange, date)
+}
+
+// TierMigration define result of migrating one exchange from hot to cold store
+type TierMigration struct {
+	Exchange string
+	Cutoff   time.Time
+	Moved    []time.Time
+}
+
+// Migrate move days older than cutoff from hot to cold store
+// each day is saved into cold store and verified against hot store before deleted from hot store
+func (s Tiered) Migrate(ctx context.Context, exchange exchanges.Exchange) (*TierMigration, error) {
+	cutoff := s.Cutoff(exchange)
+	migration := &TierMigration{Exchange: exchange.Code(), Cutoff: cutoff}
+
+	dates, err := Dates(s.hot, exchange, time.Unix(0, 0), cutoff.AddDate(0, 0, -1))
+	if err != nil {
+		zap.L().Error("list hot store dates failed", zap.Error(err), zap.String("exchange", exchange.Code()))
+		return migration, err
+	}
+
+	for _, date := range dates {
+		err = ctx.Err()
+		if err != nil {
+			return migration, err
+		}
+
+		err = s.migrate(exchange, date)
+		if err != nil {
+			return migration, err
+		}
+
+		migration.Moved = append(migration.Moved, date)
+	}
+
+	return migration, nil
+}
+
+// migrate copy one day from hot to cold store, verify then delete it from hot store
+// day already in cold store was saved after it passed cutoff, so it is newer than hot copy and kept
+func (s Tiered) migrate(exchange exchanges.Exchange, date time.Time) error {
+	exists, err := s.cold.Exists(exchange, date)
+	if err != nil {
+		zap.L().Error("check cold exchange daily quote exists failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	if !exists {
+		err = s.copyToCold(exchange, date)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.hot.Delete(exchange, date)
+	if err != nil {
+		zap.L().Error("delete hot exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	return nil
+}
+
+// copyToCold copy one day from hot to cold store and verify it
+func (s Tiered) copyToCold(exchange exchanges.Exchange, date time.Time) error {
+	edq, err := s.hot.Load(exchange, date)
+	if err != nil {
+		zap.L().Error("load hot exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	err = s.cold.Save(exchange, date, edq)
+	if err != nil {
+		zap.L().Error("save cold exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	saved, err := s.cold.Load(exchange, date)
+	if err != nil {
+		zap.L().Error("load cold exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return err
+	}
+
+	err = edq.Equal(*saved)
+	if err != nil {
+		zap.L().Error("verify cold exchange daily quote failed",
+			zap.Error(err),
+			zap.String("exchange", exchange.Code()),
+			zap.Time("date", date))
+		return fmt.Errorf("verify %s %s in cold store failed: %w", exchange.Code(), date.Format("2006-01-02"), err)
+	}
+
+	return nil
+}
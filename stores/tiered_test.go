@@ -0,0 +1,134 @@
+package stores
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nzai/qr/exchanges"
+)
+
+func TestTiered(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	old := time.Date(2023, 3, 6, 0, 0, 0, 0, exchange.Location())
+	recent := time.Date(2023, 6, 5, 0, 0, 0, 0, exchange.Location())
+
+	store, err := Parse("tiered|30|leveldb|" + t.TempDir() + ";fs|" + t.TempDir())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	defer store.Close()
+
+	tiered := store.(*Tiered)
+	tiered.now = func() time.Time { return recent.AddDate(0, 0, 1) }
+
+	// old day lands in hot store before the tier existed
+	err = tiered.hot.Save(exchange, old, testExchangeDailyQuote(exchange, old, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	err = tiered.Save(exchange, recent, testExchangeDailyQuote(exchange, recent, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// not migrated day is still readable
+	edq, err := tiered.Load(exchange, old)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	err = testExchangeDailyQuote(exchange, old, "AAPL").Equal(*edq)
+	if err != nil {
+		t.Errorf("Load() not equal: %v", err)
+	}
+
+	migration, err := tiered.Migrate(context.Background(), exchange)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if len(migration.Moved) != 1 || !migration.Moved[0].Equal(old) {
+		t.Errorf("Migrate() moved = %v, want [%v]", migration.Moved, old)
+	}
+
+	for _, c := range []struct {
+		store Store
+		date  time.Time
+		want  bool
+	}{
+		{tiered.hot, old, false},
+		{tiered.cold, old, true},
+		{tiered.hot, recent, true},
+		{tiered.cold, recent, false},
+	} {
+		exists, err := c.store.Exists(exchange, c.date)
+		if err != nil {
+			t.Fatalf("Exists() error = %v", err)
+		}
+
+		if exists != c.want {
+			t.Errorf("%T Exists(%v) = %v, want %v", c.store, c.date, exists, c.want)
+		}
+	}
+
+	dates, err := tiered.Dates(exchange, old, recent)
+	if err != nil {
+		t.Fatalf("Dates() error = %v", err)
+	}
+
+	if len(dates) != 2 {
+		t.Errorf("Dates() = %v, want 2 dates", dates)
+	}
+
+	cdqs, err := tiered.LoadCompany(exchange, "AAPL", old, recent)
+	if err != nil {
+		t.Fatalf("LoadCompany() error = %v", err)
+	}
+
+	if len(cdqs) != 2 {
+		t.Errorf("LoadCompany() = %d quotes, want 2", len(cdqs))
+	}
+}
+
+func TestTiered_MigrateKeepsNewerCold(t *testing.T) {
+	exchange, _ := exchanges.Get("Nasdaq")
+	date := time.Date(2023, 6, 5, 0, 0, 0, 0, exchange.Location())
+
+	tiered := NewTiered(NewFileSystem(t.TempDir()), NewFileSystem(t.TempDir()), 30)
+	tiered.now = func() time.Time { return date.AddDate(0, 0, 1) }
+
+	err := tiered.Save(exchange, date, testExchangeDailyQuote(exchange, date, "AAPL"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// the day passes cutoff, then it is re-crawled into cold store
+	tiered.now = func() time.Time { return date.AddDate(0, 0, 40) }
+	recrawled := testExchangeDailyQuote(exchange, date, "AAPL", "MSFT")
+	err = tiered.Save(exchange, date, recrawled)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	_, err = tiered.Migrate(context.Background(), exchange)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	exists, err := tiered.hot.Exists(exchange, date)
+	if err != nil || exists {
+		t.Errorf("hot Exists() = %v, %v, want false", exists, err)
+	}
+
+	edq, err := tiered.cold.Load(exchange, date)
+	if err != nil {
+		t.Fatalf("cold Load() error = %v", err)
+	}
+
+	err = recrawled.Equal(*edq)
+	if err != nil {
+		t.Errorf("cold Load() is not the re-crawled day: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,9 +15,19 @@ func TryDownloadString(url string, retry int, retryInterval time.Duration) (stri
 	return TryDownloadStringWithHeader(url, nil, retry, retryInterval)
 }
 
+// TryDownloadStringContext try download string by url until context done
+func TryDownloadStringContext(ctx context.Context, url string, retry int, retryInterval time.Duration) (string, error) {
+	return TryDownloadStringWithHeaderContext(ctx, url, nil, retry, retryInterval)
+}
+
 // TryDownloadStringWithHeader try download string by url
 func TryDownloadStringWithHeader(url string, headers map[string]string, retry int, retryInterval time.Duration) (string, error) {
-	code, buffer, err := TryDownloadBytesWithHeader(url, headers, retry, retryInterval)
+	return TryDownloadStringWithHeaderContext(context.Background(), url, headers, retry, retryInterval)
+}
+
+// TryDownloadStringWithHeaderContext try download string by url until context done
+func TryDownloadStringWithHeaderContext(ctx context.Context, url string, headers map[string]string, retry int, retryInterval time.Duration) (string, error) {
+	code, buffer, err := TryDownloadBytesWithHeaderContext(ctx, url, headers, retry, retryInterval)
 	if err != nil {
 		return "", err
 	}
@@ -34,18 +45,32 @@ func TryDownloadBytes(url string, retry int, retryInterval time.Duration) (int,
 	return TryDownloadBytesWithHeader(url, nil, retry, retryInterval)
 }
 
+// TryDownloadBytesContext try download bytes by url until context done
+func TryDownloadBytesContext(ctx context.Context, url string, retry int, retryInterval time.Duration) (int, []byte, error) {
+	return TryDownloadBytesWithHeaderContext(ctx, url, nil, retry, retryInterval)
+}
+
 // TryDownloadBytesWithHeader try download bytes by url
 func TryDownloadBytesWithHeader(url string, headers map[string]string, retry int, retryInterval time.Duration) (int, []byte, error) {
+	return TryDownloadBytesWithHeaderContext(context.Background(), url, headers, retry, retryInterval)
+}
+
+// TryDownloadBytesWithHeaderContext try download bytes by url until context done
+func TryDownloadBytesWithHeaderContext(ctx context.Context, url string, headers map[string]string, retry int, retryInterval time.Duration) (int, []byte, error) {
 	var code int
 	var buffer []byte
 	var err error
 	start := time.Now()
 	for index := 0; index < retry; index++ {
-		code, buffer, err = tryDownloadBytesOnce(url, headers)
+		code, buffer, err = tryDownloadBytesOnce(ctx, url, headers)
 		if err == nil && code == http.StatusOK {
 			return code, buffer, nil
 		}
 
+		if ctx.Err() != nil {
+			return code, buffer, ctx.Err()
+		}
+
 		if code == http.StatusTooManyRequests {
 			if time.Since(start).Hours() > 12 {
 				break
@@ -56,15 +81,18 @@ func TryDownloadBytesWithHeader(url string, headers map[string]string, retry int
 		}
 
 		if index < retry-1 {
-			time.Sleep(retryInterval)
+			err1 := Sleep(ctx, retryInterval)
+			if err1 != nil {
+				return code, buffer, err1
+			}
 		}
 	}
 
 	return code, buffer, err
 }
 
-func tryDownloadBytesOnce(url string, headers map[string]string) (int, []byte, error) {
-	request, err := http.NewRequest("GET", url, nil)
+func tryDownloadBytesOnce(ctx context.Context, url string, headers map[string]string) (int, []byte, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		zap.L().Warn("create http request failed", zap.Error(err), zap.String("url", url))
 		return 0, nil, err
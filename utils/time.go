@@ -1,6 +1,9 @@
 package utils
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // TodayZero truncate time to today zero clock
 func TodayZero(now time.Time) time.Time {
@@ -18,3 +21,16 @@ func TomorrowZero(now time.Time) time.Time {
 func YesterdayZero(now time.Time) time.Time {
 	return TodayZero(now).AddDate(0, 0, -1)
 }
+
+// Sleep pause current goroutine for at least duration, return early with context error if context done
+func Sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}